@@ -4,6 +4,7 @@ type (
 	ClassLiteral struct {
 		Class      Idx
 		RightBrace Idx
+		Decorators Decorators
 		Name       *Identifier `optional:"true"`
 		SuperClass *Expression `optional:"true"`
 		Body       ClassElements
@@ -22,6 +23,7 @@ type (
 
 	FieldDefinition struct {
 		Idx         Idx
+		Decorators  Decorators
 		Key         *Expression
 		Initializer *Expression `optional:"true"`
 		Computed    bool
@@ -29,24 +31,46 @@ type (
 	}
 
 	MethodDefinition struct {
-		Idx      Idx
-		Key      *Expression
-		Kind     PropertyKind // "method", "get" or "set"
-		Body     *FunctionLiteral
-		Computed bool
-		Static   bool
+		Idx        Idx
+		Decorators Decorators
+		Key        *Expression
+		Kind       PropertyKind // "method", "get" or "set"
+		Body       *FunctionLiteral
+		Computed   bool
+		Static     bool
+	}
+
+	// ClassAccessorProperty is a field declared with the accessor keyword,
+	// which defines a getter and setter pair backed by a private field.
+	ClassAccessorProperty struct {
+		Idx         Idx
+		Decorators  Decorators
+		Key         *Expression
+		Initializer *Expression `optional:"true"`
+		Computed    bool
+		Static      bool
 	}
 
 	ClassStaticBlock struct {
 		Static Idx
 		Block  *BlockStatement
 	}
+
+	Decorators []Decorator
+
+	// Decorator is a decorator such as @foo, @foo.bar(baz) or @(expr) in
+	// front of a class or class element.
+	Decorator struct {
+		At         Idx
+		Expression *Expression
+	}
 )
 
 func (*ClassLiteral) _expr()  {}
 func (*PropertyShort) _expr() {}
 func (*PropertyKeyed) _expr() {}
 
-func (*FieldDefinition) _classElement()  {}
-func (*MethodDefinition) _classElement() {}
-func (*ClassStaticBlock) _classElement() {}
+func (*FieldDefinition) _classElement()       {}
+func (*MethodDefinition) _classElement()      {}
+func (*ClassAccessorProperty) _classElement() {}
+func (*ClassStaticBlock) _classElement()      {}
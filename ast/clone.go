@@ -8,7 +8,7 @@ func (n *ArrayPattern) Clone() *ArrayPattern {
 	return &ArrayPattern{LeftBracket: n.LeftBracket, RightBracket: n.RightBracket, Elements: *n.Elements.Clone(), Rest: n.Rest.Clone()}
 }
 func (n *ArrowFunctionLiteral) Clone() *ArrowFunctionLiteral {
-	return &ArrowFunctionLiteral{Start: n.Start, ParameterList: *n.ParameterList.Clone(), Body: n.Body.Clone(), Async: n.Async, ScopeContext: n.ScopeContext}
+	return &ArrowFunctionLiteral{Start: n.Start, ParameterList: *n.ParameterList.Clone(), Body: n.Body.Clone(), Async: n.Async, Strict: n.Strict, ScopeContext: n.ScopeContext}
 }
 func (n *AssignExpression) Clone() *AssignExpression {
 	return &AssignExpression{Operator: n.Operator, Left: n.Left.Clone(), Right: n.Right.Clone()}
@@ -19,6 +19,9 @@ func (n *AwaitExpression) Clone() *AwaitExpression {
 func (n *BadStatement) Clone() *BadStatement {
 	return &BadStatement{From: n.From, To: n.To}
 }
+func (n *BigIntLiteral) Clone() *BigIntLiteral {
+	return &BigIntLiteral{Idx: n.Idx, Value: n.Value, Raw: n.Raw}
+}
 func (n *BinaryExpression) Clone() *BinaryExpression {
 	return &BinaryExpression{Operator: n.Operator, Left: n.Left.Clone(), Right: n.Right.Clone()}
 }
@@ -39,7 +42,7 @@ func (n *BindingTarget) Clone() *BindingTarget {
 	return &BindingTarget{Target: clonedTarget}
 }
 func (n *BlockStatement) Clone() *BlockStatement {
-	return &BlockStatement{LeftBrace: n.LeftBrace, List: *n.List.Clone(), RightBrace: n.RightBrace, ScopeContext: n.ScopeContext}
+	return &BlockStatement{LeftBrace: n.LeftBrace, Directives: *n.Directives.Clone(), List: *n.List.Clone(), RightBrace: n.RightBrace, ScopeContext: n.ScopeContext}
 }
 func (n *BooleanLiteral) Clone() *BooleanLiteral {
 	return &BooleanLiteral{Idx: n.Idx, Value: n.Value}
@@ -75,12 +78,21 @@ func (n *CatchStatement) Clone() *CatchStatement {
 	}
 	return &CatchStatement{Catch: n.Catch, Parameter: parameter, Body: n.Body.Clone()}
 }
+func (n *ClassAccessorProperty) Clone() *ClassAccessorProperty {
+	var initializer *Expression
+	if n.Initializer != nil {
+		initializer = n.Initializer.Clone()
+	}
+	return &ClassAccessorProperty{Idx: n.Idx, Decorators: *n.Decorators.Clone(), Key: n.Key.Clone(), Initializer: initializer, Computed: n.Computed, Static: n.Static}
+}
 func (n *ClassDeclaration) Clone() *ClassDeclaration {
 	return &ClassDeclaration{Class: n.Class.Clone()}
 }
 func (n *ClassElement) Clone() *ClassElement {
 	var clonedElement Element
 	switch element := n.Element.(type) {
+	case *ClassAccessorProperty:
+		clonedElement = element.Clone()
 	case *ClassStaticBlock:
 		clonedElement = element.Clone()
 	case *FieldDefinition:
@@ -106,7 +118,7 @@ func (n *ClassLiteral) Clone() *ClassLiteral {
 	if n.SuperClass != nil {
 		superclass = n.SuperClass.Clone()
 	}
-	return &ClassLiteral{Class: n.Class, RightBrace: n.RightBrace, Name: name, SuperClass: superclass, Body: *n.Body.Clone()}
+	return &ClassLiteral{Class: n.Class, RightBrace: n.RightBrace, Decorators: *n.Decorators.Clone(), Name: name, SuperClass: superclass, Body: *n.Body.Clone()}
 }
 func (n *ClassStaticBlock) Clone() *ClassStaticBlock {
 	return &ClassStaticBlock{Static: n.Static, Block: n.Block.Clone()}
@@ -137,12 +149,71 @@ func (n *ContinueStatement) Clone() *ContinueStatement {
 func (n *DebuggerStatement) Clone() *DebuggerStatement {
 	return &DebuggerStatement{Debugger: n.Debugger}
 }
+func (n *Decorator) Clone() *Decorator {
+	return &Decorator{At: n.At, Expression: n.Expression.Clone()}
+}
+func (n *Decorators) Clone() *Decorators {
+	ns := make(Decorators, len(*n))
+	for i := range *n {
+		ns[i] = *(*n)[i].Clone()
+	}
+	return &ns
+}
+func (n *Directive) Clone() *Directive {
+	return &Directive{Idx: n.Idx, Value: n.Value, Cooked: n.Cooked, Raw: n.Raw}
+}
+func (n *Directives) Clone() *Directives {
+	ns := make(Directives, len(*n))
+	for i := range *n {
+		ns[i] = *(*n)[i].Clone()
+	}
+	return &ns
+}
 func (n *DoWhileStatement) Clone() *DoWhileStatement {
 	return &DoWhileStatement{Do: n.Do, Test: n.Test.Clone(), Body: n.Body.Clone()}
 }
 func (n *EmptyStatement) Clone() *EmptyStatement {
 	return &EmptyStatement{Semicolon: n.Semicolon}
 }
+func (n *ExportAllDeclaration) Clone() *ExportAllDeclaration {
+	var exported *ModuleExportName
+	if n.Exported != nil {
+		exported = n.Exported.Clone()
+	}
+	return &ExportAllDeclaration{Export: n.Export, Exported: exported, Source: n.Source.Clone()}
+}
+func (n *ExportDefaultDeclaration) Clone() *ExportDefaultDeclaration {
+	var declaration *Statement
+	if n.Declaration != nil {
+		declaration = n.Declaration.Clone()
+	}
+	var expression *Expression
+	if n.Expression != nil {
+		expression = n.Expression.Clone()
+	}
+	return &ExportDefaultDeclaration{Export: n.Export, Declaration: declaration, Expression: expression}
+}
+func (n *ExportNamedDeclaration) Clone() *ExportNamedDeclaration {
+	var declaration *Statement
+	if n.Declaration != nil {
+		declaration = n.Declaration.Clone()
+	}
+	var source *StringLiteral
+	if n.Source != nil {
+		source = n.Source.Clone()
+	}
+	return &ExportNamedDeclaration{Export: n.Export, Declaration: declaration, Specifiers: *n.Specifiers.Clone(), Source: source, RightBrace: n.RightBrace}
+}
+func (n *ExportSpecifier) Clone() *ExportSpecifier {
+	return &ExportSpecifier{Local: n.Local.Clone(), Exported: n.Exported.Clone()}
+}
+func (n *ExportSpecifiers) Clone() *ExportSpecifiers {
+	ns := make(ExportSpecifiers, len(*n))
+	for i := range *n {
+		ns[i] = *(*n)[i].Clone()
+	}
+	return &ns
+}
 func (n *Expression) Clone() *Expression {
 	var clonedExpr Expr
 	switch expr := n.Expr.(type) {
@@ -156,6 +227,8 @@ func (n *Expression) Clone() *Expression {
 		clonedExpr = expr.Clone()
 	case *AwaitExpression:
 		clonedExpr = expr.Clone()
+	case *BigIntLiteral:
+		clonedExpr = expr.Clone()
 	case *BinaryExpression:
 		clonedExpr = expr.Clone()
 	case *BooleanLiteral:
@@ -170,8 +243,16 @@ func (n *Expression) Clone() *Expression {
 		clonedExpr = expr.Clone()
 	case *Identifier:
 		clonedExpr = expr.Clone()
+	case *ImportExpression:
+		clonedExpr = expr.Clone()
 	case *InvalidExpression:
 		clonedExpr = expr.Clone()
+	case *JSXElement:
+		clonedExpr = expr.Clone()
+	case *JSXExpressionContainer:
+		clonedExpr = expr.Clone()
+	case *JSXFragment:
+		clonedExpr = expr.Clone()
 	case *MemberExpression:
 		clonedExpr = expr.Clone()
 	case *MetaProperty:
@@ -238,7 +319,7 @@ func (n *FieldDefinition) Clone() *FieldDefinition {
 	if n.Initializer != nil {
 		initializer = n.Initializer.Clone()
 	}
-	return &FieldDefinition{Idx: n.Idx, Key: n.Key.Clone(), Initializer: initializer, Computed: n.Computed, Static: n.Static}
+	return &FieldDefinition{Idx: n.Idx, Decorators: *n.Decorators.Clone(), Key: n.Key.Clone(), Initializer: initializer, Computed: n.Computed, Static: n.Static}
 }
 func (n *ForInStatement) Clone() *ForInStatement {
 	return &ForInStatement{For: n.For, Into: n.Into.Clone(), Source: n.Source.Clone(), Body: n.Body.Clone()}
@@ -264,7 +345,7 @@ func (n *ForLoopInitializer) Clone() *ForLoopInitializer {
 	return &ForLoopInitializer{Initializer: clonedForLoopInit}
 }
 func (n *ForOfStatement) Clone() *ForOfStatement {
-	return &ForOfStatement{For: n.For, Into: n.Into.Clone(), Source: n.Source.Clone(), Body: n.Body.Clone()}
+	return &ForOfStatement{For: n.For, Into: n.Into.Clone(), Source: n.Source.Clone(), Body: n.Body.Clone(), Await: n.Await}
 }
 func (n *ForStatement) Clone() *ForStatement {
 	var initializer *ForLoopInitializer
@@ -281,7 +362,7 @@ func (n *FunctionLiteral) Clone() *FunctionLiteral {
 	if n.Name != nil {
 		name = n.Name.Clone()
 	}
-	return &FunctionLiteral{Function: n.Function, Name: name, ParameterList: *n.ParameterList.Clone(), Body: n.Body.Clone(), Async: n.Async, ScopeContext: n.ScopeContext}
+	return &FunctionLiteral{Function: n.Function, Name: name, ParameterList: *n.ParameterList.Clone(), Body: n.Body.Clone(), Async: n.Async, Strict: n.Strict, ScopeContext: n.ScopeContext}
 }
 func (n *Identifier) Clone() *Identifier {
 	return &Identifier{Idx: n.Idx, Name: n.Name, ScopeContext: n.ScopeContext}
@@ -293,9 +374,124 @@ func (n *IfStatement) Clone() *IfStatement {
 	}
 	return &IfStatement{If: n.If, Test: n.Test.Clone(), Consequent: n.Consequent.Clone(), Alternate: alternate}
 }
+func (n *ImportDeclaration) Clone() *ImportDeclaration {
+	return &ImportDeclaration{Import: n.Import, Specifiers: *n.Specifiers.Clone(), Source: n.Source.Clone()}
+}
+func (n *ImportDefaultSpecifier) Clone() *ImportDefaultSpecifier {
+	return &ImportDefaultSpecifier{Local: n.Local.Clone()}
+}
+func (n *ImportExpression) Clone() *ImportExpression {
+	var options *Expression
+	if n.Options != nil {
+		options = n.Options.Clone()
+	}
+	return &ImportExpression{Import: n.Import, Source: n.Source.Clone(), Options: options, RightParenthesis: n.RightParenthesis}
+}
+func (n *ImportNamedSpecifier) Clone() *ImportNamedSpecifier {
+	return &ImportNamedSpecifier{Imported: n.Imported.Clone(), Local: n.Local.Clone()}
+}
+func (n *ImportNamespaceSpecifier) Clone() *ImportNamespaceSpecifier {
+	return &ImportNamespaceSpecifier{Star: n.Star, Local: n.Local.Clone()}
+}
+func (n *ImportSpecifier) Clone() *ImportSpecifier {
+	var clonedImportSpec ImportSpec
+	switch importSpec := n.Specifier.(type) {
+	case *ImportDefaultSpecifier:
+		clonedImportSpec = importSpec.Clone()
+	case *ImportNamedSpecifier:
+		clonedImportSpec = importSpec.Clone()
+	case *ImportNamespaceSpecifier:
+		clonedImportSpec = importSpec.Clone()
+	}
+	return &ImportSpecifier{Specifier: clonedImportSpec}
+}
+func (n *ImportSpecifiers) Clone() *ImportSpecifiers {
+	ns := make(ImportSpecifiers, len(*n))
+	for i := range *n {
+		ns[i] = *(*n)[i].Clone()
+	}
+	return &ns
+}
 func (n *InvalidExpression) Clone() *InvalidExpression {
 	return &InvalidExpression{From: n.From, To: n.To}
 }
+func (n *JSXAttribute) Clone() *JSXAttribute {
+	var value *Expression
+	if n.Value != nil {
+		value = n.Value.Clone()
+	}
+	return &JSXAttribute{Name: n.Name.Clone(), Value: value}
+}
+func (n *JSXAttributeItem) Clone() *JSXAttributeItem {
+	var clonedJSXAttr JSXAttr
+	switch jSXAttr := n.Attribute.(type) {
+	case *JSXAttribute:
+		clonedJSXAttr = jSXAttr.Clone()
+	case *JSXSpreadAttribute:
+		clonedJSXAttr = jSXAttr.Clone()
+	}
+	return &JSXAttributeItem{Attribute: clonedJSXAttr}
+}
+func (n *JSXAttributes) Clone() *JSXAttributes {
+	ns := make(JSXAttributes, len(*n))
+	for i := range *n {
+		ns[i] = *(*n)[i].Clone()
+	}
+	return &ns
+}
+func (n *JSXChildItem) Clone() *JSXChildItem {
+	var clonedJSXChild JSXChild
+	switch jSXChild := n.Child.(type) {
+	case *JSXElement:
+		clonedJSXChild = jSXChild.Clone()
+	case *JSXExpressionContainer:
+		clonedJSXChild = jSXChild.Clone()
+	case *JSXFragment:
+		clonedJSXChild = jSXChild.Clone()
+	case *JSXText:
+		clonedJSXChild = jSXChild.Clone()
+	}
+	return &JSXChildItem{Child: clonedJSXChild}
+}
+func (n *JSXChildren) Clone() *JSXChildren {
+	ns := make(JSXChildren, len(*n))
+	for i := range *n {
+		ns[i] = *(*n)[i].Clone()
+	}
+	return &ns
+}
+func (n *JSXClosingElement) Clone() *JSXClosingElement {
+	return &JSXClosingElement{LessThan: n.LessThan, Name: n.Name.Clone(), GreaterThan: n.GreaterThan}
+}
+func (n *JSXElement) Clone() *JSXElement {
+	var closing *JSXClosingElement
+	if n.Closing != nil {
+		closing = n.Closing.Clone()
+	}
+	return &JSXElement{Opening: n.Opening.Clone(), Children: *n.Children.Clone(), Closing: closing}
+}
+func (n *JSXExpressionContainer) Clone() *JSXExpressionContainer {
+	var expression *Expression
+	if n.Expression != nil {
+		expression = n.Expression.Clone()
+	}
+	return &JSXExpressionContainer{LeftBrace: n.LeftBrace, Expression: expression, RightBrace: n.RightBrace}
+}
+func (n *JSXFragment) Clone() *JSXFragment {
+	return &JSXFragment{OpeningLessThan: n.OpeningLessThan, OpeningGreaterThan: n.OpeningGreaterThan, Children: *n.Children.Clone(), ClosingLessThan: n.ClosingLessThan, ClosingGreaterThan: n.ClosingGreaterThan}
+}
+func (n *JSXName) Clone() *JSXName {
+	return &JSXName{Idx: n.Idx, Name: n.Name}
+}
+func (n *JSXOpeningElement) Clone() *JSXOpeningElement {
+	return &JSXOpeningElement{LessThan: n.LessThan, Name: n.Name.Clone(), Attributes: *n.Attributes.Clone(), SelfClosing: n.SelfClosing, GreaterThan: n.GreaterThan}
+}
+func (n *JSXSpreadAttribute) Clone() *JSXSpreadAttribute {
+	return &JSXSpreadAttribute{LeftBrace: n.LeftBrace, Argument: n.Argument.Clone(), RightBrace: n.RightBrace}
+}
+func (n *JSXText) Clone() *JSXText {
+	return &JSXText{Idx: n.Idx, Value: n.Value, Raw: n.Raw}
+}
 func (n *LabelledStatement) Clone() *LabelledStatement {
 	return &LabelledStatement{Label: n.Label.Clone(), Colon: n.Colon, Statement: n.Statement.Clone()}
 }
@@ -316,7 +512,17 @@ func (n *MetaProperty) Clone() *MetaProperty {
 	return &MetaProperty{Meta: n.Meta.Clone(), Idx: n.Idx}
 }
 func (n *MethodDefinition) Clone() *MethodDefinition {
-	return &MethodDefinition{Idx: n.Idx, Key: n.Key.Clone(), Kind: n.Kind, Body: n.Body.Clone(), Computed: n.Computed, Static: n.Static}
+	return &MethodDefinition{Idx: n.Idx, Decorators: *n.Decorators.Clone(), Key: n.Key.Clone(), Kind: n.Kind, Body: n.Body.Clone(), Computed: n.Computed, Static: n.Static}
+}
+func (n *ModuleExportName) Clone() *ModuleExportName {
+	var clonedExportName ExportName
+	switch exportName := n.Name.(type) {
+	case *Identifier:
+		clonedExportName = exportName.Clone()
+	case *StringLiteral:
+		clonedExportName = exportName.Clone()
+	}
+	return &ModuleExportName{Name: clonedExportName}
 }
 func (n *NewExpression) Clone() *NewExpression {
 	return &NewExpression{New: n.New, Callee: n.Callee.Clone(), LeftParenthesis: n.LeftParenthesis, ArgumentList: *n.ArgumentList.Clone(), RightParenthesis: n.RightParenthesis}
@@ -343,6 +549,8 @@ func (n *ObjectPattern) Clone() *ObjectPattern {
 		clonedExpr = expr.Clone()
 	case *AwaitExpression:
 		clonedExpr = expr.Clone()
+	case *BigIntLiteral:
+		clonedExpr = expr.Clone()
 	case *BinaryExpression:
 		clonedExpr = expr.Clone()
 	case *BooleanLiteral:
@@ -357,8 +565,16 @@ func (n *ObjectPattern) Clone() *ObjectPattern {
 		clonedExpr = expr.Clone()
 	case *Identifier:
 		clonedExpr = expr.Clone()
+	case *ImportExpression:
+		clonedExpr = expr.Clone()
 	case *InvalidExpression:
 		clonedExpr = expr.Clone()
+	case *JSXElement:
+		clonedExpr = expr.Clone()
+	case *JSXExpressionContainer:
+		clonedExpr = expr.Clone()
+	case *JSXFragment:
+		clonedExpr = expr.Clone()
 	case *MemberExpression:
 		clonedExpr = expr.Clone()
 	case *MetaProperty:
@@ -429,6 +645,8 @@ func (n *ParameterList) Clone() *ParameterList {
 		clonedExpr = expr.Clone()
 	case *AwaitExpression:
 		clonedExpr = expr.Clone()
+	case *BigIntLiteral:
+		clonedExpr = expr.Clone()
 	case *BinaryExpression:
 		clonedExpr = expr.Clone()
 	case *BooleanLiteral:
@@ -443,8 +661,16 @@ func (n *ParameterList) Clone() *ParameterList {
 		clonedExpr = expr.Clone()
 	case *Identifier:
 		clonedExpr = expr.Clone()
+	case *ImportExpression:
+		clonedExpr = expr.Clone()
 	case *InvalidExpression:
 		clonedExpr = expr.Clone()
+	case *JSXElement:
+		clonedExpr = expr.Clone()
+	case *JSXExpressionContainer:
+		clonedExpr = expr.Clone()
+	case *JSXFragment:
+		clonedExpr = expr.Clone()
 	case *MemberExpression:
 		clonedExpr = expr.Clone()
 	case *MetaProperty:
@@ -503,7 +729,7 @@ func (n *PrivateIdentifier) Clone() *PrivateIdentifier {
 	return &PrivateIdentifier{Identifier: n.Identifier.Clone()}
 }
 func (n *Program) Clone() *Program {
-	return &Program{Body: *n.Body.Clone()}
+	return &Program{Directives: *n.Directives.Clone(), Body: *n.Body.Clone(), SourceType: n.SourceType, Strict: n.Strict, WebCompat: n.WebCompat, Source: n.Source}
 }
 func (n *Properties) Clone() *Properties {
 	ns := make(Properties, len(*n))
@@ -528,7 +754,11 @@ func (n *PropertyKeyed) Clone() *PropertyKeyed {
 	return &PropertyKeyed{Key: n.Key.Clone(), Kind: n.Kind, Value: n.Value.Clone(), Computed: n.Computed}
 }
 func (n *PropertyShort) Clone() *PropertyShort {
-	return &PropertyShort{Name: n.Name.Clone(), Initializer: n.Initializer.Clone()}
+	var initializer *Expression
+	if n.Initializer != nil {
+		initializer = n.Initializer.Clone()
+	}
+	return &PropertyShort{Name: n.Name.Clone(), Initializer: initializer}
 }
 func (n *RegExpLiteral) Clone() *RegExpLiteral {
 	return &RegExpLiteral{Idx: n.Idx, Literal: n.Literal, Pattern: n.Pattern, Flags: n.Flags}
@@ -569,6 +799,12 @@ func (n *Statement) Clone() *Statement {
 		clonedStmt = stmt.Clone()
 	case *EmptyStatement:
 		clonedStmt = stmt.Clone()
+	case *ExportAllDeclaration:
+		clonedStmt = stmt.Clone()
+	case *ExportDefaultDeclaration:
+		clonedStmt = stmt.Clone()
+	case *ExportNamedDeclaration:
+		clonedStmt = stmt.Clone()
 	case *ExpressionStatement:
 		clonedStmt = stmt.Clone()
 	case *ForInStatement:
@@ -581,6 +817,8 @@ func (n *Statement) Clone() *Statement {
 		clonedStmt = stmt.Clone()
 	case *IfStatement:
 		clonedStmt = stmt.Clone()
+	case *ImportDeclaration:
+		clonedStmt = stmt.Clone()
 	case *LabelledStatement:
 		clonedStmt = stmt.Clone()
 	case *ReturnStatement:
@@ -680,5 +918,9 @@ func (n *WithStatement) Clone() *WithStatement {
 	return &WithStatement{With: n.With, Object: n.Object.Clone(), Body: n.Body.Clone()}
 }
 func (n *YieldExpression) Clone() *YieldExpression {
-	return &YieldExpression{Yield: n.Yield, Argument: n.Argument.Clone(), Delegate: n.Delegate}
+	var argument *Expression
+	if n.Argument != nil {
+		argument = n.Argument.Clone()
+	}
+	return &YieldExpression{Yield: n.Yield, Argument: argument, Delegate: n.Delegate}
 }
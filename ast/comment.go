@@ -0,0 +1,50 @@
+package ast
+
+// CommentKind is the syntactic form of a comment.
+type CommentKind int
+
+const (
+	CommentLine  CommentKind = iota // `// ...`
+	CommentBlock                    // `/* ... */`
+	// The HTML-like comments of scripts with web compatibility, which run to
+	// the end of the line like line comments.
+	CommentHTMLOpen  // `<!-- ...`
+	CommentHTMLClose // `--> ...`, only first on a line
+)
+
+func (k CommentKind) String() string {
+	if k == CommentBlock {
+		return "Block"
+	}
+	return "Line"
+}
+
+// Comment is a single comment of the source text.
+type Comment struct {
+	Idx  Idx // The index of the opening delimiter
+	Kind CommentKind
+	// Text is the comment without its delimiters.
+	Text string
+}
+
+func (c *Comment) Idx0() Idx { return c.Idx }
+func (c *Comment) Idx1() Idx {
+	return Idx(int(c.Idx) + len(c.Text) + c.Kind.delimiters())
+}
+
+// delimiters returns the length of the delimiters of a comment of kind k.
+func (k CommentKind) delimiters() int {
+	switch k {
+	case CommentBlock, CommentHTMLOpen:
+		return 4 // "/*" and "*/", or "<!--"
+	case CommentHTMLClose:
+		return 3 // "-->"
+	}
+	return 2 // "//"
+}
+
+// NodeComments holds the comments attached to a single node.
+type NodeComments struct {
+	Leading  []*Comment
+	Trailing []*Comment
+}
@@ -29,3 +29,34 @@ type (
 func (*FunctionDeclaration) _stmt() {}
 func (*ClassDeclaration) _stmt()    {}
 func (*VariableDeclaration) _stmt() {}
+
+// BoundNames calls fn for every identifier bound by the binding target expr.
+func BoundNames(expr Expr, fn func(*Identifier)) {
+	switch expr := expr.(type) {
+	case *Identifier:
+		fn(expr)
+	case *BindingTarget:
+		BoundNames(expr.Target, fn)
+	case *AssignExpression:
+		BoundNames(expr.Left.Expr, fn)
+	case *ArrayPattern:
+		for _, elem := range expr.Elements {
+			BoundNames(elem.Expr, fn)
+		}
+		if expr.Rest != nil {
+			BoundNames(expr.Rest.Expr, fn)
+		}
+	case *ObjectPattern:
+		for _, prop := range expr.Properties {
+			switch prop := prop.Prop.(type) {
+			case *PropertyShort:
+				if prop.Name != nil {
+					fn(prop.Name)
+				}
+			case *PropertyKeyed:
+				BoundNames(prop.Value.Expr, fn)
+			}
+		}
+		BoundNames(expr.Rest, fn)
+	}
+}
@@ -33,7 +33,7 @@ type (
 
 	YieldExpression struct {
 		Yield    Idx
-		Argument *Expression
+		Argument *Expression `optional:"true"`
 		Delegate bool
 	}
 
@@ -128,6 +128,8 @@ type (
 		ParameterList ParameterList
 		Body          *ConciseBody
 		Async         bool
+		// Strict is set when the function is strict mode code, see FunctionLiteral.
+		Strict bool
 
 		ScopeContext ScopeContext
 	}
@@ -207,6 +209,14 @@ type (
 		Meta, Property *Identifier
 		Idx            Idx
 	}
+
+	// ImportExpression is a dynamic `import(source)` or `import(source, options)`.
+	ImportExpression struct {
+		Import           Idx
+		Source           *Expression
+		Options          *Expression `optional:"true"`
+		RightParenthesis Idx
+	}
 )
 
 func (*BlockStatement) _conciseBody() {}
@@ -241,6 +251,7 @@ func (*SuperExpression) _expr()       {}
 func (*UnaryExpression) _expr()       {}
 func (*UpdateExpression) _expr()      {}
 func (*MetaProperty) _expr()          {}
+func (*ImportExpression) _expr()      {}
 func (*ObjectPattern) _expr()         {}
 func (*ArrayPattern) _expr()          {}
 func (*VariableDeclarator) _expr()    {}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/t14raptor/go-fast/resolver"
 	"math"
+	"math/big"
 	"slices"
 	"strings"
 
@@ -46,6 +47,12 @@ func IsArrayLiteral(n *ast.Expression) bool {
 	return ok
 }
 
+// IsBigInt returns true if the expression is known to be a BigInt value.
+func IsBigInt(n *ast.Expression) bool {
+	t := GetType(n)
+	return t.Known() && t.Val() == BigIntType{}
+}
+
 // IsNaN returns true if expr is a global reference to NaN.
 func IsNaN(expr *ast.Expression) bool {
 	return IsGlobalRefTo(expr, "NaN")
@@ -88,10 +95,23 @@ func CastToBool(expr *ast.Expression) (value BoolValue, pure bool) {
 
 	switch e := expr.Expr.(type) {
 	case *ast.AssignExpression:
-		if e.Operator == token.Assign {
+		switch e.Operator {
+		case token.Assign:
 			v, _ := CastToBool(e.Right)
 			return v, false
+		// Logical assignments only write when the left side does not
+		// short-circuit, so the right side decides the result only when it
+		// agrees with the short-circuit value.
+		case token.LogicalOr:
+			if v, _ := CastToBool(e.Right); v.Value == Known(true) {
+				return v, false
+			}
+		case token.LogicalAnd:
+			if v, _ := CastToBool(e.Right); v.Value == Known(false) {
+				return v, false
+			}
 		}
+		return BoolValue{Unknown[bool]()}, false
 	case *ast.UnaryExpression:
 		switch e.Operator {
 		case token.Minus:
@@ -193,6 +213,8 @@ func CastToBool(expr *ast.Expression) (value BoolValue, pure bool) {
 			return BoolValue{}, true
 		}
 		return BoolValue{Known(true)}, true
+	case *ast.BigIntLiteral:
+		return BoolValue{Known(e.Value.Sign() != 0)}, true
 	case *ast.BooleanLiteral:
 		return BoolValue{Known(e.Value)}, true
 	case *ast.StringLiteral:
@@ -283,6 +305,21 @@ func CastToNumber(expr *ast.Expression) (value Value[float64], pure bool) {
 	return Unknown[float64](), false
 }
 
+// AsPureBigInt gets the BigInt value if it does not have any side effects.
+func AsPureBigInt(expr *ast.Expression) Value[*big.Int] {
+	switch e := expr.Expr.(type) {
+	case *ast.BigIntLiteral:
+		return Known(e.Value)
+	case *ast.UnaryExpression:
+		if e.Operator == token.Minus {
+			if v := AsPureBigInt(e.Operand); v.Known() {
+				return Known(new(big.Int).Neg(v.Val()))
+			}
+		}
+	}
+	return Unknown[*big.Int]()
+}
+
 // AsPureString gets the string value if it does not have any side effects.
 func AsPureString(expr *ast.Expression) Value[string] {
 	objectToStr := func(name string) string {
@@ -300,6 +337,8 @@ func AsPureString(expr *ast.Expression) Value[string] {
 			return Known("0")
 		}
 		return Known(ftoa.FormatFloat(e.Value, 'g', -1, 64))
+	case *ast.BigIntLiteral:
+		return Known(e.Value.String())
 	case *ast.BooleanLiteral:
 		return Known(fmt.Sprint(e.Value))
 	case *ast.NullLiteral:
@@ -470,11 +509,23 @@ func GetType(expr *ast.Expression) TypeValue {
 			if !lt.Unknown() && (lt.Val() == ObjectType{}) {
 				return TypeValue{Unknown[Type]()}
 			}
+			// BigInt only adds to BigInt, mixing it with anything else throws.
+			if !rt.Unknown() && !lt.Unknown() && (lt.Val() == BigIntType{} || rt.Val() == BigIntType{}) {
+				if lt == rt {
+					return lt
+				}
+				return TypeValue{Unknown[Type]()}
+			}
 			if !rt.Unknown() && !lt.Unknown() && !mayBeStr(lt.Val()) && !mayBeStr(rt.Val()) {
 				return TypeValue{Known[Type](NumberType{})}
 			}
-		case token.Or, token.ExclusiveOr, token.And, token.ShiftLeft, token.ShiftRight, token.UnsignedShiftRight,
+		case token.Or, token.ExclusiveOr, token.And, token.ShiftLeft, token.ShiftRight,
 			token.Minus, token.Multiply, token.Remainder, token.Slash, token.Exponent:
+			if IsBigInt(e.Left) && IsBigInt(e.Right) {
+				return TypeValue{Known[Type](BigIntType{})}
+			}
+			return TypeValue{Known[Type](NumberType{})}
+		case token.UnsignedShiftRight:
 			return TypeValue{Known[Type](NumberType{})}
 		case token.Equal, token.NotEqual, token.StrictEqual, token.StrictNotEqual, token.Less, token.LessOrEqual,
 			token.Greater, token.GreaterOrEqual, token.In, token.InstanceOf:
@@ -498,9 +549,16 @@ func GetType(expr *ast.Expression) TypeValue {
 		}
 	case *ast.NumberLiteral:
 		return TypeValue{Known[Type](NumberType{})}
+	case *ast.BigIntLiteral:
+		return TypeValue{Known[Type](BigIntType{})}
 	case *ast.UnaryExpression:
 		switch e.Operator {
-		case token.Minus, token.Plus, token.BitwiseNot:
+		case token.Minus, token.BitwiseNot:
+			if IsBigInt(e.Operand) {
+				return TypeValue{Known[Type](BigIntType{})}
+			}
+			return TypeValue{Known[Type](NumberType{})}
+		case token.Plus:
 			return TypeValue{Known[Type](NumberType{})}
 		case token.Not, token.Delete:
 			return TypeValue{Known[Type](BoolType{})}
@@ -579,7 +637,7 @@ func MayHaveSideEffects(expr *ast.Expression) bool {
 			return true
 		}
 		return false
-	case *ast.StringLiteral, *ast.NumberLiteral, *ast.BooleanLiteral, *ast.NullLiteral, *ast.RegExpLiteral:
+	case *ast.StringLiteral, *ast.NumberLiteral, *ast.BigIntLiteral, *ast.BooleanLiteral, *ast.NullLiteral, *ast.RegExpLiteral:
 		return false
 	// Function expression does not have any side effect if it's not used.
 	case *ast.FunctionLiteral, *ast.ArrowFunctionLiteral:
@@ -157,6 +157,7 @@ func (v *literalVisitor) VisitExpression(n *ast.Expression) {
 	}
 }
 func (v *literalVisitor) VisitFunctionLiteral(n *ast.FunctionLiteral)     { v.isLit = false }
+func (v *literalVisitor) VisitImportExpression(n *ast.ImportExpression)   { v.isLit = false }
 func (v *literalVisitor) VisitInvalidExpression(n *ast.InvalidExpression) { v.isLit = false }
 func (v *literalVisitor) VisitMemberExpression(n *ast.MemberExpression)   { v.isLit = false }
 func (v *literalVisitor) VisitMetaProperty(n *ast.MetaProperty)           { v.isLit = false }
@@ -226,7 +227,7 @@ func PreserveEffects(val ast.Expression, exprs []ast.Expression) ast.Expression
 // ExtractSideEffectsTo adds side effects of expr to to.
 func ExtractSideEffectsTo(to *[]ast.Expression, expr *ast.Expression) {
 	switch e := expr.Expr.(type) {
-	case *ast.StringLiteral, *ast.BooleanLiteral, *ast.NullLiteral, *ast.NumberLiteral, *ast.RegExpLiteral,
+	case *ast.StringLiteral, *ast.BooleanLiteral, *ast.NullLiteral, *ast.NumberLiteral, *ast.BigIntLiteral, *ast.RegExpLiteral,
 		*ast.ThisExpression, *ast.FunctionLiteral, *ast.ArrowFunctionLiteral, *ast.PrivateIdentifier:
 	case *ast.Identifier:
 		if MayHaveSideEffects(expr) {
@@ -238,7 +239,7 @@ func ExtractSideEffectsTo(to *[]ast.Expression, expr *ast.Expression) {
 	// TODO
 	case *ast.MetaProperty:
 		*to = append(*to, *expr)
-	case *ast.CallExpression:
+	case *ast.CallExpression, *ast.ImportExpression:
 		*to = append(*to, *expr)
 	case *ast.NewExpression:
 		// Known constructors
@@ -11,6 +11,7 @@ type (
 	StringType    struct{}
 	SymbolType    struct{}
 	NumberType    struct{}
+	BigIntType    struct{}
 	ObjectType    struct{}
 )
 
@@ -94,4 +95,5 @@ func (BoolType) _type()      {}
 func (StringType) _type()    {}
 func (SymbolType) _type()    {}
 func (NumberType) _type()    {}
+func (BigIntType) _type()    {}
 func (ObjectType) _type()    {}
@@ -8,6 +8,9 @@ type (
 		Body          *BlockStatement
 
 		Async, Generator bool
+		// Strict is set when the function is strict mode code, either through
+		// its own directive prologue or because it is nested in strict mode code.
+		Strict bool
 
 		ScopeContext ScopeContext
 	}
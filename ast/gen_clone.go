@@ -54,7 +54,7 @@ func newChild(fieldName, fieldType string, cloneable, pointer, optional bool) Ch
 func main() {
 	fset := token.NewFileSet()
 	pkgs, err := parser.ParseDir(fset, "./ast", func(info fs.FileInfo) bool {
-		return info.Name() != "clone.go" && info.Name() != "visit.go"
+		return info.Name() != "clone.go" && info.Name() != "visit.go" && info.Name() != "utilities.go" && info.Name() != "source.go"
 	}, parser.ParseComments)
 	if err != nil {
 		log.Fatalf("%v", err)
@@ -332,7 +332,7 @@ func findCloneableNodes(f *ast.File) (types []CloneableNodeType) {
 			}
 
 			switch typeSpec.Name.Name {
-			case "ScopeContext", "Id":
+			case "ScopeContext", "Id", "Comment", "NodeComments":
 				continue
 			}
 
@@ -371,18 +371,21 @@ func findStructChildren(fields []*ast.Field) (children []Child) {
 			}
 
 			switch fieldType.Name {
-			case "Idx", "any", "bool", "int", "ScopeContext", "string", "PropertyKind", "Token", "float64":
+			case "Idx", "any", "bool", "int", "ScopeContext", "string", "PropertyKind", "SourceType", "Token", "float64":
 				children = append(children, newChild(field.Names[0].Name, fieldType.Name, false, false, optional))
 			default:
 				children = append(children, newChild(field.Names[0].Name, fieldType.Name, true, false, optional))
 			}
 		case *ast.StarExpr:
 			if ident, ok := fieldType.X.(*ast.Ident); ok {
-				if ident.Name == "string" {
+				if ident.Name == "string" || ident.Name == "SourceFile" {
 					children = append(children, newChild(field.Names[0].Name, ident.Name, false, false, optional))
 					continue
 				}
 				children = append(children, newChild(field.Names[0].Name, ident.Name, true, true, optional))
+			} else if _, ok := fieldType.X.(*ast.SelectorExpr); ok {
+				// Foreign types such as *big.Int are immutable values here.
+				children = append(children, newChild(field.Names[0].Name, "", false, false, optional))
 			} else {
 				children = append(children, newChild(field.Names[0].Name, "", true, false, optional))
 			}
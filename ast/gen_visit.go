@@ -43,7 +43,7 @@ func newChild(fieldName string, optional bool) Child {
 func main() {
 	fset := token.NewFileSet()
 	pkgs, err := parser.ParseDir(fset, "./ast", func(info fs.FileInfo) bool {
-		return info.Name() != "visit.go" && info.Name() != "utilities.go"
+		return info.Name() != "visit.go" && info.Name() != "utilities.go" && info.Name() != "source.go"
 	}, parser.ParseComments)
 	if err != nil {
 		log.Fatalf("%v", err)
@@ -221,7 +221,7 @@ func findVisitableNodes(f *ast.File) (types []VisitableNodeType) {
 			}
 
 			switch typeSpec.Name.Name {
-			case "ScopeContext", "Id":
+			case "ScopeContext", "Id", "Comment", "NodeComments":
 				continue
 			}
 
@@ -259,13 +259,16 @@ func findStructChildren(fields []*ast.Field) (children []Child) {
 			}
 
 			switch fieldType.Name {
-			case "Idx", "any", "bool", "int", "ScopeContext", "string", "PropertyKind", "float64":
+			case "Idx", "any", "bool", "int", "ScopeContext", "string", "PropertyKind", "SourceType", "float64":
 			default:
 				fmt.Println(fieldType.Name)
 				children = append(children, newChild(field.Names[0].Name, optional))
 			}
 		case *ast.StarExpr:
-			if ident, ok := fieldType.X.(*ast.Ident); ok && ident.Name == "string" {
+			if ident, ok := fieldType.X.(*ast.Ident); ok && (ident.Name == "string" || ident.Name == "SourceFile") {
+				continue
+			}
+			if _, ok := fieldType.X.(*ast.SelectorExpr); ok {
 				continue
 			}
 			children = append(children, newChild(field.Names[0].Name, optional))
@@ -0,0 +1,108 @@
+package ast
+
+type (
+	// JSXElement is an element such as <a href={url}>text</a> or <br />.
+	JSXElement struct {
+		Opening  *JSXOpeningElement
+		Children JSXChildren
+		Closing  *JSXClosingElement `optional:"true"` // nil if self-closing
+	}
+
+	JSXOpeningElement struct {
+		LessThan    Idx
+		Name        *JSXName
+		Attributes  JSXAttributes
+		SelfClosing bool
+		GreaterThan Idx
+	}
+
+	JSXClosingElement struct {
+		LessThan    Idx
+		Name        *JSXName
+		GreaterThan Idx
+	}
+
+	// JSXFragment is a list of children without an element, as in <>a<b /></>.
+	JSXFragment struct {
+		OpeningLessThan    Idx
+		OpeningGreaterThan Idx
+		Children           JSXChildren
+		ClosingLessThan    Idx
+		ClosingGreaterThan Idx
+	}
+
+	// JSXName is the name of an element or attribute. Besides an identifier,
+	// which may contain dashes, it can be a member expression like Foo.Bar or a
+	// namespaced name like svg:rect.
+	JSXName struct {
+		Idx  Idx
+		Name string
+	}
+
+	JSXAttributes []JSXAttributeItem
+
+	JSXAttributeItem struct {
+		Attribute JSXAttr
+	}
+
+	JSXAttr interface {
+		Node
+		VisitableNode
+		_jsxAttribute()
+	}
+
+	// JSXAttribute is an attribute such as disabled or href={url}. Its value
+	// is a StringLiteral, JSXExpressionContainer, JSXElement or JSXFragment.
+	JSXAttribute struct {
+		Name  *JSXName
+		Value *Expression `optional:"true"`
+	}
+
+	// JSXSpreadAttribute is an attribute such as {...props}.
+	JSXSpreadAttribute struct {
+		LeftBrace  Idx
+		Argument   *Expression
+		RightBrace Idx
+	}
+
+	JSXChildren []JSXChildItem
+
+	JSXChildItem struct {
+		Child JSXChild
+	}
+
+	JSXChild interface {
+		Node
+		VisitableNode
+		_jsxChild()
+	}
+
+	// JSXExpressionContainer is an expression in braces used as an attribute
+	// value or child. Expression is nil for an empty container such as {} or
+	// {/* comment */}, and a SpreadElement for a spread child.
+	JSXExpressionContainer struct {
+		LeftBrace  Idx
+		Expression *Expression `optional:"true"`
+		RightBrace Idx
+	}
+
+	// JSXText is the text between tags.
+	JSXText struct {
+		Idx Idx
+		// Value is the text with HTML character references decoded.
+		Value string
+		Raw   string
+	}
+)
+
+func (*JSXElement) _expr()             {}
+func (*JSXFragment) _expr()            {}
+func (*JSXExpressionContainer) _expr() {}
+
+func (*JSXAttribute) _jsxAttribute()       {}
+func (*JSXSpreadAttribute) _jsxAttribute() {}
+
+func (*JSXElement) _jsxChild()             {}
+func (*JSXFragment) _jsxChild()            {}
+func (*JSXExpressionContainer) _jsxChild() {}
+func (*JSXText) _jsxChild()                {}
@@ -1,6 +1,15 @@
 package ast
 
+import "math/big"
+
 type (
+	BigIntLiteral struct {
+		Idx   Idx
+		Value *big.Int
+
+		Raw *string
+	}
+
 	BooleanLiteral struct {
 		Idx   Idx
 		Value bool
@@ -33,6 +42,7 @@ type (
 	}
 )
 
+func (*BigIntLiteral) _expr()  {}
 func (*BooleanLiteral) _expr() {}
 func (*NullLiteral) _expr()    {}
 func (*NumberLiteral) _expr()  {}
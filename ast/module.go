@@ -0,0 +1,105 @@
+package ast
+
+type (
+	ImportDeclaration struct {
+		Import     Idx
+		Specifiers ImportSpecifiers
+		Source     *StringLiteral
+	}
+
+	ImportSpecifiers []ImportSpecifier
+
+	ImportSpecifier struct {
+		Specifier ImportSpec
+	}
+
+	ImportSpec interface {
+		Node
+		VisitableNode
+		_importSpecifier()
+	}
+
+	// ImportNamedSpecifier is `{ imported as local }` or `{ local }`.
+	ImportNamedSpecifier struct {
+		Imported *ModuleExportName
+		Local    *Identifier
+	}
+
+	// ImportDefaultSpecifier is `local` in `import local from "..."`.
+	ImportDefaultSpecifier struct {
+		Local *Identifier
+	}
+
+	// ImportNamespaceSpecifier is `* as local`.
+	ImportNamespaceSpecifier struct {
+		Star  Idx
+		Local *Identifier
+	}
+
+	ExportNamedDeclaration struct {
+		Export      Idx
+		Declaration *Statement `optional:"true"`
+		Specifiers  ExportSpecifiers
+		Source      *StringLiteral `optional:"true"`
+		RightBrace  Idx
+	}
+
+	ExportSpecifiers []ExportSpecifier
+
+	ExportSpecifier struct {
+		Local    *ModuleExportName
+		Exported *ModuleExportName
+	}
+
+	ExportDefaultDeclaration struct {
+		Export Idx
+		// Declaration is set for named function and class declarations,
+		// Expression for everything else.
+		Declaration *Statement  `optional:"true"`
+		Expression  *Expression `optional:"true"`
+	}
+
+	ExportAllDeclaration struct {
+		Export   Idx
+		Exported *ModuleExportName `optional:"true"`
+		Source   *StringLiteral
+	}
+
+	// ModuleExportName is an imported or exported name, either an identifier or a string literal.
+	ModuleExportName struct {
+		Name ExportName
+	}
+
+	ExportName interface {
+		Node
+		VisitableNode
+		_moduleExportName()
+	}
+)
+
+func (*ImportNamedSpecifier) _importSpecifier()     {}
+func (*ImportDefaultSpecifier) _importSpecifier()   {}
+func (*ImportNamespaceSpecifier) _importSpecifier() {}
+
+func (*Identifier) _moduleExportName()    {}
+func (*StringLiteral) _moduleExportName() {}
+
+func (*ImportDeclaration) _stmt()        {}
+func (*ExportNamedDeclaration) _stmt()   {}
+func (*ExportDefaultDeclaration) _stmt() {}
+func (*ExportAllDeclaration) _stmt()     {}
+
+// SourceType is the goal symbol a Program was parsed with.
+type SourceType int
+
+const (
+	SourceTypeScript SourceType = iota
+	SourceTypeModule
+)
+
+func (t SourceType) String() string {
+	if t == SourceTypeModule {
+		return "module"
+	}
+	return "script"
+}
@@ -20,7 +20,47 @@ type VisitableNode interface {
 }
 
 type Program struct {
-	Body Statements
+	Directives Directives
+	Body       Statements
+
+	SourceType SourceType
+	// Strict is set when the program is strict mode code, either because it
+	// is a module or because of a "use strict" directive.
+	Strict bool
+	// WebCompat is set when the program was parsed with the web
+	// compatibility extensions of Annex B, under which functions declared in
+	// blocks of sloppy mode code are also visible in the enclosing function.
+	WebCompat bool
+
+	// Comments lists every comment in source order. It is only populated
+	// when comments are collected while parsing.
+	Comments []*Comment
+	// CommentMap holds the comments attached to statements and expressions.
+	// The leading comments of the program itself are the file header, which
+	// is kept whatever happens to the statements.
+	//
+	// Clone copies neither Comments nor CommentMap, whose keys are the nodes
+	// of this program and not those of the clone.
+	CommentMap map[Node]*NodeComments
+
+	// Source is the parsed source text, which maps the positions of the
+	// nodes to lines and columns.
+	Source *SourceFile
+
+	// OnRelease is called by Release. A parser.Parser sets it to take back
+	// the memory of the nodes.
+	OnRelease func()
+}
+
+// Release declares that the program is no longer needed, so that the parser
+// that produced it can reuse the memory of its nodes for later parses. The
+// program and its nodes must not be used after Release, while clones of them
+// stay valid. Calling it more than once does nothing.
+func (n *Program) Release() {
+	if release := n.OnRelease; release != nil {
+		n.OnRelease = nil
+		release()
+	}
 }
 
 func (o *Optional) Idx0() Idx              { return o.Expr.Expr.Idx0() }
@@ -33,12 +73,12 @@ func (y *YieldExpression) Idx0() Idx       { return y.Yield }
 func (a *AwaitExpression) Idx0() Idx       { return a.Await }
 func (a *AssignExpression) Idx0() Idx      { return a.Left.Expr.Idx0() }
 func (b *BinaryExpression) Idx0() Idx      { return b.Left.Expr.Idx0() }
+func (b *BigIntLiteral) Idx0() Idx         { return b.Idx }
 func (b *BooleanLiteral) Idx0() Idx        { return b.Idx }
 func (n *CallExpression) Idx0() Idx        { return n.Callee.Expr.Idx0() }
 func (n *ConditionalExpression) Idx0() Idx { return n.Test.Expr.Idx0() }
 func (p *PrivateDotExpression) Idx0() Idx  { return p.Left.Expr.Idx0() }
 func (f *FunctionLiteral) Idx0() Idx       { return f.Function }
-func (c *ClassLiteral) Idx0() Idx          { return c.Class }
 func (a *ArrowFunctionLiteral) Idx0() Idx  { return a.Start }
 func (i *Identifier) Idx0() Idx            { return i.Idx }
 func (n *InvalidExpression) Idx0() Idx     { return n.From }
@@ -56,6 +96,7 @@ func (n *SuperExpression) Idx0() Idx       { return n.Idx }
 func (n *UnaryExpression) Idx0() Idx       { return n.Idx }
 func (n *UpdateExpression) Idx0() Idx      { return n.Idx }
 func (n *MetaProperty) Idx0() Idx          { return n.Idx }
+func (n *ImportExpression) Idx0() Idx      { return n.Import }
 func (m *MemberExpression) Idx0() Idx { return m.Object.Expr.Idx0() }
 func (m *MemberExpression) Idx1() Idx {
 	if m.RightBracket > 0 {
@@ -83,6 +124,7 @@ func (n *ContinueStatement) Idx0() Idx   { return n.Idx }
 func (n *CaseStatement) Idx0() Idx       { return n.Case }
 func (n *CatchStatement) Idx0() Idx      { return n.Catch }
 func (n *DebuggerStatement) Idx0() Idx   { return n.Debugger }
+func (n *Directive) Idx0() Idx           { return n.Idx }
 func (n *DoWhileStatement) Idx0() Idx    { return n.Do }
 func (n *EmptyStatement) Idx0() Idx      { return n.Semicolon }
 func (n *ExpressionStatement) Idx0() Idx { return n.Expression.Expr.Idx0() }
@@ -91,7 +133,12 @@ func (n *ForOfStatement) Idx0() Idx      { return n.For }
 func (n *ForStatement) Idx0() Idx        { return n.For }
 func (n *IfStatement) Idx0() Idx         { return n.If }
 func (n *LabelledStatement) Idx0() Idx   { return n.Label.Idx0() }
-func (n *Program) Idx0() Idx             { return n.Body[0].Stmt.Idx0() }
+func (n *Program) Idx0() Idx {
+	if len(n.Directives) > 0 {
+		return n.Directives[0].Idx0()
+	}
+	return n.Body[0].Stmt.Idx0()
+}
 func (n *ReturnStatement) Idx0() Idx     { return n.Return }
 func (n *SwitchStatement) Idx0() Idx     { return n.Switch }
 func (n *ThrowStatement) Idx0() Idx      { return n.Throw }
@@ -106,12 +153,42 @@ func (b *VariableDeclarator) Idx0() Idx  { return b.Target.Idx0() }
 func (n *PropertyShort) Idx0() Idx { return n.Name.Idx }
 func (n *PropertyKeyed) Idx0() Idx { return n.Key.Expr.Idx0() }
 
-func (n *FieldDefinition) Idx0() Idx  { return n.Idx }
-func (n *MethodDefinition) Idx0() Idx { return n.Idx }
-func (n *ClassStaticBlock) Idx0() Idx { return n.Static }
+func (c *ClassLiteral) Idx0() Idx {
+	if len(c.Decorators) > 0 {
+		return c.Decorators[0].At
+	}
+	return c.Class
+}
+
+func (n *FieldDefinition) Idx0() Idx       { return n.Idx }
+func (n *MethodDefinition) Idx0() Idx      { return n.Idx }
+func (n *ClassAccessorProperty) Idx0() Idx { return n.Idx }
+func (n *ClassStaticBlock) Idx0() Idx      { return n.Static }
+func (n *Decorator) Idx0() Idx             { return n.At }
 
 func (n *ForLoopInitializer) Idx0() Idx { return 0 }
 
+func (n *ImportDeclaration) Idx0() Idx        { return n.Import }
+func (n *ImportSpecifier) Idx0() Idx          { return n.Specifier.Idx0() }
+func (n *ImportNamedSpecifier) Idx0() Idx     { return n.Imported.Idx0() }
+func (n *ImportDefaultSpecifier) Idx0() Idx   { return n.Local.Idx0() }
+func (n *ImportNamespaceSpecifier) Idx0() Idx { return n.Star }
+func (n *ExportNamedDeclaration) Idx0() Idx   { return n.Export }
+func (n *ExportSpecifier) Idx0() Idx          { return n.Local.Idx0() }
+func (n *ExportDefaultDeclaration) Idx0() Idx { return n.Export }
+func (n *ExportAllDeclaration) Idx0() Idx     { return n.Export }
+func (n *ModuleExportName) Idx0() Idx         { return n.Name.Idx0() }
+
+func (n *JSXElement) Idx0() Idx             { return n.Opening.LessThan }
+func (n *JSXOpeningElement) Idx0() Idx      { return n.LessThan }
+func (n *JSXClosingElement) Idx0() Idx      { return n.LessThan }
+func (n *JSXFragment) Idx0() Idx            { return n.OpeningLessThan }
+func (n *JSXName) Idx0() Idx                { return n.Idx }
+func (n *JSXAttribute) Idx0() Idx           { return n.Name.Idx }
+func (n *JSXSpreadAttribute) Idx0() Idx     { return n.LeftBrace }
+func (n *JSXExpressionContainer) Idx0() Idx { return n.LeftBrace }
+func (n *JSXText) Idx0() Idx                { return n.Idx }
+
 func (o *Optional) Idx1() Idx              { return o.Expr.Expr.Idx1() }
 func (n *OptionalChain) Idx1() Idx         { return n.Base.Expr.Idx1() }
 func (a *ArrayLiteral) Idx1() Idx          { return a.RightBracket + 1 }
@@ -136,6 +213,12 @@ func (n *NewExpression) Idx1() Idx {
 	}
 }
 func (n *NullLiteral) Idx1() Idx { return Idx(int(n.Idx) + 4) } // "null"
+func (b *BigIntLiteral) Idx1() Idx {
+	if b.Raw != nil {
+		return Idx(int(b.Idx) + len(*b.Raw))
+	}
+	return Idx(int(b.Idx) + len(b.Value.String()) + 1) // +1 for the "n" suffix
+}
 func (n *NumberLiteral) Idx1() Idx {
 	if n.Raw != nil {
 		return Idx(int(n.Idx) + len(*n.Raw))
@@ -170,6 +253,9 @@ func (n *UpdateExpression) Idx1() Idx {
 func (n *MetaProperty) Idx1() Idx {
 	return n.Property.Idx1()
 }
+func (n *ImportExpression) Idx1() Idx {
+	return n.RightParenthesis + 1
+}
 func (n *PrivateIdentifier) Idx0() Idx {
 	return n.Identifier.Idx0()
 }
@@ -177,13 +263,22 @@ func (n *PrivateIdentifier) Idx1() Idx {
 	return n.Identifier.Idx1()
 }
 
-func (n *BadStatement) Idx1() Idx        { return n.To }
-func (n *BlockStatement) Idx1() Idx      { return n.RightBrace + 1 }
-func (n *BreakStatement) Idx1() Idx      { return n.Idx }
-func (n *ContinueStatement) Idx1() Idx   { return n.Idx }
-func (n *CaseStatement) Idx1() Idx       { return n.Consequent[len(n.Consequent)-1].Stmt.Idx1() }
+func (n *BadStatement) Idx1() Idx      { return n.To }
+func (n *BlockStatement) Idx1() Idx    { return n.RightBrace + 1 }
+func (n *BreakStatement) Idx1() Idx    { return n.Idx }
+func (n *ContinueStatement) Idx1() Idx { return n.Idx }
+func (n *CaseStatement) Idx1() Idx {
+	if len(n.Consequent) > 0 {
+		return n.Consequent[len(n.Consequent)-1].Stmt.Idx1()
+	}
+	if n.Test != nil {
+		return n.Test.Expr.Idx1() + 1
+	}
+	return n.Case + 8
+}
 func (n *CatchStatement) Idx1() Idx      { return n.Body.Idx1() }
 func (n *DebuggerStatement) Idx1() Idx   { return n.Debugger + 8 }
+func (n *Directive) Idx1() Idx           { return n.Idx + Idx(len(n.Raw)) }
 func (n *DoWhileStatement) Idx1() Idx    { return n.Test.Expr.Idx1() }
 func (n *EmptyStatement) Idx1() Idx      { return n.Semicolon + 1 }
 func (n *ExpressionStatement) Idx1() Idx { return n.Expression.Expr.Idx1() }
@@ -197,15 +292,25 @@ func (n *IfStatement) Idx1() Idx {
 	return n.Consequent.Stmt.Idx1()
 }
 func (n *LabelledStatement) Idx1() Idx { return n.Colon + 1 }
-func (n *Program) Idx1() Idx           { return n.Body[len(n.Body)-1].Stmt.Idx1() }
+func (n *Program) Idx1() Idx {
+	if len(n.Body) == 0 && len(n.Directives) > 0 {
+		return n.Directives[len(n.Directives)-1].Idx1()
+	}
+	return n.Body[len(n.Body)-1].Stmt.Idx1()
+}
 func (n *ReturnStatement) Idx1() Idx {
 	if n.Argument != nil {
 		return n.Argument.Expr.Idx1()
 	}
 	return n.Return + 6
 }
-func (n *SwitchStatement) Idx1() Idx   { return n.Body[len(n.Body)-1].Idx1() }
-func (n *ThrowStatement) Idx1() Idx    { return n.Argument.Expr.Idx1() }
+func (n *SwitchStatement) Idx1() Idx {
+	if len(n.Body) > 0 {
+		return n.Body[len(n.Body)-1].Idx1()
+	}
+	return n.Discriminant.Expr.Idx1() + 1
+}
+func (n *ThrowStatement) Idx1() Idx { return n.Argument.Expr.Idx1() }
 func (n *TryStatement) Idx1() Idx {
 	if n.Finally != nil {
 		return n.Finally.Idx1()
@@ -247,6 +352,17 @@ func (n *MethodDefinition) Idx1() Idx {
 	return n.Body.Idx1()
 }
 
+func (n *ClassAccessorProperty) Idx1() Idx {
+	if n.Initializer != nil {
+		return n.Initializer.Expr.Idx1()
+	}
+	return n.Key.Expr.Idx1()
+}
+
+func (n *Decorator) Idx1() Idx {
+	return n.Expression.Expr.Idx1()
+}
+
 func (n *ClassStaticBlock) Idx1() Idx {
 	return n.Block.Idx1()
 }
@@ -259,6 +375,50 @@ func (y *YieldExpression) Idx1() Idx {
 }
 func (n *ForLoopInitializer) Idx1() Idx { return 0 }
 
+func (n *ImportDeclaration) Idx1() Idx        { return n.Source.Idx1() }
+func (n *ImportSpecifier) Idx1() Idx          { return n.Specifier.Idx1() }
+func (n *ImportNamedSpecifier) Idx1() Idx     { return n.Local.Idx1() }
+func (n *ImportDefaultSpecifier) Idx1() Idx   { return n.Local.Idx1() }
+func (n *ImportNamespaceSpecifier) Idx1() Idx { return n.Local.Idx1() }
+func (n *ExportNamedDeclaration) Idx1() Idx {
+	if n.Source != nil {
+		return n.Source.Idx1()
+	}
+	if n.Declaration != nil {
+		return n.Declaration.Idx1()
+	}
+	return n.RightBrace + 1
+}
+func (n *ExportSpecifier) Idx1() Idx { return n.Exported.Idx1() }
+func (n *ExportDefaultDeclaration) Idx1() Idx {
+	if n.Declaration != nil {
+		return n.Declaration.Idx1()
+	}
+	return n.Expression.Idx1()
+}
+func (n *ExportAllDeclaration) Idx1() Idx { return n.Source.Idx1() }
+func (n *ModuleExportName) Idx1() Idx     { return n.Name.Idx1() }
+
+func (n *JSXElement) Idx1() Idx {
+	if n.Closing != nil {
+		return n.Closing.GreaterThan + 1
+	}
+	return n.Opening.GreaterThan + 1
+}
+func (n *JSXOpeningElement) Idx1() Idx      { return n.GreaterThan + 1 }
+func (n *JSXClosingElement) Idx1() Idx      { return n.GreaterThan + 1 }
+func (n *JSXFragment) Idx1() Idx            { return n.ClosingGreaterThan + 1 }
+func (n *JSXName) Idx1() Idx                { return n.Idx + Idx(len(n.Name)) }
+func (n *JSXSpreadAttribute) Idx1() Idx     { return n.RightBrace + 1 }
+func (n *JSXExpressionContainer) Idx1() Idx { return n.RightBrace + 1 }
+func (n *JSXText) Idx1() Idx                { return n.Idx + Idx(len(n.Raw)) }
+func (n *JSXAttribute) Idx1() Idx {
+	if n.Value != nil {
+		return n.Value.Idx1()
+	}
+	return n.Name.Idx1()
+}
+
 func (n *ConciseBody) Idx0() Idx { return n.Body.Idx0() }
 func (n *ConciseBody) Idx1() Idx { return n.Body.Idx1() }
 func (n *Expression) Idx0() Idx  { return n.Expr.Idx0() }
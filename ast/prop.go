@@ -23,7 +23,7 @@ type (
 
 	PropertyShort struct {
 		Name        *Identifier
-		Initializer *Expression
+		Initializer *Expression `optional:"true"`
 	}
 
 	PropertyKeyed struct {
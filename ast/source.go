@@ -0,0 +1,226 @@
+package ast
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// ColumnUnit is the unit in which a SourceFile counts columns.
+type ColumnUnit int
+
+const (
+	// ColumnBytes counts columns in bytes of the UTF-8 source text.
+	ColumnBytes ColumnUnit = iota
+	// ColumnUTF16 counts columns in UTF-16 code units, like JavaScript
+	// strings, ESTree loc objects and source maps do.
+	ColumnUTF16
+	// ColumnCodePoints counts columns in Unicode code points.
+	ColumnCodePoints
+)
+
+// Position is a line and column in a SourceFile. Lines count from 1 and
+// columns from 0, as in ESTree loc objects.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// markInterval is the number of bytes between the marks of a line.
+const markInterval = 64
+
+// SourceFile is the source text of a parse together with an index of its
+// lines, which maps an Idx to its line and column and back again without
+// rescanning the text. Lines are ended by \n, \r, \r\n, U+2028 and U+2029,
+// the line terminators of JavaScript.
+//
+// A SourceFile is immutable and safe for concurrent use.
+type SourceFile struct {
+	text  string
+	lines []sourceLine
+}
+
+type sourceLine struct {
+	start int // The offset of the first byte of the line
+	end   int // The offset of the line terminator
+	// extra16 and extraPoints are how many more bytes than UTF-16 code
+	// units and code points precede the line.
+	extra16     int
+	extraPoints int
+	// marks holds the column of every markInterval'th byte of the line in
+	// UTF-16 code units and code points, so that columns are found without
+	// scanning the whole line. It is nil for a line of ASCII text only, on
+	// which every unit counts alike.
+	marks []lineMark
+}
+
+type lineMark struct {
+	offset int // The offset of the first character at or after the mark
+	utf16  int
+	points int
+}
+
+// NewSourceFile indexes the lines of text.
+func NewSourceFile(text string) *SourceFile {
+	f := &SourceFile{text: text}
+	start, ascii := 0, true
+	var extra16, extraPoints int
+	line := sourceLine{}
+	endLine := func(end, next int) {
+		line.end = end
+		if !ascii {
+			line.marks = markLine(text[start:end], start)
+		}
+		f.lines = append(f.lines, line)
+		start, ascii = next, true
+		line = sourceLine{start: start, extra16: extra16, extraPoints: extraPoints}
+	}
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '\n':
+			endLine(i, i+1)
+			i++
+		case c == '\r':
+			if i+1 < len(text) && text[i+1] == '\n' {
+				endLine(i, i+2)
+				i += 2
+			} else {
+				endLine(i, i+1)
+				i++
+			}
+		case c < utf8.RuneSelf:
+			i++
+		default:
+			r, size := utf8.DecodeRuneInString(text[i:])
+			extra16 += size - runeLen16(r)
+			extraPoints += size - 1
+			if r == '\u2028' || r == '\u2029' {
+				endLine(i, i+size)
+			} else {
+				ascii = false
+			}
+			i += size
+		}
+	}
+	endLine(len(text), len(text))
+	return f
+}
+
+// markLine returns the marks of line, which starts at offset start.
+func markLine(line string, start int) []lineMark {
+	marks := make([]lineMark, 0, len(line)/markInterval+1)
+	var utf16, points int
+	for i := 0; i < len(line); {
+		for len(marks)*markInterval <= i {
+			marks = append(marks, lineMark{offset: start + i, utf16: utf16, points: points})
+		}
+		r, size := utf8.DecodeRuneInString(line[i:])
+		i += size
+		utf16 += runeLen16(r)
+		points++
+	}
+	return marks
+}
+
+func runeLen16(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+// Text returns the source text of the file.
+func (f *SourceFile) Text() string {
+	return f.text
+}
+
+// LineCount returns the number of lines of the file.
+func (f *SourceFile) LineCount() int {
+	return len(f.lines)
+}
+
+// Offset returns the byte offset of idx, clamped to the text.
+func (f *SourceFile) Offset(idx Idx) int {
+	return min(max(int(idx)-1, 0), len(f.text))
+}
+
+// OffsetIn returns the offset of idx from the start of the text, counted in
+// unit. With ColumnUTF16 it is the index into the text as a JavaScript
+// string.
+func (f *SourceFile) OffsetIn(idx Idx, unit ColumnUnit) int {
+	pos := f.Position(idx, unit)
+	line := f.lines[pos.Line-1]
+	switch unit {
+	case ColumnUTF16:
+		return line.start - line.extra16 + pos.Column
+	case ColumnCodePoints:
+		return line.start - line.extraPoints + pos.Column
+	}
+	return line.start + pos.Column
+}
+
+// Position returns the line and column of idx, counting the column in unit.
+func (f *SourceFile) Position(idx Idx, unit ColumnUnit) Position {
+	offset := f.Offset(idx)
+	n := sort.Search(len(f.lines), func(i int) bool { return f.lines[i].start > offset }) - 1
+	line := f.lines[n]
+	column := offset - line.start
+	if unit == ColumnBytes || line.marks == nil {
+		return Position{Line: n + 1, Column: column}
+	}
+	m := line.marks[min(column/markInterval, len(line.marks)-1)]
+	if m.offset > offset {
+		// The mark follows a character that straddles it.
+		m = line.marks[column/markInterval-1]
+	}
+	i, column := m.offset, m.points
+	if unit == ColumnUTF16 {
+		column = m.utf16
+	}
+	for i < offset {
+		r, size := utf8.DecodeRuneInString(f.text[i:])
+		i += size
+		if unit == ColumnUTF16 {
+			column += runeLen16(r)
+		} else {
+			column++
+		}
+	}
+	return Position{Line: n + 1, Column: column}
+}
+
+// Idx returns the index of pos, whose column is counted in unit. It is the
+// inverse of Position. Lines and columns past the end are clamped to the
+// end of the file and of the line.
+func (f *SourceFile) Idx(pos Position, unit ColumnUnit) Idx {
+	if pos.Line < 1 {
+		return 1
+	}
+	if pos.Line > len(f.lines) {
+		return Idx(len(f.text) + 1)
+	}
+	line := f.lines[pos.Line-1]
+	column := max(pos.Column, 0)
+	if unit == ColumnBytes || line.marks == nil {
+		return Idx(min(line.start+column, line.end) + 1)
+	}
+	units := func(m lineMark) int {
+		if unit == ColumnUTF16 {
+			return m.utf16
+		}
+		return m.points
+	}
+	k := sort.Search(len(line.marks), func(i int) bool { return units(line.marks[i]) > column }) - 1
+	m := line.marks[max(k, 0)]
+	i, n := m.offset, units(m)
+	for i < line.end && n < column {
+		r, size := utf8.DecodeRuneInString(f.text[i:])
+		i += size
+		if unit == ColumnUTF16 {
+			n += runeLen16(r)
+		} else {
+			n++
+		}
+	}
+	return Idx(i + 1)
+}
@@ -20,7 +20,9 @@ type (
 	}
 
 	BlockStatement struct {
-		LeftBrace  Idx
+		LeftBrace Idx
+		// Directives is the directive prologue of a function body.
+		Directives Directives
 		List       Statements
 		RightBrace Idx
 
@@ -55,6 +57,19 @@ type (
 		Debugger Idx
 	}
 
+	Directives []Directive
+
+	// Directive is a string literal statement of a directive prologue, such as "use strict".
+	Directive struct {
+		Idx Idx
+		// Value is the source text between the quotes. Escapes are not
+		// interpreted, since a directive only has meaning if written literally.
+		Value string
+		// Cooked is the value of the string literal, with escapes interpreted.
+		Cooked string
+		Raw    string
+	}
+
 	DoWhileStatement struct {
 		Do   Idx
 		Test *Expression
@@ -148,6 +163,8 @@ type (
 		Into   *ForInto
 		Source *Expression
 		Body   *Statement
+		// Await is set for a for await...of loop over an async iterable.
+		Await bool
 	}
 
 	ForInto struct {
@@ -8,6 +8,7 @@ type Visitor interface {
 	VisitAssignExpression(n *AssignExpression)
 	VisitAwaitExpression(n *AwaitExpression)
 	VisitBadStatement(n *BadStatement)
+	VisitBigIntLiteral(n *BigIntLiteral)
 	VisitBinaryExpression(n *BinaryExpression)
 	VisitBindingTarget(n *BindingTarget)
 	VisitBlockStatement(n *BlockStatement)
@@ -17,6 +18,7 @@ type Visitor interface {
 	VisitCaseStatement(n *CaseStatement)
 	VisitCaseStatements(n *CaseStatements)
 	VisitCatchStatement(n *CatchStatement)
+	VisitClassAccessorProperty(n *ClassAccessorProperty)
 	VisitClassDeclaration(n *ClassDeclaration)
 	VisitClassElement(n *ClassElement)
 	VisitClassElements(n *ClassElements)
@@ -27,8 +29,17 @@ type Visitor interface {
 	VisitConditionalExpression(n *ConditionalExpression)
 	VisitContinueStatement(n *ContinueStatement)
 	VisitDebuggerStatement(n *DebuggerStatement)
+	VisitDecorator(n *Decorator)
+	VisitDecorators(n *Decorators)
+	VisitDirective(n *Directive)
+	VisitDirectives(n *Directives)
 	VisitDoWhileStatement(n *DoWhileStatement)
 	VisitEmptyStatement(n *EmptyStatement)
+	VisitExportAllDeclaration(n *ExportAllDeclaration)
+	VisitExportDefaultDeclaration(n *ExportDefaultDeclaration)
+	VisitExportNamedDeclaration(n *ExportNamedDeclaration)
+	VisitExportSpecifier(n *ExportSpecifier)
+	VisitExportSpecifiers(n *ExportSpecifiers)
 	VisitExpression(n *Expression)
 	VisitExpressionStatement(n *ExpressionStatement)
 	VisitExpressions(n *Expressions)
@@ -42,12 +53,33 @@ type Visitor interface {
 	VisitFunctionLiteral(n *FunctionLiteral)
 	VisitIdentifier(n *Identifier)
 	VisitIfStatement(n *IfStatement)
+	VisitImportDeclaration(n *ImportDeclaration)
+	VisitImportDefaultSpecifier(n *ImportDefaultSpecifier)
+	VisitImportExpression(n *ImportExpression)
+	VisitImportNamedSpecifier(n *ImportNamedSpecifier)
+	VisitImportNamespaceSpecifier(n *ImportNamespaceSpecifier)
+	VisitImportSpecifier(n *ImportSpecifier)
+	VisitImportSpecifiers(n *ImportSpecifiers)
 	VisitInvalidExpression(n *InvalidExpression)
+	VisitJSXAttribute(n *JSXAttribute)
+	VisitJSXAttributeItem(n *JSXAttributeItem)
+	VisitJSXAttributes(n *JSXAttributes)
+	VisitJSXChildItem(n *JSXChildItem)
+	VisitJSXChildren(n *JSXChildren)
+	VisitJSXClosingElement(n *JSXClosingElement)
+	VisitJSXElement(n *JSXElement)
+	VisitJSXExpressionContainer(n *JSXExpressionContainer)
+	VisitJSXFragment(n *JSXFragment)
+	VisitJSXName(n *JSXName)
+	VisitJSXOpeningElement(n *JSXOpeningElement)
+	VisitJSXSpreadAttribute(n *JSXSpreadAttribute)
+	VisitJSXText(n *JSXText)
 	VisitLabelledStatement(n *LabelledStatement)
 	VisitMemberExpression(n *MemberExpression)
 	VisitMemberProperty(n *MemberProperty)
 	VisitMetaProperty(n *MetaProperty)
 	VisitMethodDefinition(n *MethodDefinition)
+	VisitModuleExportName(n *ModuleExportName)
 	VisitNewExpression(n *NewExpression)
 	VisitNullLiteral(n *NullLiteral)
 	VisitNumberLiteral(n *NumberLiteral)
@@ -109,6 +141,9 @@ func (nv *NoopVisitor) VisitAwaitExpression(n *AwaitExpression) {
 func (nv *NoopVisitor) VisitBadStatement(n *BadStatement) {
 	n.VisitChildrenWith(nv.V)
 }
+func (nv *NoopVisitor) VisitBigIntLiteral(n *BigIntLiteral) {
+	n.VisitChildrenWith(nv.V)
+}
 func (nv *NoopVisitor) VisitBinaryExpression(n *BinaryExpression) {
 	n.VisitChildrenWith(nv.V)
 }
@@ -136,6 +171,9 @@ func (nv *NoopVisitor) VisitCaseStatements(n *CaseStatements) {
 func (nv *NoopVisitor) VisitCatchStatement(n *CatchStatement) {
 	n.VisitChildrenWith(nv.V)
 }
+func (nv *NoopVisitor) VisitClassAccessorProperty(n *ClassAccessorProperty) {
+	n.VisitChildrenWith(nv.V)
+}
 func (nv *NoopVisitor) VisitClassDeclaration(n *ClassDeclaration) {
 	n.VisitChildrenWith(nv.V)
 }
@@ -166,12 +204,39 @@ func (nv *NoopVisitor) VisitContinueStatement(n *ContinueStatement) {
 func (nv *NoopVisitor) VisitDebuggerStatement(n *DebuggerStatement) {
 	n.VisitChildrenWith(nv.V)
 }
+func (nv *NoopVisitor) VisitDecorator(n *Decorator) {
+	n.VisitChildrenWith(nv.V)
+}
+func (nv *NoopVisitor) VisitDecorators(n *Decorators) {
+	n.VisitChildrenWith(nv.V)
+}
+func (nv *NoopVisitor) VisitDirective(n *Directive) {
+	n.VisitChildrenWith(nv.V)
+}
+func (nv *NoopVisitor) VisitDirectives(n *Directives) {
+	n.VisitChildrenWith(nv.V)
+}
 func (nv *NoopVisitor) VisitDoWhileStatement(n *DoWhileStatement) {
 	n.VisitChildrenWith(nv.V)
 }
 func (nv *NoopVisitor) VisitEmptyStatement(n *EmptyStatement) {
 	n.VisitChildrenWith(nv.V)
 }
+func (nv *NoopVisitor) VisitExportAllDeclaration(n *ExportAllDeclaration) {
+	n.VisitChildrenWith(nv.V)
+}
+func (nv *NoopVisitor) VisitExportDefaultDeclaration(n *ExportDefaultDeclaration) {
+	n.VisitChildrenWith(nv.V)
+}
+func (nv *NoopVisitor) VisitExportNamedDeclaration(n *ExportNamedDeclaration) {
+	n.VisitChildrenWith(nv.V)
+}
+func (nv *NoopVisitor) VisitExportSpecifier(n *ExportSpecifier) {
+	n.VisitChildrenWith(nv.V)
+}
+func (nv *NoopVisitor) VisitExportSpecifiers(n *ExportSpecifiers) {
+	n.VisitChildrenWith(nv.V)
+}
 func (nv *NoopVisitor) VisitExpression(n *Expression) {
 	n.VisitChildrenWith(nv.V)
 }
@@ -211,9 +276,69 @@ func (nv *NoopVisitor) VisitIdentifier(n *Identifier) {
 func (nv *NoopVisitor) VisitIfStatement(n *IfStatement) {
 	n.VisitChildrenWith(nv.V)
 }
+func (nv *NoopVisitor) VisitImportDeclaration(n *ImportDeclaration) {
+	n.VisitChildrenWith(nv.V)
+}
+func (nv *NoopVisitor) VisitImportDefaultSpecifier(n *ImportDefaultSpecifier) {
+	n.VisitChildrenWith(nv.V)
+}
+func (nv *NoopVisitor) VisitImportExpression(n *ImportExpression) {
+	n.VisitChildrenWith(nv.V)
+}
+func (nv *NoopVisitor) VisitImportNamedSpecifier(n *ImportNamedSpecifier) {
+	n.VisitChildrenWith(nv.V)
+}
+func (nv *NoopVisitor) VisitImportNamespaceSpecifier(n *ImportNamespaceSpecifier) {
+	n.VisitChildrenWith(nv.V)
+}
+func (nv *NoopVisitor) VisitImportSpecifier(n *ImportSpecifier) {
+	n.VisitChildrenWith(nv.V)
+}
+func (nv *NoopVisitor) VisitImportSpecifiers(n *ImportSpecifiers) {
+	n.VisitChildrenWith(nv.V)
+}
 func (nv *NoopVisitor) VisitInvalidExpression(n *InvalidExpression) {
 	n.VisitChildrenWith(nv.V)
 }
+func (nv *NoopVisitor) VisitJSXAttribute(n *JSXAttribute) {
+	n.VisitChildrenWith(nv.V)
+}
+func (nv *NoopVisitor) VisitJSXAttributeItem(n *JSXAttributeItem) {
+	n.VisitChildrenWith(nv.V)
+}
+func (nv *NoopVisitor) VisitJSXAttributes(n *JSXAttributes) {
+	n.VisitChildrenWith(nv.V)
+}
+func (nv *NoopVisitor) VisitJSXChildItem(n *JSXChildItem) {
+	n.VisitChildrenWith(nv.V)
+}
+func (nv *NoopVisitor) VisitJSXChildren(n *JSXChildren) {
+	n.VisitChildrenWith(nv.V)
+}
+func (nv *NoopVisitor) VisitJSXClosingElement(n *JSXClosingElement) {
+	n.VisitChildrenWith(nv.V)
+}
+func (nv *NoopVisitor) VisitJSXElement(n *JSXElement) {
+	n.VisitChildrenWith(nv.V)
+}
+func (nv *NoopVisitor) VisitJSXExpressionContainer(n *JSXExpressionContainer) {
+	n.VisitChildrenWith(nv.V)
+}
+func (nv *NoopVisitor) VisitJSXFragment(n *JSXFragment) {
+	n.VisitChildrenWith(nv.V)
+}
+func (nv *NoopVisitor) VisitJSXName(n *JSXName) {
+	n.VisitChildrenWith(nv.V)
+}
+func (nv *NoopVisitor) VisitJSXOpeningElement(n *JSXOpeningElement) {
+	n.VisitChildrenWith(nv.V)
+}
+func (nv *NoopVisitor) VisitJSXSpreadAttribute(n *JSXSpreadAttribute) {
+	n.VisitChildrenWith(nv.V)
+}
+func (nv *NoopVisitor) VisitJSXText(n *JSXText) {
+	n.VisitChildrenWith(nv.V)
+}
 func (nv *NoopVisitor) VisitLabelledStatement(n *LabelledStatement) {
 	n.VisitChildrenWith(nv.V)
 }
@@ -229,6 +354,9 @@ func (nv *NoopVisitor) VisitMetaProperty(n *MetaProperty) {
 func (nv *NoopVisitor) VisitMethodDefinition(n *MethodDefinition) {
 	n.VisitChildrenWith(nv.V)
 }
+func (nv *NoopVisitor) VisitModuleExportName(n *ModuleExportName) {
+	n.VisitChildrenWith(nv.V)
+}
 func (nv *NoopVisitor) VisitNewExpression(n *NewExpression) {
 	n.VisitChildrenWith(nv.V)
 }
@@ -381,6 +509,11 @@ func (n *BadStatement) VisitWith(v Visitor) {
 }
 func (n *BadStatement) VisitChildrenWith(v Visitor) {
 }
+func (n *BigIntLiteral) VisitWith(v Visitor) {
+	v.VisitBigIntLiteral(n)
+}
+func (n *BigIntLiteral) VisitChildrenWith(v Visitor) {
+}
 func (n *BinaryExpression) VisitWith(v Visitor) {
 	v.VisitBinaryExpression(n)
 }
@@ -398,6 +531,7 @@ func (n *BlockStatement) VisitWith(v Visitor) {
 	v.VisitBlockStatement(n)
 }
 func (n *BlockStatement) VisitChildrenWith(v Visitor) {
+	n.Directives.VisitWith(v)
 	n.List.VisitWith(v)
 }
 func (n *BooleanLiteral) VisitWith(v Visitor) {
@@ -446,6 +580,16 @@ func (n *CatchStatement) VisitChildrenWith(v Visitor) {
 	}
 	n.Body.VisitWith(v)
 }
+func (n *ClassAccessorProperty) VisitWith(v Visitor) {
+	v.VisitClassAccessorProperty(n)
+}
+func (n *ClassAccessorProperty) VisitChildrenWith(v Visitor) {
+	n.Decorators.VisitWith(v)
+	n.Key.VisitWith(v)
+	if n.Initializer != nil {
+		n.Initializer.VisitWith(v)
+	}
+}
 func (n *ClassDeclaration) VisitWith(v Visitor) {
 	v.VisitClassDeclaration(n)
 }
@@ -470,6 +614,7 @@ func (n *ClassLiteral) VisitWith(v Visitor) {
 	v.VisitClassLiteral(n)
 }
 func (n *ClassLiteral) VisitChildrenWith(v Visitor) {
+	n.Decorators.VisitWith(v)
 	if n.Name != nil {
 		n.Name.VisitWith(v)
 	}
@@ -517,6 +662,33 @@ func (n *DebuggerStatement) VisitWith(v Visitor) {
 }
 func (n *DebuggerStatement) VisitChildrenWith(v Visitor) {
 }
+func (n *Decorator) VisitWith(v Visitor) {
+	v.VisitDecorator(n)
+}
+func (n *Decorator) VisitChildrenWith(v Visitor) {
+	n.Expression.VisitWith(v)
+}
+func (n *Decorators) VisitWith(v Visitor) {
+	v.VisitDecorators(n)
+}
+func (n *Decorators) VisitChildrenWith(v Visitor) {
+	for i := 0; i < len(*n); i++ {
+		(*n)[i].VisitWith(v)
+	}
+}
+func (n *Directive) VisitWith(v Visitor) {
+	v.VisitDirective(n)
+}
+func (n *Directive) VisitChildrenWith(v Visitor) {
+}
+func (n *Directives) VisitWith(v Visitor) {
+	v.VisitDirectives(n)
+}
+func (n *Directives) VisitChildrenWith(v Visitor) {
+	for i := 0; i < len(*n); i++ {
+		(*n)[i].VisitWith(v)
+	}
+}
 func (n *DoWhileStatement) VisitWith(v Visitor) {
 	v.VisitDoWhileStatement(n)
 }
@@ -529,6 +701,53 @@ func (n *EmptyStatement) VisitWith(v Visitor) {
 }
 func (n *EmptyStatement) VisitChildrenWith(v Visitor) {
 }
+func (n *ExportAllDeclaration) VisitWith(v Visitor) {
+	v.VisitExportAllDeclaration(n)
+}
+func (n *ExportAllDeclaration) VisitChildrenWith(v Visitor) {
+	if n.Exported != nil {
+		n.Exported.VisitWith(v)
+	}
+	n.Source.VisitWith(v)
+}
+func (n *ExportDefaultDeclaration) VisitWith(v Visitor) {
+	v.VisitExportDefaultDeclaration(n)
+}
+func (n *ExportDefaultDeclaration) VisitChildrenWith(v Visitor) {
+	if n.Declaration != nil {
+		n.Declaration.VisitWith(v)
+	}
+	if n.Expression != nil {
+		n.Expression.VisitWith(v)
+	}
+}
+func (n *ExportNamedDeclaration) VisitWith(v Visitor) {
+	v.VisitExportNamedDeclaration(n)
+}
+func (n *ExportNamedDeclaration) VisitChildrenWith(v Visitor) {
+	if n.Declaration != nil {
+		n.Declaration.VisitWith(v)
+	}
+	n.Specifiers.VisitWith(v)
+	if n.Source != nil {
+		n.Source.VisitWith(v)
+	}
+}
+func (n *ExportSpecifier) VisitWith(v Visitor) {
+	v.VisitExportSpecifier(n)
+}
+func (n *ExportSpecifier) VisitChildrenWith(v Visitor) {
+	n.Local.VisitWith(v)
+	n.Exported.VisitWith(v)
+}
+func (n *ExportSpecifiers) VisitWith(v Visitor) {
+	v.VisitExportSpecifiers(n)
+}
+func (n *ExportSpecifiers) VisitChildrenWith(v Visitor) {
+	for i := 0; i < len(*n); i++ {
+		(*n)[i].VisitWith(v)
+	}
+}
 func (n *Expression) VisitWith(v Visitor) {
 	v.VisitExpression(n)
 }
@@ -555,6 +774,7 @@ func (n *FieldDefinition) VisitWith(v Visitor) {
 	v.VisitFieldDefinition(n)
 }
 func (n *FieldDefinition) VisitChildrenWith(v Visitor) {
+	n.Decorators.VisitWith(v)
 	n.Key.VisitWith(v)
 	if n.Initializer != nil {
 		n.Initializer.VisitWith(v)
@@ -630,11 +850,150 @@ func (n *IfStatement) VisitChildrenWith(v Visitor) {
 		n.Alternate.VisitWith(v)
 	}
 }
+func (n *ImportDeclaration) VisitWith(v Visitor) {
+	v.VisitImportDeclaration(n)
+}
+func (n *ImportDeclaration) VisitChildrenWith(v Visitor) {
+	n.Specifiers.VisitWith(v)
+	n.Source.VisitWith(v)
+}
+func (n *ImportDefaultSpecifier) VisitWith(v Visitor) {
+	v.VisitImportDefaultSpecifier(n)
+}
+func (n *ImportDefaultSpecifier) VisitChildrenWith(v Visitor) {
+	n.Local.VisitWith(v)
+}
+func (n *ImportExpression) VisitWith(v Visitor) {
+	v.VisitImportExpression(n)
+}
+func (n *ImportExpression) VisitChildrenWith(v Visitor) {
+	n.Source.VisitWith(v)
+	if n.Options != nil {
+		n.Options.VisitWith(v)
+	}
+}
+func (n *ImportNamedSpecifier) VisitWith(v Visitor) {
+	v.VisitImportNamedSpecifier(n)
+}
+func (n *ImportNamedSpecifier) VisitChildrenWith(v Visitor) {
+	n.Imported.VisitWith(v)
+	n.Local.VisitWith(v)
+}
+func (n *ImportNamespaceSpecifier) VisitWith(v Visitor) {
+	v.VisitImportNamespaceSpecifier(n)
+}
+func (n *ImportNamespaceSpecifier) VisitChildrenWith(v Visitor) {
+	n.Local.VisitWith(v)
+}
+func (n *ImportSpecifier) VisitWith(v Visitor) {
+	v.VisitImportSpecifier(n)
+}
+func (n *ImportSpecifier) VisitChildrenWith(v Visitor) {
+	n.Specifier.VisitWith(v)
+}
+func (n *ImportSpecifiers) VisitWith(v Visitor) {
+	v.VisitImportSpecifiers(n)
+}
+func (n *ImportSpecifiers) VisitChildrenWith(v Visitor) {
+	for i := 0; i < len(*n); i++ {
+		(*n)[i].VisitWith(v)
+	}
+}
 func (n *InvalidExpression) VisitWith(v Visitor) {
 	v.VisitInvalidExpression(n)
 }
 func (n *InvalidExpression) VisitChildrenWith(v Visitor) {
 }
+func (n *JSXAttribute) VisitWith(v Visitor) {
+	v.VisitJSXAttribute(n)
+}
+func (n *JSXAttribute) VisitChildrenWith(v Visitor) {
+	n.Name.VisitWith(v)
+	if n.Value != nil {
+		n.Value.VisitWith(v)
+	}
+}
+func (n *JSXAttributeItem) VisitWith(v Visitor) {
+	v.VisitJSXAttributeItem(n)
+}
+func (n *JSXAttributeItem) VisitChildrenWith(v Visitor) {
+	n.Attribute.VisitWith(v)
+}
+func (n *JSXAttributes) VisitWith(v Visitor) {
+	v.VisitJSXAttributes(n)
+}
+func (n *JSXAttributes) VisitChildrenWith(v Visitor) {
+	for i := 0; i < len(*n); i++ {
+		(*n)[i].VisitWith(v)
+	}
+}
+func (n *JSXChildItem) VisitWith(v Visitor) {
+	v.VisitJSXChildItem(n)
+}
+func (n *JSXChildItem) VisitChildrenWith(v Visitor) {
+	n.Child.VisitWith(v)
+}
+func (n *JSXChildren) VisitWith(v Visitor) {
+	v.VisitJSXChildren(n)
+}
+func (n *JSXChildren) VisitChildrenWith(v Visitor) {
+	for i := 0; i < len(*n); i++ {
+		(*n)[i].VisitWith(v)
+	}
+}
+func (n *JSXClosingElement) VisitWith(v Visitor) {
+	v.VisitJSXClosingElement(n)
+}
+func (n *JSXClosingElement) VisitChildrenWith(v Visitor) {
+	n.Name.VisitWith(v)
+}
+func (n *JSXElement) VisitWith(v Visitor) {
+	v.VisitJSXElement(n)
+}
+func (n *JSXElement) VisitChildrenWith(v Visitor) {
+	n.Opening.VisitWith(v)
+	n.Children.VisitWith(v)
+	if n.Closing != nil {
+		n.Closing.VisitWith(v)
+	}
+}
+func (n *JSXExpressionContainer) VisitWith(v Visitor) {
+	v.VisitJSXExpressionContainer(n)
+}
+func (n *JSXExpressionContainer) VisitChildrenWith(v Visitor) {
+	if n.Expression != nil {
+		n.Expression.VisitWith(v)
+	}
+}
+func (n *JSXFragment) VisitWith(v Visitor) {
+	v.VisitJSXFragment(n)
+}
+func (n *JSXFragment) VisitChildrenWith(v Visitor) {
+	n.Children.VisitWith(v)
+}
+func (n *JSXName) VisitWith(v Visitor) {
+	v.VisitJSXName(n)
+}
+func (n *JSXName) VisitChildrenWith(v Visitor) {
+}
+func (n *JSXOpeningElement) VisitWith(v Visitor) {
+	v.VisitJSXOpeningElement(n)
+}
+func (n *JSXOpeningElement) VisitChildrenWith(v Visitor) {
+	n.Name.VisitWith(v)
+	n.Attributes.VisitWith(v)
+}
+func (n *JSXSpreadAttribute) VisitWith(v Visitor) {
+	v.VisitJSXSpreadAttribute(n)
+}
+func (n *JSXSpreadAttribute) VisitChildrenWith(v Visitor) {
+	n.Argument.VisitWith(v)
+}
+func (n *JSXText) VisitWith(v Visitor) {
+	v.VisitJSXText(n)
+}
+func (n *JSXText) VisitChildrenWith(v Visitor) {
+}
 func (n *LabelledStatement) VisitWith(v Visitor) {
 	v.VisitLabelledStatement(n)
 }
@@ -665,9 +1024,16 @@ func (n *MethodDefinition) VisitWith(v Visitor) {
 	v.VisitMethodDefinition(n)
 }
 func (n *MethodDefinition) VisitChildrenWith(v Visitor) {
+	n.Decorators.VisitWith(v)
 	n.Key.VisitWith(v)
 	n.Body.VisitWith(v)
 }
+func (n *ModuleExportName) VisitWith(v Visitor) {
+	v.VisitModuleExportName(n)
+}
+func (n *ModuleExportName) VisitChildrenWith(v Visitor) {
+	n.Name.VisitWith(v)
+}
 func (n *NewExpression) VisitWith(v Visitor) {
 	v.VisitNewExpression(n)
 }
@@ -738,6 +1104,7 @@ func (n *Program) VisitWith(v Visitor) {
 	v.VisitProgram(n)
 }
 func (n *Program) VisitChildrenWith(v Visitor) {
+	n.Directives.VisitWith(v)
 	n.Body.VisitWith(v)
 }
 func (n *Properties) VisitWith(v Visitor) {
@@ -766,7 +1133,9 @@ func (n *PropertyShort) VisitWith(v Visitor) {
 }
 func (n *PropertyShort) VisitChildrenWith(v Visitor) {
 	n.Name.VisitWith(v)
-	n.Initializer.VisitWith(v)
+	if n.Initializer != nil {
+		n.Initializer.VisitWith(v)
+	}
 }
 func (n *RegExpLiteral) VisitWith(v Visitor) {
 	v.VisitRegExpLiteral(n)
@@ -925,5 +1294,7 @@ func (n *YieldExpression) VisitWith(v Visitor) {
 	v.VisitYieldExpression(n)
 }
 func (n *YieldExpression) VisitChildrenWith(v Visitor) {
-	n.Argument.VisitWith(v)
+	if n.Argument != nil {
+		n.Argument.VisitWith(v)
+	}
 }
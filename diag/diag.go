@@ -0,0 +1,128 @@
+// Package diag reports the errors of a parse and the warnings of transforms
+// against the source: rendered with line numbers and underlines for people
+// on a terminal, and as JSON or SARIF for CI systems that annotate files.
+package diag
+
+import (
+	"errors"
+
+	"github.com/t14raptor/go-fast/ast"
+	"github.com/t14raptor/go-fast/parser"
+)
+
+// Severity is how serious a Diagnostic is.
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityNote
+)
+
+var severity2string = [...]string{
+	SeverityError:   "error",
+	SeverityWarning: "warning",
+	SeverityNote:    "note",
+}
+
+func (s Severity) String() string {
+	if s >= 0 && int(s) < len(severity2string) {
+		return severity2string[s]
+	}
+	return "error"
+}
+
+// Diagnostic is an error or warning about a part of the source.
+type Diagnostic struct {
+	Severity Severity
+	// Code is a stable name of the kind of diagnostic, like the String of a
+	// parser.Code. It may be empty.
+	Code    string
+	Message string
+	// Idx is where the diagnostic starts, and Length how many bytes of the
+	// source it spans. An Idx of 0 means the diagnostic has no position.
+	Idx    ast.Idx
+	Length int
+	// Hint suggests how to fix the problem.
+	Hint string
+	// Related lists other parts of the source that explain the diagnostic.
+	Related []Span
+}
+
+// Span is a part of the source with a message about it.
+type Span struct {
+	Idx     ast.Idx
+	Length  int
+	Message string
+}
+
+// Reporter receives the diagnostics of a transform.
+type Reporter interface {
+	Report(d Diagnostic)
+}
+
+// List is a Reporter that collects diagnostics in the order they are reported.
+type List []Diagnostic
+
+func (l *List) Report(d Diagnostic) {
+	*l = append(*l, d)
+}
+
+// HasErrors reports whether the list holds a diagnostic of SeverityError.
+func (l List) HasErrors() bool {
+	for _, d := range l {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// FromError returns the diagnostics of an error returned by the parser. Each
+// syntax error of a parser.ErrorList becomes a diagnostic with its position,
+// code, hint and related span. Other errors, like those of exceeded limits,
+// become a diagnostic without a position. A nil error has no diagnostics.
+func FromError(err error) []Diagnostic {
+	if err == nil {
+		return nil
+	}
+	var list *parser.ErrorList
+	if errors.As(err, &list) {
+		diags := make([]Diagnostic, len(*list))
+		for i, e := range *list {
+			diags[i] = fromSyntaxError(e)
+		}
+		return diags
+	}
+	var e *parser.SyntaxError
+	if errors.As(err, &e) {
+		return []Diagnostic{fromSyntaxError(e)}
+	}
+	return []Diagnostic{{Severity: SeverityError, Message: err.Error()}}
+}
+
+func fromSyntaxError(e *parser.SyntaxError) Diagnostic {
+	d := Diagnostic{
+		Severity: SeverityError,
+		Message:  e.Message,
+		Idx:      ast.Idx(e.Offset + 1),
+		Length:   e.Length,
+		Hint:     e.Hint,
+	}
+	if e.Code != parser.CodeUnknown {
+		d.Code = e.Code.String()
+	}
+	if r := e.Related; r != nil {
+		d.Related = []Span{{Idx: ast.Idx(r.Offset + 1), Length: r.Length, Message: r.Message}}
+	}
+	return d
+}
+
+// File is a source file together with its diagnostics.
+type File struct {
+	// Name is the path of the file, shown in rendered diagnostics and used
+	// as the URI of the file in SARIF.
+	Name        string
+	Source      *ast.SourceFile
+	Diagnostics []Diagnostic
+}
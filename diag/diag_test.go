@@ -0,0 +1,177 @@
+package diag_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/t14raptor/go-fast/ast"
+	"github.com/t14raptor/go-fast/diag"
+	"github.com/t14raptor/go-fast/parser"
+)
+
+func parseErrors(t *testing.T, src string) diag.File {
+	t.Helper()
+	_, err := parser.ParseFile(src)
+	if err == nil {
+		t.Fatalf("Expected error for %q", src)
+	}
+	return diag.File{Name: "main.js", Source: ast.NewSourceFile(src), Diagnostics: diag.FromError(err)}
+}
+
+func TestRender(t *testing.T) {
+	for _, tt := range []struct {
+		src  string
+		opts diag.Options
+		want string
+	}{
+		{"if (a) {\n  b()\n", diag.Options{Context: 1}, `error[UnexpectedEnd]: Unexpected end of input
+ --> main.js:3:1
+  |
+1 | if (a) {
+  |        - The '{' opened here
+2 |   b()
+3 |
+  | ^
+  = hint: Add the missing '}'
+`},
+		{"a;\nb;\n\tbreak foo;\nc;\nd;\n", diag.Options{Context: 1}, `error[UndefinedLabel]: Undefined label 'foo'
+ --> main.js:3:8
+  |
+2 | b;
+3 | 	break foo;
+  | 	      ^^^
+4 | c;
+`},
+		{"x = 'ü' +;", diag.Options{}, `error[UnexpectedToken]: Unexpected token ;
+ --> main.js:1:11
+  |
+1 | x = 'ü' +;
+  |          ^
+`},
+		{"switch (a) {\n  default:\n\n\n\n  default:\n}", diag.Options{}, `error[DuplicateDefault]: Already saw a default in switch
+ --> main.js:6:3
+  |
+2 |   default:
+  |   ------- The first default clause
+...
+6 |   default:
+  |   ^^^^^^^
+`},
+	} {
+		var b bytes.Buffer
+		if err := diag.Render(&b, parseErrors(t, tt.src), tt.opts); err != nil {
+			t.Fatal(err)
+		}
+		if got := b.String(); got != tt.want {
+			t.Errorf("Render of %q =\n%s\nwant\n%s", tt.src, got, tt.want)
+		}
+	}
+
+	var b bytes.Buffer
+	f := parseErrors(t, "a b")
+	if err := diag.Render(&b, f, diag.Options{Color: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); !strings.Contains(got, "\x1b[1;31merror[UnexpectedIdentifier]\x1b[0m") || !strings.Contains(got, "\x1b[1;31m^\x1b[0m") {
+		t.Errorf("Expected coloured output, got %q", got)
+	}
+
+	b.Reset()
+	f = diag.File{Diagnostics: diag.FromError(errors.New("maximum depth of 10 exceeded"))}
+	if err := diag.Render(&b, f, diag.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := b.String(), "error: maximum depth of 10 exceeded\n"; got != want {
+		t.Errorf("Render = %q; want %q", got, want)
+	}
+}
+
+func TestList(t *testing.T) {
+	var list diag.List
+	var r diag.Reporter = &list
+	r.Report(diag.Diagnostic{Severity: diag.SeverityWarning, Message: "a"})
+	if len(list) != 1 || list.HasErrors() {
+		t.Errorf("Unexpected list %+v", list)
+	}
+	r.Report(diag.Diagnostic{Message: "b"})
+	if !list.HasErrors() {
+		t.Errorf("Expected errors in %+v", list)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var b bytes.Buffer
+	if err := diag.WriteJSON(&b, parseErrors(t, "// ü\nf(1, 2")); err != nil {
+		t.Fatal(err)
+	}
+	var got []map[string]any
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"file": "main.js", "severity": "error", "code": "UnexpectedEnd", "message": "Unexpected end of input",
+		"line": 2.0, "column": 7.0, "offset": 12.0, "length": 0.0, "hint": "Add the missing ')'",
+	}
+	if len(got) != 1 {
+		t.Fatalf("Expected 1 diagnostic, got %s", b.String())
+	}
+	for k, v := range want {
+		if got[0][k] != v {
+			t.Errorf("Expected %s to be %v, got %v", k, v, got[0][k])
+		}
+	}
+	related, _ := got[0]["related"].([]any)
+	if len(related) != 1 || related[0].(map[string]any)["column"] != 2.0 {
+		t.Errorf("Unexpected related spans %v", got[0]["related"])
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var b bytes.Buffer
+	if err := diag.WriteSARIF(&b, parseErrors(t, "'😀'; a b")); err != nil {
+		t.Fatal(err)
+	}
+	var log struct {
+		Version string
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string
+					Rules []struct{ ID string }
+				}
+			}
+			Results []struct {
+				RuleID    string
+				Level     string
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct{ URI string }
+						Region           struct{ StartLine, StartColumn, EndLine, EndColumn int }
+					}
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(b.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Results) != 1 {
+		t.Fatalf("Unexpected log %s", b.String())
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "go-fast" || len(run.Tool.Driver.Rules) != 1 || run.Tool.Driver.Rules[0].ID != "UnexpectedIdentifier" {
+		t.Errorf("Unexpected tool %+v", run.Tool)
+	}
+	res := run.Results[0]
+	if res.RuleID != "UnexpectedIdentifier" || res.Level != "error" || len(res.Locations) != 1 {
+		t.Fatalf("Unexpected result %+v", res)
+	}
+	// Columns count the emoji as two UTF-16 code units.
+	loc := res.Locations[0].PhysicalLocation
+	if loc.ArtifactLocation.URI != "main.js" || loc.Region.StartLine != 1 || loc.Region.StartColumn != 9 || loc.Region.EndColumn != 10 {
+		t.Errorf("Unexpected location %+v", loc)
+	}
+}
@@ -0,0 +1,193 @@
+package diag
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/t14raptor/go-fast/ast"
+)
+
+// Options configures Render.
+type Options struct {
+	// Context is the number of lines shown before and after each line that
+	// is underlined. Zero shows just the underlined lines.
+	Context int
+	// Color highlights the output with ANSI escape codes.
+	Color bool
+}
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[1;31m"
+	ansiYellow = "\x1b[1;33m"
+	ansiCyan   = "\x1b[1;36m"
+	ansiBlue   = "\x1b[1;34m"
+)
+
+var severity2color = [...]string{
+	SeverityError:   ansiRed,
+	SeverityWarning: ansiYellow,
+	SeverityNote:    ansiCyan,
+}
+
+// Render writes the diagnostics of f to w in a form meant for people:
+//
+//	error[UnexpectedEnd]: Unexpected end of input
+//	 --> main.js:3:1
+//	  |
+//	1 | if (a) {
+//	  |        - The '{' opened here
+//	2 |   b()
+//	3 |
+//	  | ^
+//	  = hint: Add the missing '}'
+//
+// Lines count from 1 and columns from 1 in bytes, as in a parser.SyntaxError.
+// The lines and underlines are left out if f has no Source.
+func Render(w io.Writer, f File, opts Options) error {
+	r := &renderer{f: f, opts: opts}
+	for i, d := range f.Diagnostics {
+		if i > 0 {
+			r.b.WriteByte('\n')
+		}
+		r.diagnostic(d)
+	}
+	_, err := io.WriteString(w, r.b.String())
+	return err
+}
+
+type renderer struct {
+	b    strings.Builder
+	f    File
+	opts Options
+}
+
+// mark is an underlined part of a line.
+type mark struct {
+	line    int
+	offset  int
+	length  int
+	primary bool
+	message string
+}
+
+func (r *renderer) paint(style, s string) string {
+	if !r.opts.Color {
+		return s
+	}
+	return style + s + ansiReset
+}
+
+func (r *renderer) diagnostic(d Diagnostic) {
+	color := ansiRed
+	if d.Severity >= 0 && int(d.Severity) < len(severity2color) {
+		color = severity2color[d.Severity]
+	}
+	title := d.Severity.String()
+	if d.Code != "" {
+		title += "[" + d.Code + "]"
+	}
+	r.b.WriteString(r.paint(color, title) + r.paint(ansiBold, ": "+d.Message) + "\n")
+
+	source := r.f.Source
+	if d.Idx == 0 || source == nil {
+		if d.Hint != "" {
+			r.b.WriteString("  = " + r.paint(ansiBold, "hint") + ": " + d.Hint + "\n")
+		}
+		return
+	}
+
+	marks := []mark{r.mark(d.Idx, d.Length, true, "")}
+	for _, s := range d.Related {
+		marks = append(marks, r.mark(s.Idx, s.Length, false, s.Message))
+	}
+	var lines []int
+	for _, m := range marks {
+		for n := max(m.line-r.opts.Context, 1); n <= min(m.line+r.opts.Context, source.LineCount()); n++ {
+			lines = append(lines, n)
+		}
+	}
+	slices.Sort(lines)
+	lines = slices.Compact(lines)
+	width := len(strconv.Itoa(lines[len(lines)-1]))
+	gutter := strings.Repeat(" ", width) + " " + r.paint(ansiBlue, "|")
+
+	pos := source.Position(d.Idx, ast.ColumnBytes)
+	location := fmt.Sprintf("%d:%d", pos.Line, pos.Column+1)
+	if r.f.Name != "" {
+		location = r.f.Name + ":" + location
+	}
+	r.b.WriteString(strings.Repeat(" ", width) + r.paint(ansiBlue, "-->") + " " + location + "\n")
+	r.b.WriteString(gutter + "\n")
+
+	for i, n := range lines {
+		if i > 0 && n > lines[i-1]+1 {
+			r.b.WriteString(r.paint(ansiBlue, "...") + "\n")
+		}
+		start, text := r.line(n)
+		r.b.WriteString(r.paint(ansiBlue, fmt.Sprintf("%*d |", width, n)))
+		if text != "" {
+			r.b.WriteString(" " + text)
+		}
+		r.b.WriteByte('\n')
+		for _, m := range marks {
+			if m.line == n {
+				r.b.WriteString(gutter + " " + r.underline(text, m.offset-start, m, color) + "\n")
+			}
+		}
+	}
+	if d.Hint != "" {
+		r.b.WriteString(strings.Repeat(" ", width) + " = " + r.paint(ansiBold, "hint") + ": " + d.Hint + "\n")
+	}
+}
+
+func (r *renderer) mark(idx ast.Idx, length int, primary bool, message string) mark {
+	return mark{
+		line:    r.f.Source.Position(idx, ast.ColumnBytes).Line,
+		offset:  r.f.Source.Offset(idx),
+		length:  length,
+		primary: primary,
+		message: message,
+	}
+}
+
+// line returns the offset and the text of line n without its terminator.
+func (r *renderer) line(n int) (int, string) {
+	source := r.f.Source
+	text := source.Text()
+	start := source.Offset(source.Idx(ast.Position{Line: n}, ast.ColumnBytes))
+	end := source.Offset(source.Idx(ast.Position{Line: n, Column: len(text)}, ast.ColumnBytes))
+	return start, text[start:end]
+}
+
+// underline returns the underline of m, which starts at column of text. It
+// is indented with the tabs of text so that it lines up with the text.
+func (r *renderer) underline(text string, column int, m mark, color string) string {
+	column = min(column, len(text))
+	var b strings.Builder
+	for _, c := range text[:column] {
+		if c == '\t' {
+			b.WriteByte('\t')
+		} else {
+			b.WriteByte(' ')
+		}
+	}
+	// Only the first line of a span spanning several lines is underlined.
+	n := max(utf8.RuneCountInString(text[column:min(column+m.length, len(text))]), 1)
+	char := "-"
+	if m.primary {
+		char = "^"
+	} else {
+		color = ansiBlue
+	}
+	line := strings.Repeat(char, n)
+	if m.message != "" {
+		line += " " + m.message
+	}
+	return b.String() + r.paint(color, line)
+}
@@ -0,0 +1,190 @@
+package diag
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/t14raptor/go-fast/ast"
+)
+
+// jsonDiagnostic is a Diagnostic as written by WriteJSON.
+type jsonDiagnostic struct {
+	File     string `json:"file"`
+	Severity string `json:"severity"`
+	Code     string `json:"code,omitempty"`
+	Message  string `json:"message"`
+	*jsonSpan
+	Hint    string     `json:"hint,omitempty"`
+	Related []jsonSpan `json:"related,omitempty"`
+}
+
+type jsonSpan struct {
+	Message string `json:"message,omitempty"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Offset  int    `json:"offset"`
+	Length  int    `json:"length"`
+}
+
+// WriteJSON writes the diagnostics of files to w as a JSON array. Each
+// diagnostic is an object with the name of its file, its severity, code,
+// message and hint, and the position of its start and related spans, with
+// lines and columns counted from 1 and columns and offsets in bytes.
+func WriteJSON(w io.Writer, files ...File) error {
+	out := []jsonDiagnostic{}
+	for _, f := range files {
+		for _, d := range f.Diagnostics {
+			j := jsonDiagnostic{
+				File:     f.Name,
+				Severity: d.Severity.String(),
+				Code:     d.Code,
+				Message:  d.Message,
+				Hint:     d.Hint,
+			}
+			if d.Idx != 0 && f.Source != nil {
+				s := toJSONSpan(f.Source, Span{Idx: d.Idx, Length: d.Length})
+				j.jsonSpan = &s
+				for _, r := range d.Related {
+					j.Related = append(j.Related, toJSONSpan(f.Source, r))
+				}
+			}
+			out = append(out, j)
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func toJSONSpan(source *ast.SourceFile, s Span) jsonSpan {
+	pos := source.Position(s.Idx, ast.ColumnBytes)
+	return jsonSpan{
+		Message: s.Message,
+		Line:    pos.Line,
+		Column:  pos.Column + 1,
+		Offset:  source.Offset(s.Idx),
+		Length:  s.Length,
+	}
+}
+
+// The subset of SARIF 2.1.0 that WriteSARIF writes.
+type (
+	sarifLog struct {
+		Version string     `json:"version"`
+		Schema  string     `json:"$schema"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules,omitempty"`
+	}
+	sarifRule struct {
+		ID string `json:"id"`
+	}
+	sarifResult struct {
+		RuleID           string          `json:"ruleId,omitempty"`
+		Level            string          `json:"level"`
+		Message          sarifMessage    `json:"message"`
+		Locations        []sarifLocation `json:"locations,omitempty"`
+		RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+		Properties       *sarifHint      `json:"properties,omitempty"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifLocation struct {
+		ID               *int                  `json:"id,omitempty"`
+		Message          *sarifMessage         `json:"message,omitempty"`
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn"`
+		EndLine     int `json:"endLine"`
+		EndColumn   int `json:"endColumn"`
+	}
+	sarifHint struct {
+		Hint string `json:"hint"`
+	}
+)
+
+// WriteSARIF writes the diagnostics of files to w as a SARIF 2.1.0 log, which
+// CI systems such as GitHub code scanning read to annotate the files. The
+// codes of the diagnostics are the rule IDs, and columns are counted in
+// UTF-16 code units as SARIF expects.
+func WriteSARIF(w io.Writer, files ...File) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "go-fast",
+			InformationURI: "https://github.com/t14raptor/go-fast",
+		}},
+		Results: []sarifResult{},
+	}
+	rules := map[string]bool{}
+	for _, f := range files {
+		for _, d := range f.Diagnostics {
+			result := sarifResult{
+				RuleID:  d.Code,
+				Level:   d.Severity.String(),
+				Message: sarifMessage{Text: d.Message},
+			}
+			if d.Code != "" && !rules[d.Code] {
+				rules[d.Code] = true
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: d.Code})
+			}
+			if d.Idx != 0 && f.Source != nil {
+				result.Locations = []sarifLocation{sarifLocationOf(f, Span{Idx: d.Idx, Length: d.Length})}
+				for i, r := range d.Related {
+					l := sarifLocationOf(f, r)
+					l.ID = &i
+					l.Message = &sarifMessage{Text: r.Message}
+					result.RelatedLocations = append(result.RelatedLocations, l)
+				}
+			} else if f.Name != "" {
+				result.Locations = []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: f.Name},
+				}}}
+			}
+			if d.Hint != "" {
+				result.Properties = &sarifHint{Hint: d.Hint}
+			}
+			run.Results = append(run.Results, result)
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{run},
+	})
+}
+
+func sarifLocationOf(f File, s Span) sarifLocation {
+	start := f.Source.Position(s.Idx, ast.ColumnUTF16)
+	end := f.Source.Position(s.Idx+ast.Idx(s.Length), ast.ColumnUTF16)
+	return sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: f.Name},
+		Region: &sarifRegion{
+			StartLine:   start.Line,
+			StartColumn: start.Column + 1,
+			EndLine:     end.Line,
+			EndColumn:   end.Column + 1,
+		},
+	}}
+}
@@ -7,14 +7,36 @@ import (
 	"unicode"
 
 	"github.com/t14raptor/go-fast/ast"
+	"github.com/t14raptor/go-fast/limit"
 	"github.com/t14raptor/go-fast/token"
 )
 
 func Generate(node ast.VisitableNode) string {
+	code, _ := GenerateWithOptions(node, Options{})
+	return code
+}
+
+// Options configures the output of GenerateWithOptions.
+type Options struct {
+	// Limits stops the generation with one of the errors of package limit
+	// when the nodes nest deeper than MaxDepth, more than MaxNodes nodes are
+	// generated or Context is done.
+	Limits limit.Limits
+}
+
+// GenerateWithOptions is like Generate but generates according to opts. It
+// only fails when a limit of opts.Limits is exceeded.
+func GenerateWithOptions(node ast.VisitableNode, opts Options) (code string, err error) {
+	defer limit.Catch(&err)
 	g := &GenVisitor{}
 	g.V = g
+	g.limits.Start(opts.Limits)
+	if program, ok := node.(*ast.Program); ok {
+		g.comments = program.CommentMap
+	}
 	g.gen(node)
-	return g.out.String()
+	g.writePendingComments()
+	return g.out.String(), nil
 }
 
 type GenVisitor struct {
@@ -26,17 +48,40 @@ type GenVisitor struct {
 
 	p ast.VisitableNode
 	s ast.VisitableNode
+
+	comments map[ast.Node]*ast.NodeComments
+	// Trailing line comments are held back until the end of the line, so
+	// that nothing is written after them on the same line.
+	pending []*ast.Comment
+
+	limits limit.Tracker
 }
 
 func (g *GenVisitor) gen(node ast.VisitableNode) {
+	g.limits.Enter()
+	defer g.limits.Leave()
+	g.limits.Count()
 	old := g.p
 
+	if len(g.pending) > 0 {
+		g.lineAndPad()
+	}
+	c := g.nodeComments(node)
+	if c != nil {
+		g.leadingComments(node, c.Leading)
+	}
+
 	g.p, g.s = g.s, node
 	node.VisitWith(g)
 	g.s, g.p = g.p, old
+
+	if c != nil {
+		g.trailingComments(node, c.Trailing)
+	}
 }
 
 func (g *GenVisitor) line() {
+	g.writePendingComments()
 	g.out.WriteString("\n")
 }
 
@@ -47,6 +92,94 @@ func (g *GenVisitor) lineAndPad() {
 	}
 }
 
+func (g *GenVisitor) nodeComments(node ast.VisitableNode) *ast.NodeComments {
+	if g.comments == nil {
+		return nil
+	}
+	if n, ok := unwrap(node).(ast.Node); ok {
+		return g.comments[n]
+	}
+	return nil
+}
+
+// unwrap returns the node held by an expression, statement or arrow function
+// body wrapper, which is what comments are attached to.
+func unwrap(node ast.VisitableNode) ast.VisitableNode {
+	switch n := node.(type) {
+	case *ast.ConciseBody:
+		if n.Body != nil {
+			return unwrap(n.Body)
+		}
+	case *ast.Expression:
+		if n.Expr != nil {
+			return n.Expr
+		}
+	case *ast.Statement:
+		if n.Stmt != nil {
+			return n.Stmt
+		}
+	}
+	return node
+}
+
+// statementLike reports whether comments around node may take lines of their own.
+func statementLike(node ast.VisitableNode) bool {
+	switch unwrap(node).(type) {
+	case ast.Stmt, ast.Element, *ast.Directive, *ast.Program:
+		return true
+	}
+	return false
+}
+
+func (g *GenVisitor) leadingComments(node ast.VisitableNode, comments []*ast.Comment) {
+	stmt := statementLike(node)
+	for _, c := range comments {
+		switch {
+		case c.Kind == ast.CommentBlock:
+			g.out.WriteString("/*" + c.Text + "*/")
+			if stmt {
+				g.lineAndPad()
+			} else {
+				g.out.WriteString(" ")
+			}
+		case stmt:
+			g.out.WriteString("//" + c.Text)
+			g.lineAndPad()
+		case !strings.Contains(c.Text, "*/"):
+			// A line break inside of an expression could change its meaning.
+			g.out.WriteString("/*" + c.Text + "*/ ")
+		}
+	}
+}
+
+func (g *GenVisitor) trailingComments(node ast.VisitableNode, comments []*ast.Comment) {
+	if _, ok := node.(*ast.Program); ok {
+		for _, c := range comments {
+			if c.Kind == ast.CommentBlock {
+				g.out.WriteString("/*" + c.Text + "*/")
+			} else {
+				g.out.WriteString("//" + c.Text)
+			}
+			g.line()
+		}
+		return
+	}
+	for _, c := range comments {
+		if c.Kind == ast.CommentBlock {
+			g.out.WriteString(" /*" + c.Text + "*/")
+		} else {
+			g.pending = append(g.pending, c)
+		}
+	}
+}
+
+func (g *GenVisitor) writePendingComments() {
+	for _, c := range g.pending {
+		g.out.WriteString(" //" + c.Text)
+	}
+	g.pending = g.pending[:0]
+}
+
 func (g *GenVisitor) VisitArrowFunctionLiteral(n *ast.ArrowFunctionLiteral) {
 	if n.Async {
 		g.out.WriteString("async ")
@@ -108,7 +241,9 @@ func (g *GenVisitor) VisitAssignExpression(n *ast.AssignExpression) {
 func (g *GenVisitor) VisitArrayPattern(n *ast.ArrayPattern) {
 	g.out.WriteString("[")
 	for i, elem := range n.Elements {
-		g.gen(elem.Expr)
+		if elem.Expr != nil {
+			g.gen(elem.Expr)
+		}
 		if i < len(n.Elements)-1 {
 			g.out.WriteString(", ")
 		}
@@ -152,10 +287,11 @@ func (g *GenVisitor) VisitBlockStatement(n *ast.BlockStatement) {
 	g.out.WriteString("{")
 
 	g.indent++
+	g.VisitDirectives(&n.Directives)
 	g.VisitStatements(&n.List)
 	g.indent--
 
-	if len(n.List) > 0 {
+	if len(n.Directives) > 0 || len(n.List) > 0 {
 		g.lineAndPad()
 	}
 	g.out.WriteString("}")
@@ -168,6 +304,18 @@ func (g *GenVisitor) VisitStatements(n *ast.Statements) {
 	}
 }
 
+func (g *GenVisitor) VisitDirective(n *ast.Directive) {
+	g.out.WriteString(n.Raw)
+	g.out.WriteString(";")
+}
+
+func (g *GenVisitor) VisitDirectives(n *ast.Directives) {
+	for i := range *n {
+		g.lineAndPad()
+		g.gen(&(*n)[i])
+	}
+}
+
 func (g *GenVisitor) VisitBooleanLiteral(n *ast.BooleanLiteral) {
 	if n.Value {
 		g.out.WriteString("true")
@@ -276,7 +424,7 @@ func (g *GenVisitor) VisitDoWhileStatement(n *ast.DoWhileStatement) {
 func (g *GenVisitor) VisitMemberExpression(n *ast.MemberExpression) {
 	switch n.Object.Expr.(type) {
 	case *ast.AssignExpression, *ast.BinaryExpression, *ast.UnaryExpression, *ast.SequenceExpression, *ast.ConditionalExpression, *ast.NumberLiteral,
-		*ast.FunctionLiteral, *ast.ArrowFunctionLiteral, *ast.UpdateExpression:
+		*ast.BigIntLiteral, *ast.FunctionLiteral, *ast.ArrowFunctionLiteral, *ast.UpdateExpression:
 		g.out.WriteString("(")
 		g.gen(n.Object.Expr)
 		g.out.WriteString(")")
@@ -299,6 +447,16 @@ func (g *GenVisitor) VisitMemberProperty(n *ast.MemberProperty) {
 	}
 }
 
+func (g *GenVisitor) VisitPrivateDotExpression(n *ast.PrivateDotExpression) {
+	g.gen(n.Left.Expr)
+	g.out.WriteString(".")
+	g.gen(n.Identifier)
+}
+
+func (g *GenVisitor) VisitPrivateIdentifier(n *ast.PrivateIdentifier) {
+	g.out.WriteString("#" + n.Identifier.Name)
+}
+
 func (g *GenVisitor) VisitEmptyStatement(n *ast.EmptyStatement) {
 	g.out.WriteString(";")
 }
@@ -321,7 +479,11 @@ func (g *GenVisitor) VisitForInStatement(n *ast.ForInStatement) {
 }
 
 func (g *GenVisitor) VisitForOfStatement(n *ast.ForOfStatement) {
-	g.out.WriteString("for (")
+	if n.Await {
+		g.out.WriteString("for await (")
+	} else {
+		g.out.WriteString("for (")
+	}
 	g.gen(n.Into)
 	g.out.WriteString(" of ")
 	g.gen(n.Source.Expr)
@@ -474,6 +636,15 @@ func (g *GenVisitor) VisitNewExpression(n *ast.NewExpression) {
 	g.out.WriteString(")")
 }
 
+func (g *GenVisitor) VisitBigIntLiteral(n *ast.BigIntLiteral) {
+	if n.Raw != nil {
+		g.out.WriteString(*n.Raw)
+	} else {
+		g.out.WriteString(n.Value.String())
+		g.out.WriteString("n")
+	}
+}
+
 func (g *GenVisitor) VisitNullLiteral(n *ast.NullLiteral) {
 	g.out.WriteString("null")
 }
@@ -544,6 +715,10 @@ func (g *GenVisitor) VisitPropertyKeyed(n *ast.PropertyKeyed) {
 }
 
 func (g *GenVisitor) VisitProgram(n *ast.Program) {
+	for i := range n.Directives {
+		g.gen(&n.Directives[i])
+		g.line()
+	}
 	for _, b := range n.Body {
 		g.gen(b.Stmt)
 		g.line()
@@ -565,7 +740,7 @@ func (g *GenVisitor) VisitReturnStatement(n *ast.ReturnStatement) {
 
 func (g *GenVisitor) VisitSequenceExpression(n *ast.SequenceExpression) {
 	switch g.p.(type) {
-	case *ast.VariableDeclarator, *ast.PropertyKeyed, *ast.UnaryExpression, *ast.UpdateExpression, *ast.BinaryExpression, *ast.ConditionalExpression, *ast.AssignExpression, *ast.CallExpression, *ast.ArrayLiteral:
+	case *ast.VariableDeclarator, *ast.PropertyKeyed, *ast.UnaryExpression, *ast.UpdateExpression, *ast.BinaryExpression, *ast.ConditionalExpression, *ast.AssignExpression, *ast.CallExpression, *ast.ImportExpression, *ast.ArrayLiteral, *ast.ExportDefaultDeclaration:
 		g.out.WriteString("(")
 		defer g.out.WriteString(")")
 	}
@@ -733,6 +908,7 @@ func (g *GenVisitor) VisitVariableDeclaration(n *ast.VariableDeclaration) {
 }
 
 func (g *GenVisitor) VisitClassLiteral(n *ast.ClassLiteral) {
+	g.decorators(n.Decorators)
 	g.out.WriteString("class ")
 	if n.Name != nil {
 		g.gen(n.Name)
@@ -742,8 +918,13 @@ func (g *GenVisitor) VisitClassLiteral(n *ast.ClassLiteral) {
 	g.indent++
 	for _, element := range n.Body {
 		g.lineAndPad()
+		c := g.nodeComments(element.Element)
+		if c != nil {
+			g.leadingComments(element.Element, c.Leading)
+		}
 		switch e := element.Element.(type) {
 		case *ast.MethodDefinition:
+			g.decorators(e.Decorators)
 			if e.Static {
 				g.out.WriteString("static ")
 			}
@@ -752,16 +933,28 @@ func (g *GenVisitor) VisitClassLiteral(n *ast.ClassLiteral) {
 			} else if e.Kind == ast.PropertyKindSet {
 				g.out.WriteString("set ")
 			}
-			if e.Computed {
-				g.out.WriteString("[")
-				g.gen(e.Key)
-				g.out.WriteString("]")
-			} else {
-				g.gen(e.Key)
-			}
+			g.classKey(e.Key, e.Computed)
 			g.gen(&e.Body.ParameterList)
 			g.out.WriteString(" ")
 			g.gen(e.Body.Body)
+		case *ast.FieldDefinition:
+			g.decorators(e.Decorators)
+			if e.Static {
+				g.out.WriteString("static ")
+			}
+			g.classKey(e.Key, e.Computed)
+			g.initializer(e.Initializer)
+		case *ast.ClassAccessorProperty:
+			g.decorators(e.Decorators)
+			if e.Static {
+				g.out.WriteString("static ")
+			}
+			g.out.WriteString("accessor ")
+			g.classKey(e.Key, e.Computed)
+			g.initializer(e.Initializer)
+		}
+		if c != nil {
+			g.trailingComments(element.Element, c.Trailing)
 		}
 	}
 	g.indent--
@@ -770,11 +963,198 @@ func (g *GenVisitor) VisitClassLiteral(n *ast.ClassLiteral) {
 	g.out.WriteString("}")
 }
 
+func (g *GenVisitor) classKey(key *ast.Expression, computed bool) {
+	if computed {
+		g.out.WriteString("[")
+		g.gen(key)
+		g.out.WriteString("]")
+	} else {
+		g.gen(key)
+	}
+}
+
+// initializer writes the optional initializer of a field and the semicolon
+// that ends it.
+func (g *GenVisitor) initializer(init *ast.Expression) {
+	if init != nil {
+		g.out.WriteString(" = ")
+		g.gen(init.Expr)
+	}
+	g.out.WriteString(";")
+}
+
+// decorators writes decorators in front of a class or class element. Their
+// expressions are parenthesized unless they are called or uncalled chains of
+// property accesses on an identifier.
+func (g *GenVisitor) decorators(list ast.Decorators) {
+	for _, d := range list {
+		g.out.WriteString("@")
+		expr := d.Expression.Expr
+		if call, ok := expr.(*ast.CallExpression); ok {
+			expr = call.Callee.Expr
+		}
+		if isDecoratorMember(expr) {
+			g.gen(d.Expression.Expr)
+		} else {
+			g.out.WriteString("(")
+			g.gen(d.Expression.Expr)
+			g.out.WriteString(")")
+		}
+		g.out.WriteString(" ")
+	}
+}
+
+func isDecoratorMember(expr ast.Expr) bool {
+	switch expr := expr.(type) {
+	case *ast.Identifier:
+		return true
+	case *ast.MemberExpression:
+		_, ok := expr.Property.Prop.(*ast.Identifier)
+		return ok && isDecoratorMember(expr.Object.Expr)
+	case *ast.PrivateDotExpression:
+		return isDecoratorMember(expr.Left.Expr)
+	}
+	return false
+}
+
 func (g *GenVisitor) VisitSpreadElement(n *ast.SpreadElement) {
 	g.out.WriteString("...")
 	g.gen(n.Expression.Expr)
 }
 
+func (g *GenVisitor) VisitMetaProperty(n *ast.MetaProperty) {
+	g.gen(n.Meta)
+	g.out.WriteString(".")
+	g.gen(n.Property)
+}
+
+func (g *GenVisitor) VisitImportExpression(n *ast.ImportExpression) {
+	g.out.WriteString("import(")
+	g.gen(n.Source.Expr)
+	if n.Options != nil {
+		g.out.WriteString(", ")
+		g.gen(n.Options.Expr)
+	}
+	g.out.WriteString(")")
+}
+
+func (g *GenVisitor) VisitImportDeclaration(n *ast.ImportDeclaration) {
+	g.out.WriteString("import ")
+	if len(n.Specifiers) > 0 {
+		braced := false
+		for i, spec := range n.Specifiers {
+			switch s := spec.Specifier.(type) {
+			case *ast.ImportDefaultSpecifier:
+				g.gen(s.Local)
+			case *ast.ImportNamespaceSpecifier:
+				if i > 0 {
+					g.out.WriteString(", ")
+				}
+				g.out.WriteString("* as ")
+				g.gen(s.Local)
+			case *ast.ImportNamedSpecifier:
+				if !braced {
+					if i > 0 {
+						g.out.WriteString(", ")
+					}
+					g.out.WriteString("{")
+					braced = true
+				} else {
+					g.out.WriteString(", ")
+				}
+				g.gen(s.Imported)
+				if ident, ok := s.Imported.Name.(*ast.Identifier); !ok || ident.Name != s.Local.Name {
+					g.out.WriteString(" as ")
+					g.gen(s.Local)
+				}
+			}
+		}
+		if braced {
+			g.out.WriteString("}")
+		}
+		g.out.WriteString(" from ")
+	}
+	g.gen(n.Source)
+	g.out.WriteString(";")
+}
+
+func (g *GenVisitor) VisitExportNamedDeclaration(n *ast.ExportNamedDeclaration) {
+	g.out.WriteString("export ")
+	if n.Declaration != nil {
+		if decl, ok := n.Declaration.Stmt.(*ast.FunctionDeclaration); ok {
+			g.gen(decl.Function)
+		} else {
+			g.gen(n.Declaration.Stmt)
+		}
+		return
+	}
+
+	g.out.WriteString("{")
+	for i, spec := range n.Specifiers {
+		g.gen(spec.Local)
+		if !sameExportName(spec.Local, spec.Exported) {
+			g.out.WriteString(" as ")
+			g.gen(spec.Exported)
+		}
+		if i < len(n.Specifiers)-1 {
+			g.out.WriteString(", ")
+		}
+	}
+	g.out.WriteString("}")
+	if n.Source != nil {
+		g.out.WriteString(" from ")
+		g.gen(n.Source)
+	}
+	g.out.WriteString(";")
+}
+
+func (g *GenVisitor) VisitExportDefaultDeclaration(n *ast.ExportDefaultDeclaration) {
+	g.out.WriteString("export default ")
+	if n.Declaration != nil {
+		if decl, ok := n.Declaration.Stmt.(*ast.FunctionDeclaration); ok {
+			g.gen(decl.Function)
+		} else {
+			g.gen(n.Declaration.Stmt)
+		}
+		return
+	}
+
+	g.gen(n.Expression.Expr)
+	switch n.Expression.Expr.(type) {
+	case *ast.FunctionLiteral, *ast.ClassLiteral:
+	default:
+		g.out.WriteString(";")
+	}
+}
+
+func (g *GenVisitor) VisitExportAllDeclaration(n *ast.ExportAllDeclaration) {
+	g.out.WriteString("export * ")
+	if n.Exported != nil {
+		g.out.WriteString("as ")
+		g.gen(n.Exported)
+		g.out.WriteString(" ")
+	}
+	g.out.WriteString("from ")
+	g.gen(n.Source)
+	g.out.WriteString(";")
+}
+
+func (g *GenVisitor) VisitModuleExportName(n *ast.ModuleExportName) {
+	g.gen(n.Name)
+}
+
+func sameExportName(a, b *ast.ModuleExportName) bool {
+	switch a := a.Name.(type) {
+	case *ast.Identifier:
+		b, ok := b.Name.(*ast.Identifier)
+		return ok && a.Name == b.Name
+	case *ast.StringLiteral:
+		b, ok := b.Name.(*ast.StringLiteral)
+		return ok && a.Value == b.Value
+	}
+	return false
+}
+
 func valid(s string) bool {
 	for i, r := range s {
 		if i == 0 && unicode.IsDigit(r) {
@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/t14raptor/go-fast/ast"
+)
+
+// jsxEscaper escapes the characters that cannot appear literally in JSX
+// text or attribute strings.
+var jsxEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	"{", "&#123;",
+	"}", "&#125;",
+	`"`, "&quot;",
+)
+
+func (g *GenVisitor) VisitJSXElement(n *ast.JSXElement) {
+	g.gen(n.Opening)
+	g.jsxChildren(n.Children)
+	if n.Closing != nil {
+		g.gen(n.Closing)
+	}
+}
+
+func (g *GenVisitor) jsxChildren(children ast.JSXChildren) {
+	for _, child := range children {
+		container, ok := child.Child.(*ast.JSXExpressionContainer)
+		if !ok || container.Expression != nil {
+			g.gen(child.Child)
+			continue
+		}
+		// The comments of an empty container are written inside of it, as
+		// they would be text anywhere else.
+		g.out.WriteString("{")
+		if c := g.nodeComments(container); c != nil {
+			for _, comment := range c.Trailing {
+				if comment.Kind == ast.CommentBlock {
+					g.out.WriteString("/*" + comment.Text + "*/")
+				} else {
+					g.out.WriteString("//" + comment.Text + "\n")
+				}
+			}
+		}
+		g.out.WriteString("}")
+	}
+}
+
+func (g *GenVisitor) VisitJSXOpeningElement(n *ast.JSXOpeningElement) {
+	g.out.WriteString("<")
+	g.gen(n.Name)
+	for _, attr := range n.Attributes {
+		g.out.WriteString(" ")
+		g.gen(attr.Attribute)
+	}
+	if n.SelfClosing {
+		g.out.WriteString(" />")
+		return
+	}
+	g.out.WriteString(">")
+}
+
+func (g *GenVisitor) VisitJSXClosingElement(n *ast.JSXClosingElement) {
+	g.out.WriteString("</")
+	g.gen(n.Name)
+	g.out.WriteString(">")
+}
+
+func (g *GenVisitor) VisitJSXFragment(n *ast.JSXFragment) {
+	g.out.WriteString("<>")
+	g.jsxChildren(n.Children)
+	g.out.WriteString("</>")
+}
+
+func (g *GenVisitor) VisitJSXName(n *ast.JSXName) {
+	g.out.WriteString(n.Name)
+}
+
+func (g *GenVisitor) VisitJSXAttribute(n *ast.JSXAttribute) {
+	g.gen(n.Name)
+	if n.Value == nil {
+		return
+	}
+	g.out.WriteString("=")
+	if str, ok := n.Value.Expr.(*ast.StringLiteral); ok && str.Raw == nil {
+		// Escape sequences are not interpreted in attribute strings.
+		g.out.WriteString(`"` + jsxEscaper.Replace(str.Value) + `"`)
+		return
+	}
+	g.gen(n.Value.Expr)
+}
+
+func (g *GenVisitor) VisitJSXSpreadAttribute(n *ast.JSXSpreadAttribute) {
+	g.out.WriteString("{...")
+	g.gen(n.Argument.Expr)
+	g.out.WriteString("}")
+}
+
+func (g *GenVisitor) VisitJSXExpressionContainer(n *ast.JSXExpressionContainer) {
+	g.out.WriteString("{")
+	if n.Expression != nil {
+		g.gen(n.Expression.Expr)
+	}
+	g.out.WriteString("}")
+}
+
+func (g *GenVisitor) VisitJSXText(n *ast.JSXText) {
+	if n.Raw != "" {
+		g.out.WriteString(n.Raw)
+		return
+	}
+	g.out.WriteString(jsxEscaper.Replace(n.Value))
+}
@@ -0,0 +1,125 @@
+// Package limit bounds the work the parser, the generator and the serializer
+// do on hostile input, which could otherwise exhaust the stack of their
+// recursive walks or run for too long.
+package limit
+
+import (
+	"context"
+	"fmt"
+)
+
+// Limits configures the limits of a parse, generation or serialization. The
+// zero value imposes no limits.
+type Limits struct {
+	// MaxDepth limits how deeply the walk may recurse, which grows with the
+	// nesting of the code. Zero means no limit.
+	MaxDepth int
+	// MaxNodes limits the number of nodes created or visited. Zero means no
+	// limit.
+	MaxNodes int
+	// Context stops the walk once it is done. A nil Context never does.
+	Context context.Context
+}
+
+// DepthError is returned when a walk recurses deeper than Limits.MaxDepth.
+type DepthError struct {
+	MaxDepth int
+}
+
+func (e *DepthError) Error() string {
+	return fmt.Sprintf("maximum depth of %d exceeded", e.MaxDepth)
+}
+
+// NodeError is returned when a walk sees more than Limits.MaxNodes nodes.
+type NodeError struct {
+	MaxNodes int
+}
+
+func (e *NodeError) Error() string {
+	return fmt.Sprintf("maximum number of %d nodes exceeded", e.MaxNodes)
+}
+
+// CanceledError is returned when the Context of the limits is done. It wraps
+// the error of the context, so errors.Is(err, context.DeadlineExceeded)
+// reports whether a deadline stopped the walk.
+type CanceledError struct {
+	Err error
+}
+
+func (e *CanceledError) Error() string {
+	return "canceled: " + e.Err.Error()
+}
+
+func (e *CanceledError) Unwrap() error {
+	return e.Err
+}
+
+// checkInterval is how many nodes are counted between checks of the context.
+const checkInterval = 1024
+
+// Tracker enforces limits on a recursive walk. When a limit is exceeded, its
+// methods panic with a value that Catch turns into the error of the limit.
+// The zero value imposes no limits.
+type Tracker struct {
+	limits Limits
+	depth  int
+	nodes  int
+}
+
+// Start resets the tracker for a new walk with limits. It panics like Check
+// if the context is done already.
+func (t *Tracker) Start(limits Limits) {
+	*t = Tracker{limits: limits}
+	t.Check()
+}
+
+// Enter is called when the walk recurses, and must be paired with a call to
+// Leave when it returns.
+func (t *Tracker) Enter() {
+	if t.depth++; t.limits.MaxDepth > 0 && t.depth > t.limits.MaxDepth {
+		panic(abort{&DepthError{MaxDepth: t.limits.MaxDepth}})
+	}
+}
+
+// Leave is called when the walk returns from a recursion.
+func (t *Tracker) Leave() {
+	t.depth--
+}
+
+// Count counts a node, checking the context every few nodes.
+func (t *Tracker) Count() {
+	if t.nodes++; t.limits.MaxNodes > 0 && t.nodes > t.limits.MaxNodes {
+		panic(abort{&NodeError{MaxNodes: t.limits.MaxNodes}})
+	}
+	if t.nodes%checkInterval == 0 {
+		t.Check()
+	}
+}
+
+// Check panics with a CanceledError if the context is done.
+func (t *Tracker) Check() {
+	if t.limits.Context == nil {
+		return
+	}
+	if err := t.limits.Context.Err(); err != nil {
+		panic(abort{&CanceledError{Err: err}})
+	}
+}
+
+// abort is the panic value of an exceeded limit.
+type abort struct {
+	err error
+}
+
+// Catch recovers from the panic of an exceeded limit and stores its error in
+// err. It has to be deferred directly, as in defer limit.Catch(&err). Other
+// panics are passed on.
+func Catch(err *error) {
+	if r := recover(); r != nil {
+		a, ok := r.(abort)
+		if !ok {
+			panic(r)
+		}
+		*err = a.err
+	}
+}
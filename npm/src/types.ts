@@ -1,15 +1,56 @@
 export interface ParseOptions {
   /** Whether to resolve variable scopes */
   resolve?: boolean;
+  /** Parse as an ES module instead of a classic script. Defaults to "script". */
+  sourceType?: "script" | "module";
+  /** Collect comments and attach them to the nearest nodes */
+  comments?: boolean;
+  /** Return a partial AST with all syntax errors instead of failing on the first one */
+  tolerant?: boolean;
+  /** Parse JSX elements and fragments */
+  jsx?: boolean;
+  /** Strip TypeScript syntax, parsing .ts (or .tsx together with `jsx`) sources */
+  typescript?: boolean;
+  /** Allow the web compatibility syntax of Annex B, such as `<!--` comments, in scripts (default true) */
+  webCompat?: boolean;
+  /** Fail with an error instead of recursing deeper than this into nested code */
+  maxDepth?: number;
+  /** Fail with an error once more nodes than this are created */
+  maxNodes?: number;
+  /** Add a `loc` object with the lines and columns of the start and end to every node */
+  locations?: boolean;
+  /** Count `start` and `end` offsets in UTF-16 code units, so that they index the source string, instead of UTF-8 bytes */
+  utf16?: boolean;
 }
 
 export interface Position {
   start: number;
   end: number;
+  /** Present with the `locations` option */
+  loc?: SourceLocation;
+}
+
+export interface SourceLocation {
+  start: LineColumn;
+  end: LineColumn;
+}
+
+export interface LineColumn {
+  /** Counted from 1 */
+  line: number;
+  /** Counted in UTF-16 code units from 0 */
+  column: number;
+}
+
+export interface Comment extends Position {
+  type: "Line" | "Block";
+  value: string;
 }
 
 export interface BaseNode extends Position {
   type: string;
+  leadingComments?: Comment[];
+  trailingComments?: Comment[];
 }
 
 export interface Identifier extends BaseNode {
@@ -23,11 +64,36 @@ export interface Literal extends BaseNode {
   value: string | number | boolean | null;
   raw?: string;
   regex?: { pattern: string; flags: string };
+  bigint?: string;
 }
 
 export interface Program extends BaseNode {
   type: "Program";
   body: Statement[];
+  sourceType: "script" | "module";
+  comments?: Comment[];
+  /** Syntax errors of a tolerant parse */
+  errors?: SyntaxErrorInfo[];
+}
+
+export interface SyntaxErrorInfo extends ErrorSpan {
+  /** Stable name of the kind of error, such as `"UnexpectedToken"` */
+  code: string;
+  /** A suggestion how to fix the error */
+  hint?: string;
+  /** Another part of the source that explains the error, such as where an unclosed brace opened */
+  related?: ErrorSpan;
+}
+
+export interface ErrorSpan {
+  message: string;
+  line: number;
+  /** Counted from 1, in UTF-16 code units with the `utf16` option and in bytes otherwise */
+  column: number;
+  /** In UTF-16 code units with the `utf16` option and in bytes otherwise */
+  offset: number;
+  /** Length of the source the error is about, 0 if unknown; counted like `offset` */
+  length: number;
 }
 
 // ESTree compatible types
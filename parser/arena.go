@@ -1,38 +1,120 @@
 package parser
 
 import (
+	"sync"
 	"unsafe"
+
+	"github.com/t14raptor/go-fast/ast"
 )
 
+// arenas hold the nodes a parse allocates the most.
+type arenas struct {
+	exprs   *miniArena[ast.Expression]
+	stmts   *miniArena[ast.Statement]
+	idents  *miniArena[ast.Identifier]
+	strings *miniArena[ast.StringLiteral]
+	numbers *miniArena[ast.NumberLiteral]
+	calls   *miniArena[ast.CallExpression]
+	members *miniArena[ast.MemberExpression]
+	props   *miniArena[ast.MemberProperty]
+	targets *miniArena[ast.BindingTarget]
+}
+
+func newArenas() arenas {
+	return arenas{
+		exprs:   newArena[ast.Expression](1024),
+		stmts:   newArena[ast.Statement](1024),
+		idents:  newArena[ast.Identifier](256),
+		strings: newArena[ast.StringLiteral](128),
+		numbers: newArena[ast.NumberLiteral](128),
+		calls:   newArena[ast.CallExpression](128),
+		members: newArena[ast.MemberExpression](128),
+		props:   newArena[ast.MemberProperty](128),
+		targets: newArena[ast.BindingTarget](128),
+	}
+}
+
+// take takes the blocks of all arenas, see miniArena.take, and returns a
+// function that recycles them.
+func (a *arenas) take() func() {
+	recycle := []func(){
+		a.exprs.take(), a.stmts.take(), a.idents.take(),
+		a.strings.take(), a.numbers.take(),
+		a.calls.take(), a.members.take(), a.props.take(), a.targets.take(),
+	}
+	return func() {
+		for _, r := range recycle {
+			r()
+		}
+	}
+}
+
 type miniArena[T any] struct {
 	elementSize uintptr
 
 	a     unsafe.Pointer
 	len   uintptr
 	index uintptr
+	size  int // The length of the next new block
+
+	// blocks are the blocks handed out since the last take.
+	blocks [][]T
+
+	mu   sync.Mutex // Guards free, as programs may be released anywhere
+	free [][]T      // Cleared blocks to hand out again
 }
 
 func newArena[T any](startLen int) *miniArena[T] {
 	var t T
 	return &miniArena[T]{
 		elementSize: unsafe.Sizeof(t),
-		len:         uintptr(startLen),
-		a:           unsafe.Pointer(&make([]T, startLen)[0]),
+		size:        startLen,
 	}
 }
 
 func (a *miniArena[T]) make() *T {
-	n := (*T)(unsafe.Add(a.a, a.index*a.elementSize))
-	if a.index++; a.index == a.len {
+	if a.index == a.len {
 		a.resize()
 	}
-
+	n := (*T)(unsafe.Add(a.a, a.index*a.elementSize))
+	a.index++
 	return n
 }
 
 func (a *miniArena[T]) resize() {
-	a.len = uintptr(float64(a.len) * 1.5)
+	var block []T
+	a.mu.Lock()
+	if n := len(a.free); n > 0 {
+		block, a.free = a.free[n-1], a.free[:n-1]
+	}
+	a.mu.Unlock()
+	if block == nil {
+		block = make([]T, a.size)
+		a.size = int(float64(a.size) * 1.5)
+	}
+	a.blocks = append(a.blocks, block)
 
-	a.a = unsafe.Pointer(&make([]T, a.len)[0])
+	a.a = unsafe.Pointer(&block[0])
+	a.len = uintptr(len(block))
 	a.index = 0
 }
+
+// take hands the blocks handed out so far over to the parsed program and
+// starts the next parse on a fresh block. It returns a function that takes
+// the blocks back once the program is released.
+func (a *miniArena[T]) take() (recycle func()) {
+	blocks := a.blocks
+	a.blocks = nil
+	a.a, a.len, a.index = nil, 0, 0
+	return func() { a.recycle(blocks) }
+}
+
+// recycle takes back blocks returned by take.
+func (a *miniArena[T]) recycle(blocks [][]T) {
+	for _, block := range blocks {
+		clear(block)
+	}
+	a.mu.Lock()
+	a.free = append(a.free, blocks...)
+	a.mu.Unlock()
+}
@@ -0,0 +1,179 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/t14raptor/go-fast/ast"
+)
+
+// commentAttacher walks the program in source order and hands every comment
+// to the nearest statement, class element or expression.
+//
+// The file header, the comments at the top of the source that stand apart
+// from the first statement, becomes the leading comments of the program, so
+// that a license survives the removal of the statement after it. Comments in
+// front of a node become its leading comments. Comments after a statement or
+// class element on the same line, and comments inside of one that no child
+// claimed, become its trailing comments. Whatever is left at the end of the
+// source is attached to the program itself.
+type commentAttacher struct {
+	ast.NoopVisitor
+
+	src      string
+	comments []*ast.Comment
+	next     int // The first comment that is not attached yet
+
+	attached map[ast.Node]*ast.NodeComments
+}
+
+func attachComments(program *ast.Program, src string, comments []*ast.Comment) map[ast.Node]*ast.NodeComments {
+	if len(comments) == 0 {
+		return nil
+	}
+	a := &commentAttacher{
+		src:      src,
+		comments: comments,
+		attached: make(map[ast.Node]*ast.NodeComments),
+	}
+	a.V = a
+	a.header(program)
+	program.VisitChildrenWith(a)
+	for ; a.next < len(a.comments); a.next++ {
+		a.get(program).Trailing = append(a.get(program).Trailing, a.comments[a.next])
+	}
+	return a.attached
+}
+
+// header attaches the file header to program. It ends with the last comment
+// in front of the first statement that is followed by a blank line or is a
+// legal comment, like /*! ... */ or one with @license or @preserve.
+func (a *commentAttacher) header(program *ast.Program) {
+	if len(program.Directives) == 0 && len(program.Body) == 0 {
+		return
+	}
+	start := program.Idx0()
+	end := 0
+	for i, c := range a.comments {
+		if c.Idx1() > start {
+			break
+		}
+		next := start
+		if i+1 < len(a.comments) && a.comments[i+1].Idx0() < next {
+			next = a.comments[i+1].Idx0()
+		}
+		if isLegalComment(c) || a.blankLine(c.Idx1(), next) {
+			end = i + 1
+		}
+	}
+	if end > 0 {
+		a.get(program).Leading = append(a.get(program).Leading, a.comments[:end]...)
+		a.next = end
+	}
+}
+
+func isLegalComment(c *ast.Comment) bool {
+	return strings.HasPrefix(c.Text, "!") || strings.Contains(c.Text, "@license") || strings.Contains(c.Text, "@preserve")
+}
+
+// blankLine reports whether an empty line lies between from and to.
+func (a *commentAttacher) blankLine(from, to ast.Idx) bool {
+	lines := 0
+	for i := int(from) - 1; i < int(to)-1 && i < len(a.src); i++ {
+		switch a.src[i] {
+		case '\n':
+			lines++
+		case '\r':
+			if i+1 >= len(a.src) || a.src[i+1] != '\n' {
+				lines++
+			}
+		}
+	}
+	return lines >= 2
+}
+
+func (a *commentAttacher) get(node ast.Node) *ast.NodeComments {
+	c, ok := a.attached[node]
+	if !ok {
+		c = &ast.NodeComments{}
+		a.attached[node] = c
+	}
+	return c
+}
+
+func (a *commentAttacher) leading(node ast.Node) {
+	start := node.Idx0()
+	for ; a.next < len(a.comments) && a.comments[a.next].Idx1() <= start; a.next++ {
+		a.get(node).Leading = append(a.get(node).Leading, a.comments[a.next])
+	}
+}
+
+func (a *commentAttacher) trailing(node ast.Node) {
+	end := node.Idx1()
+	for ; a.next < len(a.comments); a.next++ {
+		c := a.comments[a.next]
+		if c.Idx1() > end && !a.sameLine(end, c.Idx0()) {
+			break
+		}
+		a.get(node).Trailing = append(a.get(node).Trailing, c)
+	}
+}
+
+// sameLine reports whether only blanks and semicolons separate from and to.
+func (a *commentAttacher) sameLine(from, to ast.Idx) bool {
+	if from > to {
+		return false
+	}
+	for i := int(from) - 1; i < int(to)-1 && i < len(a.src); i++ {
+		switch a.src[i] {
+		case ' ', '\t', ';':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (a *commentAttacher) VisitStatement(n *ast.Statement) {
+	if n.Stmt == nil {
+		return
+	}
+	a.leading(n.Stmt)
+	n.VisitChildrenWith(a)
+	a.trailing(n.Stmt)
+}
+
+func (a *commentAttacher) VisitDirective(n *ast.Directive) {
+	a.leading(n)
+	a.trailing(n)
+}
+
+func (a *commentAttacher) VisitClassElement(n *ast.ClassElement) {
+	node, ok := n.Element.(ast.Node)
+	if !ok {
+		n.VisitChildrenWith(a)
+		return
+	}
+	a.leading(node)
+	n.VisitChildrenWith(a)
+	a.trailing(node)
+}
+
+func (a *commentAttacher) VisitExpression(n *ast.Expression) {
+	if n.Expr == nil {
+		return
+	}
+	a.leading(n.Expr)
+	n.VisitChildrenWith(a)
+}
+
+// VisitJSXExpressionContainer keeps the comments of an empty container such
+// as {/* comment */} on the container, since nothing else is inside of it.
+func (a *commentAttacher) VisitJSXExpressionContainer(n *ast.JSXExpressionContainer) {
+	if n.Expression != nil {
+		n.VisitChildrenWith(a)
+		return
+	}
+	for ; a.next < len(a.comments) && a.comments[a.next].Idx1() <= n.RightBrace; a.next++ {
+		a.get(n).Trailing = append(a.get(n).Trailing, a.comments[a.next])
+	}
+}
@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"github.com/t14raptor/go-fast/ast"
+	"github.com/t14raptor/go-fast/token"
+)
+
+// parseDecorators parses the decorators at the current token, if any.
+func (p *parser) parseDecorators() (list ast.Decorators) {
+	for p.token == token.At {
+		at := p.idx
+		p.next()
+		list = append(list, ast.Decorator{At: at, Expression: p.makeExpr(p.parseDecoratorExpression())})
+	}
+	return list
+}
+
+// parseDecoratorExpression parses the expression of a decorator, which is
+// either parenthesized or a chain of property accesses on an identifier that
+// is optionally called, as in @a.b.#c(d).
+func (p *parser) parseDecoratorExpression() ast.Expr {
+	if p.token == token.LeftParenthesis {
+		p.next()
+		expr := p.parseExpression()
+		p.expect(token.RightParenthesis)
+		return expr
+	}
+
+	if !p.isBindingId(p.token) {
+		idx := p.idx
+		p.errorUnexpectedToken(p.token)
+		p.nextStatement()
+		return &ast.InvalidExpression{From: idx, To: p.idx}
+	}
+	var expr ast.Expr = p.parseIdentifier()
+	for p.token == token.Period {
+		expr = p.parseDotMember(expr)
+	}
+	if p.opts.TypeScript && p.token == token.Less {
+		p.tryTypeArguments()
+	}
+	if p.token == token.LeftParenthesis {
+		expr = p.parseCallExpression(expr)
+	}
+	return expr
+}
+
+// parseClassDecorators parses the decorators in front of a class declaration,
+// which parseClass attaches to the class that follows. With export set they
+// may also be in front of an export of the class, as in @a export class B {}.
+func (p *parser) parseClassDecorators(export bool) {
+	if p.decorators != nil {
+		// Decorators both in front of and after export, as in @a export @b class C {}.
+		p.errorAt(p.decorators[0].At, CodeDecoratorsNotValid)
+	}
+	p.decorators = p.parseDecorators()
+	p.expectDecoratedClass(export)
+}
+
+// expectDecoratedClass drops the pending class decorators with an error
+// unless a class, or with export set an export, follows them.
+func (p *parser) expectDecoratedClass(export bool) {
+	switch {
+	case p.decorators == nil, p.token == token.Class, export && p.token == token.Export:
+	case p.opts.TypeScript && p.isContextual("abstract") && p.peek() == token.Class:
+	default:
+		p.errorAt(p.decorators[0].At, CodeDecoratorsNotValid)
+		p.decorators = nil
+	}
+}
+
+// parseAccessorKeyword consumes the accessor keyword of a class element and
+// reports whether it was there. It is the name of the element instead when
+// followed by a line break or anything but the name of an element.
+func (p *parser) parseAccessorKeyword() bool {
+	if !p.isContextual("accessor") {
+		return false
+	}
+	var state parserState
+	p.mark(&state)
+	p.next()
+	switch {
+	case p.implicitSemicolon:
+	case token.ID(p.token), p.token == token.String, p.token == token.Number,
+		p.token == token.LeftBracket, p.token == token.PrivateIdentifier:
+		return true
+	}
+	p.restore(&state)
+	return false
+}
@@ -2,97 +2,469 @@ package parser
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/t14raptor/go-fast/ast"
 	"github.com/t14raptor/go-fast/token"
 )
 
+// Code identifies the kind of a SyntaxError, so that tools can tell errors
+// apart without matching their messages. The value of a code never changes,
+// new codes are only ever added at the end.
+type Code int
+
 const (
-	errUnexpectedToken      = "Unexpected token %v"
-	errUnexpectedEndOfInput = "Unexpected end of input"
+	// CodeUnknown is the code of errors added with ErrorList.Add.
+	CodeUnknown Code = iota
+
+	CodeUnexpectedToken
+	CodeUnexpectedEnd
+	CodeUnexpectedIdentifier
+	CodeUnexpectedReservedWord
+	CodeUnexpectedNumber
+	CodeUnexpectedString
+	CodeEscapedKeyword
+	CodeInvalidUTF8
+	CodeInvalidNumber
+	CodeInvalidEscape
+	CodeIllegalBoolean
+	CodeUnterminatedRegExp
+	CodeInvalidRegExp
+	CodeInvalidRegExpFlags
+
+	CodeWithInStrictMode
+	CodeOctalInStrictMode
+	CodeLeadingZeroInStrictMode
+	CodeOctalEscapeInStrictMode
+	CodeNonOctalEscapeInStrictMode
+	CodeEvalOrArgumentsInStrictMode
+	CodeDeleteIdentifierInStrictMode
+	CodeDuplicateParameter
+	CodeUseStrictWithComplexParameters
+
+	CodeIllegalReturn
+	CodeIllegalBreak
+	CodeIllegalContinue
+	CodeUndefinedLabel
+	CodeDuplicateLabel
+	CodeNewlineAfterThrow
+	CodeMissingCatchOrFinally
+	CodeDuplicateDefault
+	CodeLexicalDeclarationInStatement
+	CodeStrictFunctionInStatement
+	CodeSloppyFunctionInStatement
+	CodeFunctionInStatement
+	CodeAsyncFunctionInStatement
+	CodeGeneratorInStatement
+	CodeLabelledFunctionInStatement
+	CodeMissingDestructuringInitializer
+	CodeForInInitializer
+	CodeForInUsing
+	CodeInvalidForInTarget
+	CodeForAwaitWithoutOf
+	CodeImportNotTopLevel
+	CodeExportNotTopLevel
+	CodeImportMetaOutsideModule
+	CodeExportSpecifierNotLocal
+	CodeUsingPattern
+	CodeUsingWithoutInitializer
+	CodeUsingNotAllowed
+
+	CodeUnexpectedSuper
+	CodeNewImport
+	CodeTemplateInOptionalChain
+	CodeInvalidAssignmentTarget
+	CodeInvalidDestructuringTarget
+	CodeInvalidBindingTarget
+	CodeInvalidBindingRest
+	CodeRestParameterNotLast
+	CodeRestElementNotLast
+	CodeCommaNotAllowed
+	CodeMalformedArrowParameters
+	CodeAwaitInParameters
+	CodeYieldInParameters
+	CodeUnaryBeforeExponent
+	CodeMixedCoalesce
+	CodeGetterParameters
+	CodeSetterParameters
+
+	CodeStaticPrototype
+	CodeConstructorField
+	CodeConstructorAccessor
+	CodeConstructorAsync
+	CodeConstructorGenerator
+	CodeConstructorPrivate
+	CodeDecoratorsNotValid
+
+	CodeJSXFragmentMismatch
+	CodeJSXTagMismatch
+	CodeJSXEmptyAttribute
+	CodeJSXUnterminated
+	CodeJSXUnterminatedString
+
+	CodeTSNamespaceWithCode
+	CodeTSEnumInitializer
+
+	CodeDuplicateExport
+	CodeUndeclaredExport
+	CodeDuplicateImport
+	CodeImportOutsideModule
+	CodeExportOutsideModule
+	CodeUnterminatedString
+	CodeUnterminatedTemplate
+	CodeNumericSeparator
 )
 
+var code2string = [...]string{
+	CodeUnknown:                         "Unknown",
+	CodeUnexpectedToken:                 "UnexpectedToken",
+	CodeUnexpectedEnd:                   "UnexpectedEnd",
+	CodeUnexpectedIdentifier:            "UnexpectedIdentifier",
+	CodeUnexpectedReservedWord:          "UnexpectedReservedWord",
+	CodeUnexpectedNumber:                "UnexpectedNumber",
+	CodeUnexpectedString:                "UnexpectedString",
+	CodeEscapedKeyword:                  "EscapedKeyword",
+	CodeInvalidUTF8:                     "InvalidUTF8",
+	CodeInvalidNumber:                   "InvalidNumber",
+	CodeInvalidEscape:                   "InvalidEscape",
+	CodeIllegalBoolean:                  "IllegalBoolean",
+	CodeUnterminatedRegExp:              "UnterminatedRegExp",
+	CodeInvalidRegExp:                   "InvalidRegExp",
+	CodeInvalidRegExpFlags:              "InvalidRegExpFlags",
+	CodeWithInStrictMode:                "WithInStrictMode",
+	CodeOctalInStrictMode:               "OctalInStrictMode",
+	CodeLeadingZeroInStrictMode:         "LeadingZeroInStrictMode",
+	CodeOctalEscapeInStrictMode:         "OctalEscapeInStrictMode",
+	CodeNonOctalEscapeInStrictMode:      "NonOctalEscapeInStrictMode",
+	CodeEvalOrArgumentsInStrictMode:     "EvalOrArgumentsInStrictMode",
+	CodeDeleteIdentifierInStrictMode:    "DeleteIdentifierInStrictMode",
+	CodeDuplicateParameter:              "DuplicateParameter",
+	CodeUseStrictWithComplexParameters:  "UseStrictWithComplexParameters",
+	CodeIllegalReturn:                   "IllegalReturn",
+	CodeIllegalBreak:                    "IllegalBreak",
+	CodeIllegalContinue:                 "IllegalContinue",
+	CodeUndefinedLabel:                  "UndefinedLabel",
+	CodeDuplicateLabel:                  "DuplicateLabel",
+	CodeNewlineAfterThrow:               "NewlineAfterThrow",
+	CodeMissingCatchOrFinally:           "MissingCatchOrFinally",
+	CodeDuplicateDefault:                "DuplicateDefault",
+	CodeLexicalDeclarationInStatement:   "LexicalDeclarationInStatement",
+	CodeStrictFunctionInStatement:       "StrictFunctionInStatement",
+	CodeSloppyFunctionInStatement:       "SloppyFunctionInStatement",
+	CodeFunctionInStatement:             "FunctionInStatement",
+	CodeAsyncFunctionInStatement:        "AsyncFunctionInStatement",
+	CodeGeneratorInStatement:            "GeneratorInStatement",
+	CodeLabelledFunctionInStatement:     "LabelledFunctionInStatement",
+	CodeMissingDestructuringInitializer: "MissingDestructuringInitializer",
+	CodeForInInitializer:                "ForInInitializer",
+	CodeForInUsing:                      "ForInUsing",
+	CodeInvalidForInTarget:              "InvalidForInTarget",
+	CodeForAwaitWithoutOf:               "ForAwaitWithoutOf",
+	CodeImportNotTopLevel:               "ImportNotTopLevel",
+	CodeExportNotTopLevel:               "ExportNotTopLevel",
+	CodeImportMetaOutsideModule:         "ImportMetaOutsideModule",
+	CodeExportSpecifierNotLocal:         "ExportSpecifierNotLocal",
+	CodeUsingPattern:                    "UsingPattern",
+	CodeUsingWithoutInitializer:         "UsingWithoutInitializer",
+	CodeUsingNotAllowed:                 "UsingNotAllowed",
+	CodeUnexpectedSuper:                 "UnexpectedSuper",
+	CodeNewImport:                       "NewImport",
+	CodeTemplateInOptionalChain:         "TemplateInOptionalChain",
+	CodeInvalidAssignmentTarget:         "InvalidAssignmentTarget",
+	CodeInvalidDestructuringTarget:      "InvalidDestructuringTarget",
+	CodeInvalidBindingTarget:            "InvalidBindingTarget",
+	CodeInvalidBindingRest:              "InvalidBindingRest",
+	CodeRestParameterNotLast:            "RestParameterNotLast",
+	CodeRestElementNotLast:              "RestElementNotLast",
+	CodeCommaNotAllowed:                 "CommaNotAllowed",
+	CodeMalformedArrowParameters:        "MalformedArrowParameters",
+	CodeAwaitInParameters:               "AwaitInParameters",
+	CodeYieldInParameters:               "YieldInParameters",
+	CodeUnaryBeforeExponent:             "UnaryBeforeExponent",
+	CodeMixedCoalesce:                   "MixedCoalesce",
+	CodeGetterParameters:                "GetterParameters",
+	CodeSetterParameters:                "SetterParameters",
+	CodeStaticPrototype:                 "StaticPrototype",
+	CodeConstructorField:                "ConstructorField",
+	CodeConstructorAccessor:             "ConstructorAccessor",
+	CodeConstructorAsync:                "ConstructorAsync",
+	CodeConstructorGenerator:            "ConstructorGenerator",
+	CodeConstructorPrivate:              "ConstructorPrivate",
+	CodeDecoratorsNotValid:              "DecoratorsNotValid",
+	CodeJSXFragmentMismatch:             "JSXFragmentMismatch",
+	CodeJSXTagMismatch:                  "JSXTagMismatch",
+	CodeJSXEmptyAttribute:               "JSXEmptyAttribute",
+	CodeJSXUnterminated:                 "JSXUnterminated",
+	CodeJSXUnterminatedString:           "JSXUnterminatedString",
+	CodeTSNamespaceWithCode:             "TSNamespaceWithCode",
+	CodeTSEnumInitializer:               "TSEnumInitializer",
+	CodeDuplicateExport:                 "DuplicateExport",
+	CodeUndeclaredExport:                "UndeclaredExport",
+	CodeDuplicateImport:                 "DuplicateImport",
+	CodeImportOutsideModule:             "ImportOutsideModule",
+	CodeExportOutsideModule:             "ExportOutsideModule",
+	CodeUnterminatedString:              "UnterminatedString",
+	CodeUnterminatedTemplate:            "UnterminatedTemplate",
+	CodeNumericSeparator:                "NumericSeparator",
+}
+
+// String returns the name of the code, which is as stable as its value.
+func (c Code) String() string {
+	if c >= 0 && int(c) < len(code2string) {
+		return code2string[c]
+	}
+	return "code(" + strconv.Itoa(int(c)) + ")"
+}
+
+// code2message holds the message of every code, formatted with the
+// arguments of the error.
+var code2message = [...]string{
+	CodeUnexpectedToken:                 "Unexpected token %v",
+	CodeUnexpectedEnd:                   "Unexpected end of input",
+	CodeUnexpectedIdentifier:            "Unexpected identifier",
+	CodeUnexpectedReservedWord:          "Unexpected reserved word",
+	CodeUnexpectedNumber:                "Unexpected number",
+	CodeUnexpectedString:                "Unexpected string",
+	CodeEscapedKeyword:                  "Keyword must not contain escaped characters",
+	CodeInvalidUTF8:                     "Invalid UTF-8 character",
+	CodeInvalidNumber:                   "Illegal numeric literal",
+	CodeInvalidEscape:                   "%s",
+	CodeIllegalBoolean:                  "Illegal boolean literal",
+	CodeUnterminatedRegExp:              "Invalid regular expression: missing /",
+	CodeInvalidRegExp:                   "Invalid regular expression: /%s/: %s",
+	CodeInvalidRegExpFlags:              "Invalid regular expression flags",
+	CodeWithInStrictMode:                "Strict mode code may not include a with statement",
+	CodeOctalInStrictMode:               "Octal literals are not allowed in strict mode",
+	CodeLeadingZeroInStrictMode:         "Decimals with leading zeros are not allowed in strict mode",
+	CodeOctalEscapeInStrictMode:         "Octal escape sequences are not allowed in strict mode",
+	CodeNonOctalEscapeInStrictMode:      "\\8 and \\9 are not allowed in strict mode",
+	CodeEvalOrArgumentsInStrictMode:     "Unexpected eval or arguments in strict mode",
+	CodeDeleteIdentifierInStrictMode:    "Delete of an unqualified identifier in strict mode",
+	CodeDuplicateParameter:              "Duplicate parameter name not allowed in this context",
+	CodeUseStrictWithComplexParameters:  "Illegal 'use strict' directive in function with non-simple parameter list",
+	CodeIllegalReturn:                   "Illegal return statement",
+	CodeIllegalBreak:                    "Illegal break statement",
+	CodeIllegalContinue:                 "Illegal continue statement",
+	CodeUndefinedLabel:                  "Undefined label '%s'",
+	CodeDuplicateLabel:                  "Label '%s' has already been declared",
+	CodeNewlineAfterThrow:               "Illegal newline after throw",
+	CodeMissingCatchOrFinally:           "Missing catch or finally after try",
+	CodeDuplicateDefault:                "Already saw a default in switch",
+	CodeLexicalDeclarationInStatement:   "Lexical declaration cannot appear in a single-statement context",
+	CodeStrictFunctionInStatement:       "In strict mode code, functions can only be declared at top level or inside a block",
+	CodeSloppyFunctionInStatement:       "In non-strict mode code, functions can only be declared at top level, inside a block, or as the body of an if statement",
+	CodeFunctionInStatement:             "Functions can only be declared at top level or inside a block",
+	CodeAsyncFunctionInStatement:        "Async functions can only be declared at the top level or inside a block",
+	CodeGeneratorInStatement:            "Generators can only be declared at the top level or inside a block",
+	CodeLabelledFunctionInStatement:     "Labelled function declaration not allowed as the body of a control flow structure",
+	CodeMissingDestructuringInitializer: "Missing initializer in destructuring declaration",
+	CodeForInInitializer:                "for-in loop variable declaration may not have an initializer",
+	CodeForInUsing:                      "for-in loop variable declaration may not be a using declaration",
+	CodeInvalidForInTarget:              "Invalid left-hand side in for-in or for-of",
+	CodeForAwaitWithoutOf:               "for await may only be used with for-of loops",
+	CodeImportNotTopLevel:               "Import declarations may only appear at the top level",
+	CodeExportNotTopLevel:               "Export declarations may only appear at the top level",
+	CodeImportMetaOutsideModule:         "Cannot use 'import.meta' outside a module",
+	CodeExportSpecifierNotLocal:         "Export specifier must refer to a local binding",
+	CodeUsingPattern:                    "Using declarations may not have binding patterns",
+	CodeUsingWithoutInitializer:         "Missing initializer in using declaration",
+	CodeUsingNotAllowed:                 "Using declarations may not appear %s",
+	CodeUnexpectedSuper:                 "'super' keyword unexpected here",
+	CodeNewImport:                       "Cannot use new with import",
+	CodeTemplateInOptionalChain:         "Invalid template literal on optional chain",
+	CodeInvalidAssignmentTarget:         "Invalid left-hand side in assignment",
+	CodeInvalidDestructuringTarget:      "Invalid destructuring assignment target",
+	CodeInvalidBindingTarget:            "Invalid destructuring binding target",
+	CodeInvalidBindingRest:              "Invalid binding rest",
+	CodeRestParameterNotLast:            "Rest parameter must be last formal parameter",
+	CodeRestElementNotLast:              "Rest element must be last element",
+	CodeCommaNotAllowed:                 "Comma is not allowed here",
+	CodeMalformedArrowParameters:        "Malformed arrow function parameter list",
+	CodeAwaitInParameters:               "Illegal await-expression in formal parameters of async function",
+	CodeYieldInParameters:               "Yield expression not allowed in formal parameter",
+	CodeUnaryBeforeExponent:             "Unary operator used immediately before exponentiation expression. Parenthesis must be used to disambiguate operator precedence",
+	CodeMixedCoalesce:                   "Logical expressions and coalesce expressions cannot be mixed. Wrap either by parentheses",
+	CodeGetterParameters:                "Getter must not have any formal parameters.",
+	CodeSetterParameters:                "Setter must have exactly one formal parameter.",
+	CodeStaticPrototype:                 "Classes may not have a static property named 'prototype'",
+	CodeConstructorField:                "Classes may not have a field named 'constructor'",
+	CodeConstructorAccessor:             "Class constructor may not be an accessor",
+	CodeConstructorAsync:                "Class constructor may not be an async method",
+	CodeConstructorGenerator:            "Class constructor may not be a generator",
+	CodeConstructorPrivate:              "Class constructor may not be a private method",
+	CodeDecoratorsNotValid:              "Decorators are not valid here",
+	CodeJSXFragmentMismatch:             "Expected corresponding closing tag for JSX fragment",
+	CodeJSXTagMismatch:                  "Expected corresponding JSX closing tag for <%s>",
+	CodeJSXEmptyAttribute:               "JSX attributes must only be assigned a non-empty expression",
+	CodeJSXUnterminated:                 "Unterminated JSX contents",
+	CodeJSXUnterminatedString:           "Unterminated string constant",
+	CodeTSNamespaceWithCode:             "TypeScript namespaces with runtime code are not supported",
+	CodeTSEnumInitializer:               "Enum member must have initializer",
+	CodeDuplicateExport:                 "Duplicate export of '%s'",
+	CodeUndeclaredExport:                "Export '%s' is not defined",
+	CodeDuplicateImport:                 "Identifier '%s' has already been declared",
+	CodeImportOutsideModule:             "Cannot use import statement outside a module",
+	CodeExportOutsideModule:             "Cannot use export statement outside a module",
+	CodeUnterminatedString:              "Unterminated string constant",
+	CodeUnterminatedTemplate:            "Unterminated template literal",
+	CodeNumericSeparator:                "Numeric separators are not allowed here",
+}
+
+// code2hint holds the hints of the codes that have one.
+var code2hint = [...]string{
+	CodeWithInStrictMode:               "Refer to the properties through a variable holding the object instead",
+	CodeOctalInStrictMode:              "Write octal numbers with the 0o prefix",
+	CodeLeadingZeroInStrictMode:        "Remove the leading zero",
+	CodeOctalEscapeInStrictMode:        "Use a \\x or \\u escape sequence instead",
+	CodeNonOctalEscapeInStrictMode:     "Remove the backslash",
+	CodeUseStrictWithComplexParameters: "Move the directive out of the function or simplify its parameters",
+	CodeIllegalReturn:                  "A return statement may only appear inside a function",
+	CodeMissingCatchOrFinally:          "Add a catch or finally block",
+	CodeLexicalDeclarationInStatement:  "Wrap the declaration in a block",
+	CodeStrictFunctionInStatement:      "Wrap the declaration in a block",
+	CodeSloppyFunctionInStatement:      "Wrap the declaration in a block",
+	CodeFunctionInStatement:            "Wrap the declaration in a block",
+	CodeAsyncFunctionInStatement:       "Wrap the declaration in a block",
+	CodeGeneratorInStatement:           "Wrap the declaration in a block",
+	CodeLabelledFunctionInStatement:    "Wrap the declaration in a block",
+	CodeForInInitializer:               "Assign the variable before the loop",
+	CodeForAwaitWithoutOf:              "Use a for await...of loop",
+	CodeImportNotTopLevel:              "Use a dynamic import() expression instead",
+	CodeImportMetaOutsideModule:        "Parse the source as a module",
+	CodeTSEnumInitializer:              "Give the member a value",
+	CodeDuplicateExport:                "Rename one of the exports with 'as'",
+	CodeDuplicateImport:                "Rename one of the bindings",
+	CodeImportOutsideModule:            "Parse the source as a module",
+	CodeExportOutsideModule:            "Parse the source as a module",
+	CodeUnterminatedString:             "Add the closing quote",
+	CodeUnterminatedTemplate:           "Add the closing backtick",
+	CodeNumericSeparator:               "Put separators only between two digits",
+}
+
 // SyntaxError represents a parsing error with position information
 type SyntaxError struct {
 	Message string
 	Line    int
 	Column  int
 	Offset  int
+	// Length is the length in bytes of the source text the error is about,
+	// zero if it is unknown.
+	Length int
+
+	// Code classifies the error.
+	Code Code
+	// Related is another part of the source that explains the error, such as
+	// the opening brace of a block that is never closed. It is nil if there
+	// is none.
+	Related *Span
+	// Hint suggests how to fix the error. It is empty if there is none.
+	Hint string
+}
+
+// Span is a part of the source that a SyntaxError refers to. Its position is
+// counted like the one of the error.
+type Span struct {
+	Message string
+	Line    int
+	Column  int
+	Offset  int
+	Length  int
 }
 
 func (e *SyntaxError) Error() string {
 	return fmt.Sprintf("%s (line %d, column %d)", e.Message, e.Line, e.Column)
 }
 
-// positionToLineColumn converts a byte offset to line and column numbers
-func positionToLineColumn(src string, offset int) (line, col int) {
-	line = 1
-	col = 1
-	for i := 0; i < offset && i < len(src); i++ {
-		if src[i] == '\n' {
-			line++
-			col = 1
-		} else {
-			col++
-		}
+// error reports an error with code at the current token, formatting the
+// message of the code with args.
+func (p *parser) error(code Code, args ...any) *SyntaxError {
+	return p.errorAt(p.idx, code, args...)
+}
+
+// errorAt is like error but reports the error at idx instead of the current token.
+func (p *parser) errorAt(idx ast.Idx, code Code, args ...any) *SyntaxError {
+	p.errors.Add(p.source(), idx-p.base, fmt.Sprintf(code2message[code], args...))
+	e := p.errors[len(p.errors)-1]
+	e.Code = code
+	if idx == p.idx {
+		// The scanner is right after the current token.
+		e.Length = max(p.chrOffset-e.Offset, 0)
 	}
-	return line, col
+	if int(code) < len(code2hint) {
+		e.Hint = code2hint[code]
+	}
+	return e
 }
 
-// error ...
-func (p *parser) error(msg string, msgValues ...any) error {
-	msg = fmt.Sprintf(msg, msgValues...)
-	p.errors.Add(p.str, p.idx, msg)
-	return p.errors[len(p.errors)-1]
+// errorAtNode is like errorAt but reports the error at node, spanning all of it.
+func (p *parser) errorAtNode(node ast.Node, code Code, args ...any) *SyntaxError {
+	e := p.errorAt(node.Idx0(), code, args...)
+	e.Length = int(node.Idx1() - node.Idx0())
+	return e
+}
+
+// span returns the span of length bytes at idx.
+func (p *parser) span(idx ast.Idx, length int, msg string) *Span {
+	file := p.source()
+	pos := file.Position(idx-p.base, ast.ColumnBytes)
+	return &Span{
+		Message: msg,
+		Line:    pos.Line,
+		Column:  pos.Column + 1,
+		Offset:  file.Offset(idx - p.base),
+		Length:  length,
+	}
 }
 
 // errorUnexpected ...
-func (p *parser) errorUnexpected(chr rune) error {
+func (p *parser) errorUnexpected(chr rune) *SyntaxError {
 	if chr == -1 {
-		return p.error(errUnexpectedEndOfInput)
+		return p.error(CodeUnexpectedEnd)
 	}
-	return p.error(errUnexpectedToken, token.Illegal)
+	return p.error(CodeUnexpectedToken, token.Illegal)
 }
 
-func (p *parser) errorUnexpectedToken(tkn token.Token) error {
+func (p *parser) errorUnexpectedToken(tkn token.Token) *SyntaxError {
 	switch tkn {
 	case token.Eof:
-		return p.error(errUnexpectedEndOfInput)
+		return p.error(CodeUnexpectedEnd)
 	}
 	value := tkn.String()
 	switch tkn {
 	case token.Boolean, token.Null:
 		value = p.literal
 	case token.Identifier:
-		return p.error("Unexpected identifier")
+		return p.error(CodeUnexpectedIdentifier)
 	case token.Keyword:
 		// TODO Might be a future reserved word
-		return p.error("Unexpected reserved word")
+		return p.error(CodeUnexpectedReservedWord)
 	case token.EscapedReservedWord:
-		return p.error("Keyword must not contain escaped characters")
+		return p.error(CodeEscapedKeyword)
+	case token.Illegal:
+		// The scanner has already reported what is wrong with the token.
+		if n := len(p.errors); n > 0 && p.errors[n-1].Offset >= p.source().Offset(p.idx-p.base) {
+			return p.errors[n-1]
+		}
 	case token.Number:
-		return p.error("Unexpected number")
+		return p.error(CodeUnexpectedNumber)
 	case token.String:
-		return p.error("Unexpected string")
+		return p.error(CodeUnexpectedString)
 	}
-	return p.error(errUnexpectedToken, value)
+	return p.error(CodeUnexpectedToken, value)
 }
 
 // ErrorList is a list of *Errors.
 type ErrorList []*SyntaxError
 
-// Add adds an Error with given position and message to an ErrorList.
-func (e *ErrorList) Add(src string, idx ast.Idx, msg string) {
-	offset := int(idx) - 1 // Convert 1-based idx to 0-based offset
-	if offset < 0 {
-		offset = 0
-	}
-	line, col := positionToLineColumn(src, offset)
+// Add adds an Error with given position and message to an ErrorList. The
+// column of the error counts bytes from 1.
+func (e *ErrorList) Add(file *ast.SourceFile, idx ast.Idx, msg string) {
+	pos := file.Position(idx, ast.ColumnBytes)
 	*e = append(*e, &SyntaxError{
 		Message: msg,
-		Line:    line,
-		Column:  col,
-		Offset:  offset,
+		Line:    pos.Line,
+		Column:  pos.Column + 1,
+		Offset:  file.Offset(idx),
 	})
 }
 
@@ -1,9 +1,11 @@
 package parser
 
 import (
+	"math/big"
 	"strings"
 
 	"github.com/t14raptor/go-fast/ast"
+	"github.com/t14raptor/go-fast/regexp"
 	"github.com/t14raptor/go-fast/token"
 )
 
@@ -11,10 +13,7 @@ func (p *parser) parseIdentifier() *ast.Identifier {
 	literal := p.parsedLiteral
 	idx := p.idx
 	p.next()
-	return &ast.Identifier{
-		Idx:  idx,
-		Name: literal,
-	}
+	return p.makeIdent(idx, literal)
 }
 
 func (p *parser) parsePrimaryExpression() ast.Expr {
@@ -23,10 +22,7 @@ func (p *parser) parsePrimaryExpression() ast.Expr {
 	switch p.token {
 	case token.Identifier:
 		p.next()
-		return &ast.Identifier{
-			Idx:  idx,
-			Name: parsedLiteral,
-		}
+		return p.makeIdent(idx, parsedLiteral)
 	case token.Null:
 		p.next()
 		return &ast.NullLiteral{
@@ -41,32 +37,38 @@ func (p *parser) parsePrimaryExpression() ast.Expr {
 		case "false":
 			value = false
 		default:
-			p.error("Illegal boolean literal")
+			p.error(CodeIllegalBoolean)
 		}
 		return &ast.BooleanLiteral{
 			Idx:   idx,
 			Value: value,
 		}
 	case token.String:
+		p.checkStrictLiteral(idx, token.String, literal)
 		p.next()
-		return &ast.StringLiteral{
-			Idx:   idx,
-			Value: parsedLiteral,
-
-			Raw: &literal,
-		}
+		return p.makeString(idx, parsedLiteral, literal)
 	case token.Number:
+		p.checkStrictLiteral(idx, token.Number, literal)
 		p.next()
 		value, err := parseNumberLiteral(literal)
 		if err != nil {
-			p.error(err.Error())
+			p.error(CodeInvalidNumber)
 			value = 0
 		}
-		return &ast.NumberLiteral{
+		return p.makeNumber(idx, value, literal)
+	case token.BigInt:
+		p.next()
+		value, err := parseBigIntLiteral(literal)
+		if err != nil {
+			p.error(CodeInvalidNumber)
+			value = new(big.Int)
+		}
+		raw := literal
+		return &ast.BigIntLiteral{
 			Idx:   idx,
 			Value: value,
 
-			Raw: &literal,
+			Raw: &raw,
 		}
 	case token.Slash, token.QuotientAssign:
 		return p.parseRegExpLiteral()
@@ -91,6 +93,29 @@ func (p *parser) parsePrimaryExpression() ast.Expr {
 		return p.parseFunction(false, false, idx)
 	case token.Class:
 		return p.parseClass(false)
+	case token.At:
+		decorators := p.parseDecorators()
+		if p.token != token.Class {
+			p.errorAt(decorators[0].At, CodeDecoratorsNotValid)
+			return p.parsePrimaryExpression()
+		}
+		class := p.parseClass(false)
+		class.Decorators = decorators
+		return class
+	case token.Import:
+		return p.parseImportExpression()
+	case token.Less:
+		if p.opts.TypeScript {
+			if arrow := p.tryArrowFunction(idx, false); arrow != nil {
+				return arrow
+			}
+			if !p.opts.JSX {
+				return p.parseTypeAssertion()
+			}
+		}
+		if p.opts.JSX {
+			return p.parseJSXElement()
+		}
 	}
 
 	if p.isBindingId(p.token) {
@@ -117,15 +142,7 @@ func (p *parser) parseSuperProperty() ast.Expr {
 		idIdx := p.idx
 		parsedLiteral := p.parsedLiteral
 		p.next()
-		return &ast.MemberExpression{
-			Object: p.makeExpr(&ast.SuperExpression{
-				Idx: idx,
-			}),
-			Property: &ast.MemberProperty{Prop: &ast.Identifier{
-				Idx:  idIdx,
-				Name: parsedLiteral,
-			}},
-		}
+		return p.makeMember(p.makeExpr(&ast.SuperExpression{Idx: idx}), p.makeIdent(idIdx, parsedLiteral))
 	case token.LeftBracket:
 		return p.parseBracketMember(&ast.SuperExpression{
 			Idx: idx,
@@ -135,7 +152,7 @@ func (p *parser) parseSuperProperty() ast.Expr {
 			Idx: idx,
 		})
 	default:
-		p.error("'super' keyword unexpected here")
+		p.error(CodeUnexpectedSuper)
 		p.nextStatement()
 		return &ast.InvalidExpression{From: idx, To: p.idx}
 	}
@@ -152,7 +169,7 @@ func (p *parser) reinterpretSequenceAsArrowFuncParams(list ast.Expressions) ast.
 			}
 		}
 		if firstRestIdx != -1 {
-			p.error("Rest parameter must be last formal parameter")
+			p.error(CodeRestParameterNotLast)
 			return ast.ParameterList{}
 		}
 		params = append(params, p.reinterpretAsBinding(item.Expr))
@@ -173,6 +190,7 @@ func (p *parser) parseParenthesisedExpression() ast.Expr {
 	var list ast.Expressions
 	if p.token != token.RightParenthesis {
 		for {
+			p.limits.Count()
 			if p.token == token.Ellipsis {
 				start := p.idx
 				p.errorUnexpectedToken(token.Ellipsis)
@@ -195,7 +213,7 @@ func (p *parser) parseParenthesisedExpression() ast.Expr {
 			}
 		}
 	}
-	p.expect(token.RightParenthesis)
+	p.expectClosing(token.RightParenthesis, opening)
 	if len(list) == 1 && len(p.errors) == 0 {
 		return list[0].Expr
 	}
@@ -239,6 +257,9 @@ func (p *parser) parseRegExpLiteral() *ast.RegExpLiteral {
 	}
 
 	literal := p.str[offset:endOffset]
+	if err == "" {
+		p.validateRegExp(idx, pattern, flags)
+	}
 
 	return &ast.RegExpLiteral{
 		Idx:     idx,
@@ -248,16 +269,33 @@ func (p *parser) parseRegExpLiteral() *ast.RegExpLiteral {
 	}
 }
 
+// validateRegExp reports the early errors of the regular expression literal
+// at idx.
+func (p *parser) validateRegExp(idx ast.Idx, pattern, flags string) {
+	f, err := regexp.ParseFlags(flags)
+	if err != nil {
+		p.errorAt(idx+ast.Idx(len(pattern)+2+err.(*regexp.Error).Offset), CodeInvalidRegExpFlags)
+		return
+	}
+	if _, err := regexp.ParseTracked(pattern, f, &p.limits); err != nil {
+		err := err.(*regexp.Error)
+		p.errorAt(idx+ast.Idx(1+err.Offset), CodeInvalidRegExp, pattern, err.Message)
+	}
+}
+
 func (p *parser) isBindingId(tok token.Token) bool {
 	if tok == token.Identifier {
 		return true
 	}
 
 	if tok == token.Await {
-		return !p.scope.allowAwait
+		return !p.scope.allowAwait && !p.isModule()
 	}
 	if tok == token.Yield {
-		return !p.scope.allowYield
+		return !p.scope.allowYield && !p.scope.strict
+	}
+	if p.scope.strict && (tok == token.Let || tok == token.Static) {
+		return false
 	}
 
 	if token.UnreservedWord(tok) {
@@ -273,13 +311,12 @@ func (p *parser) tokenToBindingId() {
 }
 
 func (p *parser) parseBindingTarget() (target ast.Target) {
+	p.limits.Enter()
+	defer p.limits.Leave()
 	p.tokenToBindingId()
 	switch p.token {
 	case token.Identifier:
-		target = &ast.Identifier{
-			Name: p.parsedLiteral,
-			Idx:  p.idx,
-		}
+		target = p.makeIdent(p.idx, p.parsedLiteral)
 		p.next()
 	case token.LeftBracket:
 		target = p.parseArrayBindingPattern()
@@ -291,12 +328,21 @@ func (p *parser) parseBindingTarget() (target ast.Target) {
 		target = &ast.InvalidExpression{From: idx, To: p.idx}
 	}
 
+	// Parameters are checked by checkParameters once the strictness of the
+	// function body is known.
+	if !p.scope.inFuncParams {
+		p.checkStrictBinding(target)
+	}
 	return
 }
 
 func (p *parser) parseVariableDeclaration(declarationList *ast.VariableDeclarators) ast.VariableDeclarator {
 	node := &ast.VariableDeclarator{
-		Target: &ast.BindingTarget{Target: p.parseBindingTarget()},
+		Target: p.makeTarget(p.parseBindingTarget()),
+	}
+
+	if p.opts.TypeScript {
+		p.skipBindingAnnotation()
 	}
 
 	if p.token == token.Assign {
@@ -330,44 +376,40 @@ func (p *parser) parseObjectPropertyKey() (string, string, ast.Expr, token.Token
 		return "", "", expr, token.Illegal
 	}
 	idx, tkn, literal, parsedLiteral := p.idx, p.token, p.literal, p.parsedLiteral
+	p.checkStrictLiteral(idx, tkn, literal)
 	var value ast.Expr
 	p.next()
 	switch tkn {
 	case token.Identifier, token.String, token.Keyword, token.EscapedReservedWord:
-		value = &ast.StringLiteral{
-			Idx:   idx,
-			Value: parsedLiteral,
-
-			Raw: &literal,
-		}
+		value = p.makeString(idx, parsedLiteral, literal)
 	case token.Number:
 		num, err := parseNumberLiteral(literal)
 		if err != nil {
-			p.error(err.Error())
+			p.error(CodeInvalidNumber)
+		} else {
+			value = p.makeNumber(idx, num, literal)
+		}
+	case token.BigInt:
+		num, err := parseBigIntLiteral(literal)
+		if err != nil {
+			p.error(CodeInvalidNumber)
 		} else {
-			value = &ast.NumberLiteral{
+			raw := literal
+			value = &ast.BigIntLiteral{
 				Idx:   idx,
 				Value: num,
 
-				Raw: &literal,
+				Raw: &raw,
 			}
 		}
 	case token.PrivateIdentifier:
 		value = &ast.PrivateIdentifier{
-			Identifier: &ast.Identifier{
-				Idx:  idx,
-				Name: parsedLiteral,
-			},
+			Identifier: p.makeIdent(idx, parsedLiteral),
 		}
 	default:
 		// null, false, class, etc.
 		if token.ID(tkn) {
-			value = &ast.StringLiteral{
-				Idx:   idx,
-				Value: literal,
-
-				Raw: &literal,
-			}
+			value = p.makeString(idx, literal, literal)
 		} else {
 			p.errorUnexpectedToken(tkn)
 		}
@@ -402,7 +444,7 @@ func (p *parser) parseObjectProperty() ast.Prop {
 			}
 		}
 		switch {
-		case p.token == token.LeftParenthesis:
+		case p.token == token.LeftParenthesis || p.opts.TypeScript && p.token == token.Less:
 			return &ast.PropertyKeyed{
 				Key:      p.makeExpr(value),
 				Kind:     ast.PropertyKindMethod,
@@ -418,13 +460,13 @@ func (p *parser) parseObjectProperty() ast.Prop {
 					p.next()
 					initializer = p.parseAssignmentExpression()
 				}
-				return &ast.PropertyShort{
-					Name: &ast.Identifier{
-						Name: parsedLiteral,
-						Idx:  value.Idx0(),
-					},
-					Initializer: p.makeExpr(initializer),
+				prop := &ast.PropertyShort{
+					Name: p.makeIdent(value.Idx0(), parsedLiteral),
 				}
+				if initializer != nil {
+					prop.Initializer = p.makeExpr(initializer)
+				}
+				return prop
 			} else {
 				p.errorUnexpectedToken(p.token)
 			}
@@ -477,14 +519,15 @@ func (p *parser) parseMethodDefinition(keyStartIdx ast.Idx, kind ast.PropertyKin
 		}()
 	}
 	parameterList := p.parseFunctionParameterList()
+	properties := p.paramProperties
 	switch kind {
 	case ast.PropertyKindGet:
 		if len(parameterList.List) > 0 || parameterList.Rest != nil {
-			p.error("Getter must not have any formal parameters.")
+			p.error(CodeGetterParameters)
 		}
 	case ast.PropertyKindSet:
 		if len(parameterList.List) != 1 || parameterList.Rest != nil {
-			p.error("Setter must have exactly one formal parameter.")
+			p.error(CodeSetterParameters)
 		}
 	}
 	node := &ast.FunctionLiteral{
@@ -493,7 +536,11 @@ func (p *parser) parseMethodDefinition(keyStartIdx ast.Idx, kind ast.PropertyKin
 		Generator:     generator,
 		Async:         async,
 	}
-	node.Body = p.parseFunctionBlock(async, async, generator)
+	node.Body, node.Strict = p.parseFunctionBlock(async, async, generator, &node.ParameterList)
+	p.checkParameters(&node.ParameterList, node.Strict, true)
+	if len(properties) > 0 {
+		addParameterProperties(node.Body, properties)
+	}
 	return node
 }
 
@@ -506,12 +553,12 @@ func (p *parser) parseObjectLiteral() *ast.ObjectLiteral {
 			value = append(value, ast.Property{Prop: property})
 		}
 		if p.token != token.RightBrace {
-			p.expect(token.Comma)
+			p.expectComma(token.RightBrace, idx0)
 		} else {
 			break
 		}
 	}
-	idx1 := p.expect(token.RightBrace)
+	idx1 := p.expectClosing(token.RightBrace, idx0)
 
 	return &ast.ObjectLiteral{
 		LeftBrace:  idx0,
@@ -524,6 +571,7 @@ func (p *parser) parseArrayLiteral() *ast.ArrayLiteral {
 	idx0 := p.expect(token.LeftBracket)
 	var value ast.Expressions
 	for p.token != token.RightBracket && p.token != token.Eof {
+		p.limits.Count()
 		if p.token == token.Comma {
 			p.next()
 			value = append(value, ast.Expression{})
@@ -538,10 +586,10 @@ func (p *parser) parseArrayLiteral() *ast.ArrayLiteral {
 			value = append(value, ast.Expression{Expr: p.parseAssignmentExpression()})
 		}
 		if p.token != token.RightBracket {
-			p.expect(token.Comma)
+			p.expectComma(token.RightBracket, idx0)
 		}
 	}
-	idx1 := p.expect(token.RightBracket)
+	idx1 := p.expectClosing(token.RightBracket, idx0)
 
 	return &ast.ArrayLiteral{
 		LeftBracket:  idx0,
@@ -555,20 +603,14 @@ func (p *parser) parseTemplateLiteral(tagged bool) *ast.TemplateLiteral {
 		OpenQuote: p.idx,
 	}
 	for {
-		start := p.offset
-		literal, parsed, finished, parseErr, err := p.parseTemplateCharacters()
-		if err != "" {
-			p.error(err)
-		}
+		start := p.chrOffset
+		literal, parsed, finished, parseErr, _ := p.parseTemplateCharacters(tagged)
 		res.Elements = append(res.Elements, ast.TemplateElement{
 			Idx:     p.idxOf(start),
 			Literal: literal,
 			Parsed:  parsed,
 			Valid:   parseErr == "",
 		})
-		if !tagged && parseErr != "" {
-			p.error(parseErr)
-		}
 		end := p.chrOffset - 1
 		p.next()
 		if finished {
@@ -576,6 +618,7 @@ func (p *parser) parseTemplateLiteral(tagged bool) *ast.TemplateLiteral {
 			break
 		}
 		expr := p.parseExpression()
+		p.limits.Count()
 		res.Expressions = append(res.Expressions, ast.Expression{Expr: expr})
 		if p.token != token.RightBrace {
 			p.errorUnexpectedToken(p.token)
@@ -602,24 +645,25 @@ func (p *parser) parseArgumentList() (argumentList ast.Expressions, idx0, idx1 a
 		} else {
 			item = p.parseAssignmentExpression()
 		}
+		p.limits.Count()
 		argumentList = append(argumentList, ast.Expression{Expr: item})
 		if p.token != token.Comma {
 			break
 		}
 		p.next()
 	}
-	idx1 = p.expect(token.RightParenthesis)
+	idx1 = p.expectClosing(token.RightParenthesis, idx0)
 	return
 }
 
 func (p *parser) parseCallExpression(left ast.Expr) ast.Expr {
 	argumentList, idx0, idx1 := p.parseArgumentList()
-	return &ast.CallExpression{
-		Callee:           p.makeExpr(left),
-		LeftParenthesis:  idx0,
-		ArgumentList:     argumentList,
-		RightParenthesis: idx1,
-	}
+	call := p.arenas.calls.make()
+	call.Callee = p.makeExpr(left)
+	call.LeftParenthesis = idx0
+	call.ArgumentList = argumentList
+	call.RightParenthesis = idx1
+	return call
 }
 
 func (p *parser) parseDotMember(left ast.Expr) ast.Expr {
@@ -634,10 +678,7 @@ func (p *parser) parseDotMember(left ast.Expr) ast.Expr {
 		return &ast.PrivateDotExpression{
 			Left: p.makeExpr(left),
 			Identifier: &ast.PrivateIdentifier{
-				Identifier: &ast.Identifier{
-					Idx:  idx,
-					Name: literal,
-				},
+				Identifier: p.makeIdent(idx, literal),
 			},
 		}
 	}
@@ -650,33 +691,21 @@ func (p *parser) parseDotMember(left ast.Expr) ast.Expr {
 
 	p.next()
 
-	return &ast.MemberExpression{
-		Object: p.makeExpr(left),
-		Property: &ast.MemberProperty{
-			Prop: &ast.Identifier{
-				Idx:  idx,
-				Name: literal,
-			},
-		},
-	}
+	return p.makeMember(p.makeExpr(left), p.makeIdent(idx, literal))
 }
 
 func (p *parser) parseBracketMember(left ast.Expr) *ast.MemberExpression {
 	p.expect(token.LeftBracket)
 	member := p.parseExpression()
 	rightBracket := p.expect(token.RightBracket)
-	return &ast.MemberExpression{
-		Object: p.makeExpr(left),
-		Property: &ast.MemberProperty{
-			Prop: &ast.ComputedProperty{
-				Expr: p.makeExpr(member),
-			},
-		},
-		RightBracket: rightBracket,
-	}
+	node := p.makeMember(p.makeExpr(left), &ast.ComputedProperty{Expr: p.makeExpr(member)})
+	node.RightBracket = rightBracket
+	return node
 }
 
 func (p *parser) parseNewExpression() ast.Expr {
+	p.limits.Enter()
+	defer p.limits.Leave()
 	idx := p.expect(token.New)
 	if p.token == token.Period {
 		p.next()
@@ -687,6 +716,7 @@ func (p *parser) parseNewExpression() ast.Expr {
 					Idx:  idx,
 				},
 				Property: p.parseIdentifier(),
+				Idx:      idx,
 			}
 		}
 		p.errorUnexpectedToken(token.Identifier)
@@ -696,6 +726,12 @@ func (p *parser) parseNewExpression() ast.Expr {
 		bad.From = idx
 		return bad
 	}
+	if p.opts.TypeScript && p.token == token.Less {
+		p.tryTypeArguments()
+	}
+	if _, ok := callee.(*ast.ImportExpression); ok {
+		p.error(CodeNewImport)
+	}
 	node := &ast.NewExpression{
 		New:    idx,
 		Callee: p.makeExpr(callee),
@@ -758,9 +794,20 @@ L:
 			left = p.parseBracketMember(left)
 		case token.LeftParenthesis:
 			left = p.parseCallExpression(left)
+		case token.Less:
+			if !p.opts.TypeScript || !p.tryTypeArguments() {
+				break L
+			}
+		case token.Not:
+			// A non-null assertion.
+			if !p.opts.TypeScript || p.implicitSemicolon {
+				break L
+			}
+			p.insertSemicolon = true
+			p.next()
 		case token.Backtick:
 			if optionalChain {
-				p.error("Invalid template literal on optional chain")
+				p.error(CodeTemplateInOptionalChain)
 				p.nextStatement()
 				return &ast.InvalidExpression{From: start, To: p.idx}
 			}
@@ -793,10 +840,12 @@ func (p *parser) parseUpdateExpression() ast.Expr {
 		idx := p.idx
 		p.next()
 		operand := p.parseUnaryExpression()
-		switch operand.(type) {
-		case *ast.Identifier, *ast.PrivateDotExpression, *ast.MemberExpression:
+		switch operand := operand.(type) {
+		case *ast.Identifier:
+			p.checkStrictIdentifier(operand)
+		case *ast.PrivateDotExpression, *ast.MemberExpression:
 		default:
-			p.error("Invalid left-hand side in assignment")
+			p.error(CodeInvalidAssignmentTarget)
 			p.nextStatement()
 			return &ast.InvalidExpression{From: idx, To: p.idx}
 		}
@@ -815,10 +864,12 @@ func (p *parser) parseUpdateExpression() ast.Expr {
 			tkn := p.token
 			idx := p.idx
 			p.next()
-			switch operand.(type) {
-			case *ast.Identifier, *ast.PrivateDotExpression, *ast.MemberExpression:
+			switch operand := operand.(type) {
+			case *ast.Identifier:
+				p.checkStrictIdentifier(operand)
+			case *ast.PrivateDotExpression, *ast.MemberExpression:
 			default:
-				p.error("Invalid left-hand side in assignment")
+				p.error(CodeInvalidAssignmentTarget)
 				p.nextStatement()
 				return &ast.InvalidExpression{From: idx, To: p.idx}
 			}
@@ -834,6 +885,8 @@ func (p *parser) parseUpdateExpression() ast.Expr {
 }
 
 func (p *parser) parseUnaryExpression() ast.Expr {
+	p.limits.Enter()
+	defer p.limits.Leave()
 	switch p.token {
 	case token.Plus, token.Minus, token.Not, token.BitwiseNot:
 		fallthrough
@@ -841,10 +894,14 @@ func (p *parser) parseUnaryExpression() ast.Expr {
 		tkn := p.token
 		idx := p.idx
 		p.next()
+		operand := p.parseUnaryExpression()
+		if _, ok := operand.(*ast.Identifier); ok && tkn == token.Delete && p.scope.strict {
+			p.errorAt(idx, CodeDeleteIdentifierInStrictMode)
+		}
 		return &ast.UnaryExpression{
 			Operator: tkn,
 			Idx:      idx,
-			Operand:  p.makeExpr(p.parseUnaryExpression()),
+			Operand:  p.makeExpr(operand),
 		}
 	case token.Await:
 		if p.scope.allowAwait {
@@ -858,7 +915,7 @@ func (p *parser) parseUnaryExpression() ast.Expr {
 				}
 			}
 			if p.scope.inFuncParams {
-				p.error("Illegal await-expression in formal parameters of async function")
+				p.error(CodeAwaitInParameters)
 			}
 			return &ast.AwaitExpression{
 				Await:    idx,
@@ -878,7 +935,7 @@ func (p *parser) parseExponentiationExpression() ast.Expr {
 	if p.token == token.Exponent {
 		if !parenthesis {
 			if _, isUnary := left.(*ast.UnaryExpression); isUnary {
-				p.error("Unary operator used immediately before exponentiation expression. Parenthesis must be used to disambiguate operator precedence")
+				p.error(CodeUnaryBeforeExponent)
 			}
 		}
 		for {
@@ -950,10 +1007,7 @@ func (p *parser) parseShiftExpression() ast.Expr {
 func (p *parser) parseRelationalExpression() ast.Expr {
 	if p.scope.allowIn && p.token == token.PrivateIdentifier {
 		left := &ast.PrivateIdentifier{
-			Identifier: &ast.Identifier{
-				Idx:  p.idx,
-				Name: p.parsedLiteral,
-			},
+			Identifier: p.makeIdent(p.idx, p.parsedLiteral),
 		}
 		p.next()
 		if p.token == token.In {
@@ -967,6 +1021,10 @@ func (p *parser) parseRelationalExpression() ast.Expr {
 		return left
 	}
 	left := p.parseShiftExpression()
+	for p.opts.TypeScript && (p.isContextual("as") || p.isContextual("satisfies")) && !p.implicitSemicolon {
+		p.next()
+		p.skipType()
+	}
 
 	allowIn := p.scope.allowIn
 	p.scope.allowIn = true
@@ -1133,7 +1191,7 @@ func (p *parser) parseLogicalOrExpression() ast.Expr {
 	}
 
 mixed:
-	p.error("Logical expressions and coalesce expressions cannot be mixed. Wrap either by parentheses")
+	p.error(CodeMixedCoalesce)
 	return left
 }
 
@@ -1142,10 +1200,11 @@ func (p *parser) parseConditionalExpression() ast.Expr {
 
 	if p.token == token.QuestionMark {
 		p.next()
-		allowIn := p.scope.allowIn
+		allowIn, start := p.scope.allowIn, p.consequent
 		p.scope.allowIn = true
+		p.consequent = p.idx
 		consequent := p.parseAssignmentExpression()
-		p.scope.allowIn = allowIn
+		p.scope.allowIn, p.consequent = allowIn, start
 		p.expect(token.Colon)
 		return &ast.ConditionalExpression{
 			Test:       p.makeExpr(left),
@@ -1164,7 +1223,8 @@ func (p *parser) parseArrowFunction(start ast.Idx, paramList ast.ParameterList,
 		ParameterList: paramList,
 		Async:         async,
 	}
-	node.Body = p.parseArrowFunctionBody(async)
+	node.Body, node.Strict = p.parseArrowFunctionBody(async, &node.ParameterList)
+	p.checkParameters(&node.ParameterList, node.Strict, true)
 	return node
 }
 
@@ -1191,7 +1251,7 @@ func (p *parser) parseSingleArgArrowFunction(start ast.Idx, async bool) ast.Expr
 		Opening: id.Idx,
 		Closing: id.Idx1(),
 		List: ast.VariableDeclarators{{
-			Target: &ast.BindingTarget{Target: id},
+			Target: p.makeTarget(id),
 		}},
 	}
 
@@ -1199,12 +1259,19 @@ func (p *parser) parseSingleArgArrowFunction(start ast.Idx, async bool) ast.Expr
 }
 
 func (p *parser) parseAssignmentExpression() ast.Expr {
+	p.limits.Enter()
+	defer p.limits.Leave()
 	start := p.idx
 	parenthesis := false
 	async := false
 	var state parserState
 	switch p.token {
 	case token.LeftParenthesis:
+		if p.opts.TypeScript {
+			if arrow := p.tryArrowFunction(start, false); arrow != nil {
+				return arrow
+			}
+		}
 		p.mark(&state)
 		parenthesis = true
 	case token.Async:
@@ -1213,7 +1280,12 @@ func (p *parser) parseAssignmentExpression() ast.Expr {
 			// async x => ...
 			p.next()
 			return p.parseSingleArgArrowFunction(start, true)
-		} else if tok == token.LeftParenthesis {
+		} else if tok == token.LeftParenthesis || p.opts.TypeScript && tok == token.Less {
+			if p.opts.TypeScript {
+				if arrow := p.tryArrowFunction(start, true); arrow != nil {
+					return arrow
+				}
+			}
 			p.mark(&state)
 			async = true
 		}
@@ -1254,6 +1326,12 @@ func (p *parser) parseAssignmentExpression() ast.Expr {
 		operator = token.ShiftRight
 	case token.UnsignedShiftRightAssign:
 		operator = token.UnsignedShiftRight
+	case token.LogicalAndAssign:
+		operator = token.LogicalAnd
+	case token.LogicalOrAssign:
+		operator = token.LogicalOr
+	case token.CoalesceAssign:
+		operator = token.Coalesce
 	case token.Arrow:
 		var paramList *ast.ParameterList
 		if id, ok := left.(*ast.Identifier); ok {
@@ -1261,7 +1339,7 @@ func (p *parser) parseAssignmentExpression() ast.Expr {
 				Opening: id.Idx,
 				Closing: id.Idx1() - 1,
 				List: ast.VariableDeclarators{{
-					Target: &ast.BindingTarget{Target: id},
+					Target: p.makeTarget(id),
 				}},
 			}
 		} else if parenthesis {
@@ -1289,7 +1367,7 @@ func (p *parser) parseAssignmentExpression() ast.Expr {
 			}
 		}
 		if paramList == nil {
-			p.error("Malformed arrow function parameter list")
+			p.error(CodeMalformedArrowParameters)
 			return &ast.InvalidExpression{From: left.Idx0(), To: left.Idx1()}
 		}
 		return p.parseArrowFunction(start, *paramList, async)
@@ -1300,7 +1378,10 @@ func (p *parser) parseAssignmentExpression() ast.Expr {
 		p.next()
 		ok := false
 		switch l := left.(type) {
-		case *ast.Identifier, *ast.PrivateDotExpression, *ast.MemberExpression:
+		case *ast.Identifier:
+			p.checkStrictIdentifier(l)
+			ok = true
+		case *ast.PrivateDotExpression, *ast.MemberExpression:
 			ok = true
 		case *ast.ArrayLiteral:
 			if !parenthesis && operator == token.Assign {
@@ -1320,7 +1401,7 @@ func (p *parser) parseAssignmentExpression() ast.Expr {
 				Right:    p.makeExpr(p.parseAssignmentExpression()),
 			}
 		}
-		p.error("Invalid left-hand side in assignment")
+		p.error(CodeInvalidAssignmentTarget)
 		p.nextStatement()
 		return &ast.InvalidExpression{From: idx, To: p.idx}
 	}
@@ -1332,7 +1413,7 @@ func (p *parser) parseYieldExpression() *ast.YieldExpression {
 	idx := p.expect(token.Yield)
 
 	if p.scope.inFuncParams {
-		p.error("Yield expression not allowed in formal parameter")
+		p.error(CodeYieldInParameters)
 	}
 
 	node := &ast.YieldExpression{
@@ -1349,10 +1430,10 @@ func (p *parser) parseYieldExpression() *ast.YieldExpression {
 		p.mark(&state)
 		expr := p.parseAssignmentExpression()
 		if _, bad := expr.(*ast.InvalidExpression); bad {
-			expr = nil
 			p.restore(&state)
+		} else {
+			node.Argument = p.makeExpr(expr)
 		}
-		node.Argument = p.makeExpr(expr)
 	}
 
 	return node
@@ -1368,6 +1449,7 @@ func (p *parser) parseExpression() ast.Expr {
 				break
 			}
 			p.next()
+			p.limits.Count()
 			sequence = append(sequence, ast.Expression{Expr: p.parseAssignmentExpression()})
 		}
 		return &ast.SequenceExpression{
@@ -1379,8 +1461,8 @@ func (p *parser) parseExpression() ast.Expr {
 }
 
 func (p *parser) checkComma(from, to ast.Idx) {
-	if pos := strings.IndexByte(p.str[int(from)-1:int(to)-1], ','); pos >= 0 {
-		p.error("Comma is not allowed here")
+	if pos := strings.IndexByte(p.str[p.offsetOf(from):p.offsetOf(to)], ','); pos >= 0 {
+		p.error(CodeCommaNotAllowed)
 	}
 }
 
@@ -1390,7 +1472,7 @@ func (p *parser) reinterpretAsArrayAssignmentPattern(left *ast.ArrayLiteral) ast
 	for i, item := range value {
 		if spread, ok := item.Expr.(*ast.SpreadElement); ok {
 			if i != len(value)-1 {
-				p.error("Rest element must be last element")
+				p.error(CodeRestElementNotLast)
 				return &ast.InvalidExpression{From: left.Idx0(), To: left.Idx1()}
 			}
 			p.checkComma(spread.Idx1(), left.RightBracket)
@@ -1424,7 +1506,7 @@ func (p *parser) reinterpretAsArrayBindingPattern(left *ast.ArrayLiteral) ast.Ta
 	for i, item := range value {
 		if spread, ok := item.Expr.(*ast.SpreadElement); ok {
 			if i != len(value)-1 {
-				p.error("Rest element must be last element")
+				p.error(CodeRestElementNotLast)
 				return &ast.InvalidExpression{From: left.Idx0(), To: left.Idx1()}
 			}
 			p.checkComma(spread.Idx1(), left.RightBracket)
@@ -1477,7 +1559,7 @@ func (p *parser) reinterpretAsObjectBindingPattern(expr *ast.ObjectLiteral) ast.
 			ok = true
 		case *ast.SpreadElement:
 			if i != len(expr.Value)-1 {
-				p.error("Rest element must be last element")
+				p.error(CodeRestElementNotLast)
 				return &ast.InvalidExpression{From: expr.Idx0(), To: expr.Idx1()}
 			}
 			// TODO make sure there is no trailing comma
@@ -1486,7 +1568,7 @@ func (p *parser) reinterpretAsObjectBindingPattern(expr *ast.ObjectLiteral) ast.
 			ok = true
 		}
 		if !ok {
-			p.error("Invalid destructuring binding target")
+			p.error(CodeInvalidBindingTarget)
 			return &ast.InvalidExpression{From: expr.Idx0(), To: expr.Idx1()}
 		}
 	}
@@ -1510,19 +1592,23 @@ func (p *parser) reinterpretAsObjectAssignmentPattern(l *ast.ObjectLiteral) ast.
 				ok = true
 			}
 		case *ast.PropertyShort:
+			p.checkStrictIdentifier(prop.Name)
 			ok = true
 		case *ast.SpreadElement:
 			if i != len(l.Value)-1 {
-				p.error("Rest element must be last element")
+				p.error(CodeRestElementNotLast)
 				return &ast.InvalidExpression{From: l.Idx0(), To: l.Idx1()}
 			}
 			// TODO make sure there is no trailing comma
 			rest = prop.Expression.Expr
+			if ident, ok := rest.(*ast.Identifier); ok {
+				p.checkStrictIdentifier(ident)
+			}
 			value = value[:i]
 			ok = true
 		}
 		if !ok {
-			p.error("Invalid destructuring assignment target")
+			p.error(CodeInvalidDestructuringTarget)
 			return &ast.InvalidExpression{From: l.Idx0(), To: l.Idx1()}
 		}
 	}
@@ -1541,7 +1627,7 @@ func (p *parser) reinterpretAsAssignmentElement(expr ast.Expr) ast.Expr {
 			expr.Left = p.makeExpr(p.reinterpretAsDestructAssignTarget(expr.Left.Expr))
 			return expr
 		} else {
-			p.error("Invalid destructuring assignment target")
+			p.error(CodeInvalidDestructuringTarget)
 			return &ast.InvalidExpression{From: expr.Idx0(), To: expr.Idx1()}
 		}
 	default:
@@ -1556,7 +1642,7 @@ func (p *parser) reinterpretAsBindingElement(expr ast.Expr) ast.Expr {
 			expr.Left = p.makeExpr(p.reinterpretAsDestructBindingTarget(expr.Left.Expr))
 			return expr
 		} else {
-			p.error("Invalid destructuring assignment target")
+			p.error(CodeInvalidDestructuringTarget)
 			return &ast.InvalidExpression{From: expr.Idx0(), To: expr.Idx1()}
 		}
 	default:
@@ -1569,18 +1655,18 @@ func (p *parser) reinterpretAsBinding(expr ast.Expr) ast.VariableDeclarator {
 	case *ast.AssignExpression:
 		if expr.Operator == token.Assign {
 			return ast.VariableDeclarator{
-				Target:      &ast.BindingTarget{Target: p.reinterpretAsDestructBindingTarget(expr.Left.Expr)},
+				Target:      p.makeTarget(p.reinterpretAsDestructBindingTarget(expr.Left.Expr)),
 				Initializer: expr.Right,
 			}
 		} else {
-			p.error("Invalid destructuring assignment target")
+			p.error(CodeInvalidDestructuringTarget)
 			return ast.VariableDeclarator{
 				Target: &ast.BindingTarget{Target: &ast.InvalidExpression{From: expr.Idx0(), To: expr.Idx1()}},
 			}
 		}
 	default:
 		return ast.VariableDeclarator{
-			Target: &ast.BindingTarget{Target: p.reinterpretAsDestructBindingTarget(expr)},
+			Target: p.makeTarget(p.reinterpretAsDestructBindingTarget(expr)),
 		}
 	}
 }
@@ -1593,10 +1679,13 @@ func (p *parser) reinterpretAsDestructAssignTarget(item ast.Expr) ast.Expr {
 		return p.reinterpretAsArrayAssignmentPattern(item)
 	case *ast.ObjectLiteral:
 		return p.reinterpretAsObjectAssignmentPattern(item)
-	case ast.Pattern, *ast.Identifier, *ast.PrivateDotExpression, *ast.MemberExpression:
+	case *ast.Identifier:
+		p.checkStrictIdentifier(item)
+		return item
+	case ast.Pattern, *ast.PrivateDotExpression, *ast.MemberExpression:
 		return item
 	}
-	p.error("Invalid destructuring assignment target")
+	p.error(CodeInvalidDestructuringTarget)
 	return &ast.InvalidExpression{From: item.Idx0(), To: item.Idx1()}
 }
 
@@ -1617,7 +1706,7 @@ func (p *parser) reinterpretAsDestructBindingTarget(item ast.Expr) ast.Target {
 			return item
 		}
 	}
-	p.error("Invalid destructuring binding target")
+	p.error(CodeInvalidBindingTarget)
 	return &ast.InvalidExpression{From: item.Idx0(), To: item.Idx1()}
 }
 
@@ -1625,6 +1714,6 @@ func (p *parser) reinterpretAsBindingRestElement(expr ast.Expr) ast.Expr {
 	if _, ok := expr.(*ast.Identifier); ok {
 		return expr
 	}
-	p.error("Invalid binding rest")
+	p.error(CodeInvalidBindingRest)
 	return &ast.InvalidExpression{From: expr.Idx0(), To: expr.Idx1()}
 }
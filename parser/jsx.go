@@ -0,0 +1,364 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/t14raptor/go-fast/ast"
+	"github.com/t14raptor/go-fast/token"
+)
+
+// parseJSXElement parses a JSX element or fragment, starting at its opening <.
+func (p *parser) parseJSXElement() ast.Expr {
+	start := p.idx
+	p.nextJSX()
+	expr := p.parseJSXElementAt(start)
+	// The final > may be followed by anything an expression can be followed by.
+	p.insertSemicolon = true
+	p.next()
+	return expr
+}
+
+// parseJSXElementAt parses a JSX element or fragment whose opening < at start
+// has been consumed already. The scanner is left right after the final >, as
+// it depends on the context how to scan what follows.
+func (p *parser) parseJSXElementAt(start ast.Idx) ast.Expr {
+	p.limits.Enter()
+	defer p.limits.Leave()
+	if p.token == token.Greater {
+		frag := &ast.JSXFragment{
+			OpeningLessThan:    start,
+			OpeningGreaterThan: p.idx,
+		}
+		frag.Children, frag.ClosingLessThan = p.parseJSXChildren()
+		p.nextJSX()
+		if p.token != token.Greater {
+			e := p.error(CodeJSXFragmentMismatch)
+			e.Related = p.span(start, len("<>"), "The opening fragment")
+		}
+		frag.ClosingGreaterThan = p.idx
+		return frag
+	}
+
+	opening := &ast.JSXOpeningElement{
+		LessThan: start,
+		Name:     p.parseJSXName(),
+	}
+	opening.Attributes = p.parseJSXAttributes()
+	if p.token == token.Slash {
+		opening.SelfClosing = true
+		p.nextJSX()
+	}
+	opening.GreaterThan = p.expectJSX(token.Greater)
+	elem := &ast.JSXElement{Opening: opening}
+	if opening.SelfClosing {
+		return elem
+	}
+
+	var lessThan ast.Idx
+	elem.Children, lessThan = p.parseJSXChildren()
+	p.nextJSX()
+	elem.Closing = &ast.JSXClosingElement{
+		LessThan: lessThan,
+		Name:     &ast.JSXName{Idx: p.idx},
+	}
+	if p.token != token.Greater {
+		elem.Closing.Name = p.parseJSXName()
+	}
+	if elem.Closing.Name.Name != opening.Name.Name {
+		e := p.errorAt(elem.Closing.Name.Idx, CodeJSXTagMismatch, opening.Name.Name)
+		e.Related = p.span(opening.Name.Idx, len(opening.Name.Name), "The opening tag")
+	}
+	elem.Closing.GreaterThan = p.expectJSX(token.Greater)
+	return elem
+}
+
+// expectJSX is like expect but does not scan past the expected token.
+func (p *parser) expectJSX(value token.Token) ast.Idx {
+	if p.token != value {
+		p.errorUnexpectedToken(p.token)
+	}
+	return p.idx
+}
+
+func (p *parser) parseJSXName() *ast.JSXName {
+	name := &ast.JSXName{Idx: p.idx, Name: p.literal}
+	if p.token != token.Identifier {
+		p.errorUnexpectedToken(p.token)
+		return name
+	}
+	p.nextJSX()
+	if p.token == token.Colon {
+		p.nextJSX()
+		if p.token != token.Identifier {
+			p.errorUnexpectedToken(p.token)
+			return name
+		}
+		name.Name += ":" + p.literal
+		p.nextJSX()
+		return name
+	}
+	for p.token == token.Period {
+		p.nextJSX()
+		if p.token != token.Identifier {
+			p.errorUnexpectedToken(p.token)
+			return name
+		}
+		name.Name += "." + p.literal
+		p.nextJSX()
+	}
+	return name
+}
+
+func (p *parser) parseJSXAttributes() (attributes ast.JSXAttributes) {
+	for {
+		switch p.token {
+		case token.Identifier:
+			attr := &ast.JSXAttribute{Name: p.parseJSXName()}
+			if p.token == token.Assign {
+				p.nextJSX()
+				attr.Value = p.makeExpr(p.parseJSXAttributeValue())
+				p.nextJSX()
+			}
+			attributes = append(attributes, ast.JSXAttributeItem{Attribute: attr})
+		case token.LeftBrace:
+			attr := &ast.JSXSpreadAttribute{LeftBrace: p.idx}
+			p.next()
+			p.expect(token.Ellipsis)
+			attr.Argument = p.makeExpr(p.parseAssignmentExpression())
+			attr.RightBrace = p.expectJSX(token.RightBrace)
+			p.nextJSX()
+			attributes = append(attributes, ast.JSXAttributeItem{Attribute: attr})
+		case token.Greater, token.Slash:
+			return attributes
+		default:
+			p.errorUnexpectedToken(p.token)
+			return attributes
+		}
+	}
+}
+
+// parseJSXAttributeValue parses the value of an attribute after its =, up to
+// and including its last token.
+func (p *parser) parseJSXAttributeValue() ast.Expr {
+	switch p.token {
+	case token.String:
+		return p.makeString(p.idx, p.parsedLiteral, p.literal)
+	case token.LeftBrace:
+		container := p.parseJSXExpressionContainer(false)
+		if container.Expression == nil {
+			p.errorAt(container.LeftBrace, CodeJSXEmptyAttribute)
+		}
+		return container
+	case token.Less:
+		start := p.idx
+		p.nextJSX()
+		return p.parseJSXElementAt(start)
+	}
+	p.errorUnexpectedToken(p.token)
+	return &ast.InvalidExpression{From: p.idx, To: p.idx}
+}
+
+// parseJSXExpressionContainer parses an expression in braces, starting at the
+// current { token and up to its }. Spread children are only allowed in child
+// position.
+func (p *parser) parseJSXExpressionContainer(child bool) *ast.JSXExpressionContainer {
+	container := &ast.JSXExpressionContainer{LeftBrace: p.idx}
+	p.next()
+	switch {
+	case p.token == token.RightBrace:
+	case p.token == token.Ellipsis && child:
+		p.next()
+		container.Expression = p.makeExpr(&ast.SpreadElement{
+			Expression: p.makeExpr(p.parseAssignmentExpression()),
+		})
+	default:
+		container.Expression = p.makeExpr(p.parseExpression())
+	}
+	container.RightBrace = p.expectJSX(token.RightBrace)
+	return container
+}
+
+// parseJSXChildren parses the children of an element or fragment, from right
+// after its opening tag up to the </ of its closing tag. It returns the
+// position of the < with the / as the current token.
+func (p *parser) parseJSXChildren() (children ast.JSXChildren, closing ast.Idx) {
+	for {
+		if text := p.scanJSXText(); text != nil {
+			children = append(children, ast.JSXChildItem{Child: text})
+		}
+		switch p.chr {
+		case '{':
+			p.read()
+			p.token, p.idx = token.LeftBrace, p.idxOf(p.chrOffset-1)
+			container := p.parseJSXExpressionContainer(true)
+			if p.token != token.RightBrace {
+				return children, container.LeftBrace
+			}
+			children = append(children, ast.JSXChildItem{Child: container})
+		case '<':
+			start := p.idxOf(p.chrOffset)
+			p.read()
+			p.nextJSX()
+			if p.token == token.Slash {
+				return children, start
+			}
+			child := p.parseJSXElementAt(start)
+			if p.token != token.Greater {
+				return children, start
+			}
+			children = append(children, ast.JSXChildItem{Child: child.(ast.JSXChild)})
+		default:
+			p.token, p.idx = token.Eof, p.idxOf(p.chrOffset)
+			p.error(CodeJSXUnterminated)
+			return children, p.idx
+		}
+	}
+}
+
+// scanJSXText scans the text up to the next child or closing tag.
+func (p *parser) scanJSXText() *ast.JSXText {
+	start := p.chrOffset
+	for p.chr != '<' && p.chr != '{' && p.chr != -1 {
+		p.read()
+	}
+	if p.chrOffset == start {
+		return nil
+	}
+	raw := p.str[start:p.chrOffset]
+	return &ast.JSXText{
+		Idx:   p.idxOf(start),
+		Value: decodeJSXEntities(raw),
+		Raw:   raw,
+	}
+}
+
+// nextJSX is like next but scans a token inside of a JSX tag.
+func (p *parser) nextJSX() {
+	p.token, p.literal, p.parsedLiteral, p.idx = p.scanJSX()
+}
+
+// scanJSX scans a token inside of a JSX tag, where identifiers may contain
+// dashes and strings cannot contain escapes but HTML character references.
+func (p *parser) scanJSX() (tkn token.Token, literal string, parsedLiteral string, idx ast.Idx) {
+	p.implicitSemicolon = false
+	for {
+		for isLineWhiteSpace(p.chr) || isLineTerminator(p.chr) {
+			p.read()
+		}
+		if p.chr != '/' || p._peek() != '/' && p._peek() != '*' {
+			break
+		}
+		start := p.chrOffset
+		p.read()
+		if p.chr == '/' {
+			p.skipSingleLineComment()
+			p.addComment(ast.CommentLine, start)
+		} else {
+			p.skipMultiLineComment()
+			p.addComment(ast.CommentBlock, start)
+		}
+	}
+
+	idx = p.idxOf(p.chrOffset)
+	start := p.chrOffset
+	switch chr := p.chr; {
+	case chr == -1:
+		return token.Eof, "", "", idx
+	case isIdentifierStart(chr) && chr != '\\':
+		for isIdentifierPart(p.chr) && p.chr != '\\' || p.chr == '-' {
+			p.read()
+		}
+		literal = p.str[start:p.chrOffset]
+		return token.Identifier, literal, literal, idx
+	case chr == '"' || chr == '\'':
+		p.read()
+		for p.chr != chr && p.chr != -1 {
+			p.read()
+		}
+		if p.chr == -1 {
+			p.errorAt(idx, CodeJSXUnterminatedString)
+			return token.Illegal, p.str[start:p.chrOffset], "", idx
+		}
+		p.read()
+		literal = p.str[start:p.chrOffset]
+		return token.String, literal, decodeJSXEntities(literal[1 : len(literal)-1]), idx
+	}
+
+	p.read()
+	switch p.str[start] {
+	case '<':
+		tkn = token.Less
+	case '>':
+		tkn = token.Greater
+	case '/':
+		tkn = token.Slash
+	case '=':
+		tkn = token.Assign
+	case '{':
+		tkn = token.LeftBrace
+	case '}':
+		tkn = token.RightBrace
+	case '.':
+		tkn = token.Period
+	case ':':
+		tkn = token.Colon
+	default:
+		p.errorAt(idx, CodeUnexpectedToken, token.Illegal)
+		tkn = token.Illegal
+	}
+	return tkn, p.str[start:p.chrOffset], "", idx
+}
+
+// decodeJSXEntities replaces the character references in JSX text and
+// attribute strings, which are &name; for an XHTML entity, &#N; and &#xN;.
+// Unlike in HTML the semicolon is required, anything else is left as it is.
+func decodeJSXEntities(s string) string {
+	i := strings.IndexByte(s, '&')
+	if i < 0 {
+		return s
+	}
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for ; i >= 0; i = strings.IndexByte(s, '&') {
+		sb.WriteString(s[:i])
+		s = s[i:]
+		if chr, n := jsxEntity(s); n > 0 {
+			sb.WriteRune(chr)
+			s = s[n:]
+		} else {
+			sb.WriteByte('&')
+			s = s[1:]
+		}
+	}
+	sb.WriteString(s)
+	return sb.String()
+}
+
+// jsxEntity decodes the character reference at the start of s and returns
+// its length, which is zero if s does not start with a valid one.
+func jsxEntity(s string) (rune, int) {
+	// Like Babel, give up on a reference of more than ten characters.
+	end := strings.IndexByte(s[:min(len(s), 12)], ';')
+	if end < 2 {
+		return 0, 0
+	}
+	name := s[1:end]
+	if name[0] != '#' {
+		chr, ok := jsxEntities[name]
+		if !ok {
+			return 0, 0
+		}
+		return chr, end + 1
+	}
+	digits, base := name[1:], 10
+	if strings.HasPrefix(digits, "x") {
+		digits, base = digits[1:], 16
+	}
+	value, err := strconv.ParseUint(digits, base, 32)
+	if err != nil || !utf8.ValidRune(rune(value)) {
+		return 0, 0
+	}
+	return rune(value), end + 1
+}
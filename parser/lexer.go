@@ -3,6 +3,7 @@ package parser
 import (
 	"errors"
 	"fmt"
+	"math/big"
 	"strconv"
 	"strings"
 	"unicode"
@@ -207,6 +208,7 @@ type parserState struct {
 	chr                                rune
 	chrOffset, offset                  int
 	errorCount                         int
+	commentCount                       int
 }
 
 func (p *parser) mark(state *parserState) *parserState {
@@ -217,6 +219,7 @@ func (p *parser) mark(state *parserState) *parserState {
 		p.idx, p.token, p.literal, p.parsedLiteral, p.implicitSemicolon, p.insertSemicolon, p.chr, p.chrOffset, p.offset
 
 	state.errorCount = len(p.errors)
+	state.commentCount = len(p.comments)
 	return state
 }
 
@@ -224,17 +227,24 @@ func (p *parser) restore(state *parserState) {
 	p.idx, p.token, p.literal, p.parsedLiteral, p.implicitSemicolon, p.insertSemicolon, p.chr, p.chrOffset, p.offset =
 		state.idx, state.tok, state.literal, state.parsedLiteral, state.implicitSemicolon, state.insertSemicolon, state.chr, state.chrOffset, state.offset
 	p.errors = p.errors[:state.errorCount]
+	p.comments = p.comments[:state.commentCount]
 }
 
 func (p *parser) peek() token.Token {
 	implicitSemicolon, insertSemicolon, chr, chrOffset, offset := p.implicitSemicolon, p.insertSemicolon, p.chr, p.chrOffset, p.offset
+	errorCount, commentCount := len(p.errors), len(p.comments)
 	tok, _, _, _ := p.scan()
 	p.implicitSemicolon, p.insertSemicolon, p.chr, p.chrOffset, p.offset = implicitSemicolon, insertSemicolon, chr, chrOffset, offset
+	p.errors = p.errors[:errorCount]
+	p.comments = p.comments[:commentCount]
 	return tok
 }
 
 func (p *parser) scan() (tkn token.Token, literal string, parsedLiteral string, idx ast.Idx) {
 	p.implicitSemicolon = false
+	// The end of the previous token, from which on an HTML-like close
+	// comment has to follow a line terminator.
+	prevEnd := p.chrOffset
 
 	for {
 		// Skip all whitespace and line terminators up front
@@ -389,6 +399,14 @@ func (p *parser) scan() (tkn token.Token, literal string, parsedLiteral string,
 				insertSemicolon = true
 			}
 		case '-':
+			if p.chr == '-' && p._peek() == '>' && p.webCompat() {
+				start := p.chrOffset - 1
+				if prevEnd == 0 || strings.ContainsAny(p.str[prevEnd:start], "\r\n\u2028\u2029") {
+					p.skipSingleLineComment()
+					p.addComment(ast.CommentHTMLClose, start)
+					continue
+				}
+			}
 			tkn = p.switch3(token.Minus, token.SubtractAssign, '-', token.Decrement)
 			if tkn == token.Decrement {
 				insertSemicolon = true
@@ -403,14 +421,18 @@ func (p *parser) scan() (tkn token.Token, literal string, parsedLiteral string,
 		case '/':
 			if p.chr == '/' {
 				// Single-line comment
+				start := p.chrOffset - 1
 				p.skipSingleLineComment()
+				p.addComment(ast.CommentLine, start)
 				continue
 			} else if p.chr == '*' {
 				// Multi-line comment
+				start := p.chrOffset - 1
 				if p.skipMultiLineComment() {
 					p.insertSemicolon = false
 					p.implicitSemicolon = true
 				}
+				p.addComment(ast.CommentBlock, start)
 				continue
 			} else {
 				// Division or QuotientAssign
@@ -422,6 +444,12 @@ func (p *parser) scan() (tkn token.Token, literal string, parsedLiteral string,
 		case '^':
 			tkn = p.switch2(token.ExclusiveOr, token.ExclusiveOrAssign)
 		case '<':
+			if p.chr == '!' && strings.HasPrefix(p.str[p.chrOffset:], "!--") && p.webCompat() {
+				start := p.chrOffset - 1
+				p.skipSingleLineComment()
+				p.addComment(ast.CommentHTMLOpen, start)
+				continue
+			}
 			tkn = p.switch4(token.Less, token.LessOrEqual, '<', token.ShiftLeft, token.ShiftLeftAssign)
 		case '>':
 			// Potential >>, >>>, >= ...
@@ -453,9 +481,9 @@ func (p *parser) scan() (tkn token.Token, literal string, parsedLiteral string,
 				tkn = token.StrictNotEqual
 			}
 		case '&':
-			tkn = p.switch3(token.And, token.AndAssign, '&', token.LogicalAnd)
+			tkn = p.switch4(token.And, token.AndAssign, '&', token.LogicalAnd, token.LogicalAndAssign)
 		case '|':
-			tkn = p.switch3(token.Or, token.OrAssign, '|', token.LogicalOr)
+			tkn = p.switch4(token.Or, token.OrAssign, '|', token.LogicalOr, token.LogicalOrAssign)
 		case '~':
 			tkn = token.BitwiseNot
 		case '?':
@@ -466,6 +494,10 @@ func (p *parser) scan() (tkn token.Token, literal string, parsedLiteral string,
 			} else if p.chr == '?' {
 				p.read()
 				tkn = token.Coalesce
+				if p.chr == '=' {
+					p.read()
+					tkn = token.CoalesceAssign
+				}
 			} else {
 				tkn = token.QuestionMark
 			}
@@ -481,6 +513,8 @@ func (p *parser) scan() (tkn token.Token, literal string, parsedLiteral string,
 		case '`':
 			// Template literal
 			tkn = token.Backtick
+		case '@':
+			tkn = token.At
 		case '#':
 			// Possible shebang (#!)
 			if p.chrOffset == 1 && p.chr == '!' {
@@ -582,7 +616,7 @@ func (p *parser) read() {
 		if chr >= utf8.RuneSelf { // !ASCII
 			chr, width = utf8.DecodeRuneInString(p.str[p.offset:])
 			if chr == utf8.RuneError && width == 1 {
-				p.error("Invalid UTF-8 character")
+				p.error(CodeInvalidUTF8)
 			}
 		}
 		p.offset += width
@@ -593,6 +627,20 @@ func (p *parser) read() {
 	}
 }
 
+// scanError reports an error with code that the scanner found at offset,
+// spanning length bytes.
+func (p *parser) scanError(offset, length int, code Code, args ...any) *SyntaxError {
+	e := p.errorAt(p.idxOf(offset), code, args...)
+	e.Length = length
+	return e
+}
+
+// errorAtChr reports an error with code at the current character, which is
+// empty at the end of the input.
+func (p *parser) errorAtChr(code Code, args ...any) *SyntaxError {
+	return p.scanError(p.chrOffset, p.offset-p.chrOffset, code, args...)
+}
+
 func (p *parser) skipSingleLineComment() {
 	for p.chr != -1 {
 		p.read()
@@ -602,6 +650,34 @@ func (p *parser) skipSingleLineComment() {
 	}
 }
 
+// addComment records the comment starting at offset start and ending at the
+// current character, if comments are being collected.
+func (p *parser) addComment(kind ast.CommentKind, start int) {
+	if !p.opts.Comments {
+		return
+	}
+	delimiter := 2
+	switch kind {
+	case ast.CommentHTMLOpen:
+		delimiter = 4
+	case ast.CommentHTMLClose:
+		delimiter = 3
+	}
+	text := p.str[start+delimiter : p.chrOffset]
+	if kind == ast.CommentBlock {
+		if !strings.HasSuffix(text, "*/") {
+			// Unterminated, already reported by skipMultiLineComment.
+			return
+		}
+		text = text[:len(text)-2]
+	}
+	p.comments = append(p.comments, &ast.Comment{
+		Idx:  p.idxOf(start),
+		Kind: kind,
+		Text: text,
+	})
+}
+
 func (p *parser) skipMultiLineComment() (hasLineTerminator bool) {
 	p.read()
 	for p.chr >= 0 {
@@ -695,13 +771,29 @@ func (p *parser) skipWhiteSpace() {
 	}
 }
 
-func (p *parser) scanMantissa(base int) {
-	for digitValue(p.chr) < base {
+// scanMantissa consumes the digits of a numeric literal. Numeric separators
+// are only allowed between two digits, it reports an error at a misplaced one
+// and returns false. Of two separators in a row the second is misplaced.
+func (p *parser) scanMantissa(base int, allowSeparator bool) bool {
+	for {
+		if p.chr == '_' && allowSeparator {
+			next := p._peek()
+			if !isDigit(rune(p.str[p.chrOffset-1]), base) || next != '_' && !isDigit(next, base) {
+				p.errorAtChr(CodeNumericSeparator)
+				return false
+			}
+		} else if !isDigit(p.chr, base) {
+			return true
+		}
 		p.read()
 	}
 }
 
-func (p *parser) scanEscape(quote rune) (int, bool) {
+// scanEscape consumes the escape sequence after a backslash and returns the
+// number of UTF-16 code units it stands for and whether it is outside ASCII.
+// For a malformed hexadecimal or Unicode escape it also returns a message,
+// which is only an error outside of regular expressions.
+func (p *parser) scanEscape(quote rune) (int, bool, string) {
 	var length, base uint32
 	chr := p.chr
 	switch chr {
@@ -710,20 +802,20 @@ func (p *parser) scanEscape(quote rune) (int, bool) {
 		length, base = 3, 8
 	case 'a', 'b', 'f', 'n', 'r', 't', 'v', '\\', '"', '\'':
 		p.read()
-		return 1, false
+		return 1, false, ""
 	case '\r':
 		p.read()
 		if p.chr == '\n' {
 			p.read()
-			return 2, false
+			return 2, false, ""
 		}
-		return 1, false
+		return 1, false, ""
 	case '\n':
 		p.read()
-		return 1, false
+		return 1, false, ""
 	case '\u2028', '\u2029':
 		p.read()
-		return 1, true
+		return 1, true, ""
 	case 'x':
 		p.read()
 		length, base = 2, 16
@@ -750,29 +842,41 @@ func (p *parser) scanEscape(quote rune) (int, bool) {
 				value = value*base + digit
 				p.read()
 			}
-		} else {
-			for p.chr != quote && p.chr >= 0 && value < utf8.MaxRune {
-				if p.chr == '}' {
-					p.read()
-					break
+			if length > 0 && base == 16 {
+				if chr == 'x' {
+					return 1, false, "Invalid hexadecimal escape sequence"
 				}
+				return 1, false, "Invalid Unicode escape sequence"
+			}
+		} else {
+			digits := 0
+			for ; p.chr != '}'; digits++ {
 				digit := uint32(digitValue(p.chr))
 				if digit >= base {
-					break
+					return 1, false, "Invalid Unicode escape sequence"
+				}
+				if value <= utf8.MaxRune {
+					value = value*base + digit
 				}
-				value = value*base + digit
 				p.read()
 			}
+			p.read()
+			if digits == 0 {
+				return 1, false, "Invalid Unicode escape sequence"
+			}
+			if value > utf8.MaxRune {
+				return 1, false, "Undefined Unicode code-point"
+			}
 		}
 		chr = rune(value)
 	}
 	if chr >= utf8.RuneSelf {
 		if chr > 0xFFFF {
-			return 2, true
+			return 2, true, ""
 		}
-		return 1, true
+		return 1, true, ""
 	}
-	return 1, false
+	return 1, false, ""
 }
 
 func (p *parser) scanString(offset int, parse bool) (literal string, parsed string, err string) {
@@ -780,6 +884,9 @@ func (p *parser) scanString(offset int, parse bool) (literal string, parsed stri
 	quote := rune(p.str[offset])
 	length := 0
 	isUnicode := false
+	// The first malformed escape sequence, which regular expressions allow.
+	var escapeStart, escapeEnd int
+	var escapeErr string
 	for p.chr != quote {
 		chr := p.chr
 		if chr == '\n' || chr == '\r' || chr < 0 {
@@ -796,11 +903,15 @@ func (p *parser) scanString(offset int, parse bool) (literal string, parsed stri
 				}
 				p.scanNewline()
 			} else {
-				l, u := p.scanEscape(quote)
+				start := p.chrOffset - 1
+				l, u, err := p.scanEscape(quote)
 				length += l
 				if u {
 					isUnicode = true
 				}
+				if err != "" && escapeErr == "" {
+					escapeStart, escapeEnd, escapeErr = start, p.chrOffset, err
+				}
 			}
 			continue
 		} else if chr == '[' && quote == '/' {
@@ -823,19 +934,25 @@ func (p *parser) scanString(offset int, parse bool) (literal string, parsed stri
 	p.read()
 	literal = p.str[offset:p.chrOffset]
 	if parse {
-		// TODO strict
+		if escapeErr != "" {
+			p.scanError(escapeStart, escapeEnd-escapeStart, CodeInvalidEscape, escapeErr)
+			return "", "", escapeErr
+		}
+		// Octal escapes in strict mode code are reported by the parser, since
+		// a directive prologue can make the code strict after the fact.
 		parsed, err = parseStringLiteral(literal[1:len(literal)-1], length, isUnicode, false)
 	}
 	return
 
 newline:
-	p.scanNewline()
-	errStr := "String not terminated"
-	if quote == '/' {
-		errStr = "Invalid regular expression: missing /"
-		p.error(errStr)
+	if quote != '/' && quote != -1 {
+		p.errorAtChr(CodeUnterminatedString)
+		p.scanNewline()
+		return "", "", code2message[CodeUnterminatedString]
 	}
-	return "", "", errStr
+	p.scanNewline()
+	p.error(CodeUnterminatedRegExp)
+	return "", "", code2message[CodeUnterminatedRegExp]
 }
 
 func (p *parser) scanNewline() {
@@ -852,7 +969,11 @@ func (p *parser) scanNewline() {
 	p.read()
 }
 
-func (p *parser) parseTemplateCharacters() (literal string, parsed string, finished bool, parseErr, err string) {
+// parseTemplateCharacters scans the characters of a template up to the next
+// substitution or the closing backtick. A malformed escape sequence leaves the
+// cooked value undefined, which is only allowed in a tagged template, so it is
+// reported unless tagged is set.
+func (p *parser) parseTemplateCharacters(tagged bool) (literal string, parsed string, finished bool, parseErr, err string) {
 	offset := p.chrOffset
 	var end int
 	length := 0
@@ -876,16 +997,28 @@ func (p *parser) parseTemplateCharacters() (literal string, parsed string, finis
 				}
 				p.scanNewline()
 			} else {
-				if p.chr == '8' || p.chr == '9' {
-					if parseErr == "" {
-						parseErr = "\\8 and \\9 are not allowed in template strings."
-					}
+				start := p.chrOffset - 1
+				msg := ""
+				switch {
+				case p.chr == '8' || p.chr == '9':
+					msg = "\\8 and \\9 are not allowed in template strings."
+				case '1' <= p.chr && p.chr <= '7' || p.chr == '0' && isDecimalDigit(p._peek()):
+					msg = "Octal escape sequences are not allowed in template strings"
 				}
-				l, u := p.scanEscape('`')
+				l, u, escapeErr := p.scanEscape('`')
 				length += l
 				if u {
 					isUnicode = true
 				}
+				if msg == "" {
+					msg = escapeErr
+				}
+				if msg != "" && parseErr == "" {
+					parseErr = msg
+					if !tagged {
+						p.scanError(start, p.chrOffset-start, CodeInvalidEscape, msg)
+					}
+				}
 			}
 			continue
 		}
@@ -913,11 +1046,15 @@ func (p *parser) parseTemplateCharacters() (literal string, parsed string, finis
 	}
 	if parseErr == "" {
 		parsed, parseErr = parseStringLiteral(literal, length, isUnicode, true)
+		if parseErr != "" && !tagged {
+			p.scanError(offset, end-offset, CodeInvalidEscape, parseErr)
+		}
 	}
 	p.insertSemicolon = true
 	return
 unterminated:
-	err = errUnexpectedEndOfInput
+	p.errorAtChr(CodeUnterminatedTemplate)
+	err = code2message[CodeUnterminatedTemplate]
 	finished = true
 	return
 }
@@ -954,6 +1091,9 @@ func hex2decimal(chr byte) (value rune, ok bool) {
 }
 
 func parseNumberLiteral(literal string) (value float64, err error) {
+	// Numeric separators have already been validated by the scanner.
+	literal = strings.ReplaceAll(literal, "_", "")
+
 	// TODO Is Uint okay? What about -MAX_UINT
 	n, err := strconv.ParseInt(literal, 0, 64)
 	if err == nil {
@@ -992,13 +1132,20 @@ error:
 	return 0, errors.New("Illegal numeric literal")
 }
 
+func parseBigIntLiteral(literal string) (*big.Int, error) {
+	literal = strings.ReplaceAll(strings.TrimSuffix(literal, "n"), "_", "")
+	value, ok := new(big.Int).SetString(literal, 0)
+	if !ok {
+		return nil, errors.New("Illegal numeric literal")
+	}
+	return value, nil
+}
+
 func parseStringLiteral(literal string, length int, unicode, strict bool) (string, string) {
 	var sb strings.Builder
 	var chars []uint16
 	if unicode {
-		chars = make([]uint16, 1, length+1)
-		// BOM
-		chars[0] = 0xFEFF
+		chars = make([]uint16, 0, length)
 	} else {
 		sb.Grow(length)
 	}
@@ -1167,7 +1314,7 @@ func parseStringLiteral(literal string, length int, unicode, strict bool) (strin
 	}
 
 	if unicode {
-		if len(chars) != length+1 {
+		if len(chars) != length {
 			panic(fmt.Errorf("unexpected unicode length while parsing '%s'", literal))
 		}
 		return string(utf16.Decode(chars)), ""
@@ -1185,7 +1332,9 @@ func (p *parser) scanNumericLiteral(decimalPoint bool) (token.Token, string) {
 
 	if decimalPoint {
 		offset--
-		p.scanMantissa(10)
+		if !p.scanMantissa(10, true) {
+			return token.Illegal, p.str[offset:p.chrOffset]
+		}
 	} else {
 		if p.chr == '0' {
 			p.read()
@@ -1199,25 +1348,50 @@ func (p *parser) scanNumericLiteral(decimalPoint bool) (token.Token, string) {
 				base = 2
 			case '.', 'e', 'E':
 				// no-op
-			default:
-				// legacy octal
-				p.scanMantissa(8)
+			case 'n':
+				p.read()
+				tkn = token.BigInt
 				goto end
+			default:
+				// Legacy octal, which does not allow separators. A digit of 8
+				// or 9 makes it a decimal with a leading zero instead, which
+				// may go on with a fraction or an exponent.
+				p.scanMantissa(8, false)
+				if p.chr != '8' && p.chr != '9' {
+					goto end
+				}
+				p.scanMantissa(10, false)
 			}
 			if base > 0 {
 				p.read()
 				if !isDigit(p.chr, base) {
+					p.errorAtChr(p.numberError())
+					return token.Illegal, p.str[offset:p.chrOffset]
+				}
+				if !p.scanMantissa(base, true) {
 					return token.Illegal, p.str[offset:p.chrOffset]
 				}
-				p.scanMantissa(base)
+				if p.chr == 'n' {
+					p.read()
+					tkn = token.BigInt
+				}
 				goto end
 			}
 		} else {
-			p.scanMantissa(10)
+			if !p.scanMantissa(10, true) {
+				return token.Illegal, p.str[offset:p.chrOffset]
+			}
+			if p.chr == 'n' {
+				p.read()
+				tkn = token.BigInt
+				goto end
+			}
 		}
 		if p.chr == '.' {
 			p.read()
-			p.scanMantissa(10)
+			if !p.scanMantissa(10, true) {
+				return token.Illegal, p.str[offset:p.chrOffset]
+			}
 		}
 	}
 
@@ -1226,17 +1400,30 @@ func (p *parser) scanNumericLiteral(decimalPoint bool) (token.Token, string) {
 		if p.chr == '-' || p.chr == '+' {
 			p.read()
 		}
-		if isDecimalDigit(p.chr) {
-			p.read()
-			p.scanMantissa(10)
-		} else {
+		if !isDecimalDigit(p.chr) {
+			p.errorAtChr(p.numberError())
+			return token.Illegal, p.str[offset:p.chrOffset]
+		}
+		if !p.scanMantissa(10, true) {
 			return token.Illegal, p.str[offset:p.chrOffset]
 		}
 	}
 end:
 	if isIdentifierStart(p.chr) || isDecimalDigit(p.chr) {
+		// The literal must not run into an identifier or a digit that does
+		// not belong to it, as in 3in or 0b12.
+		p.errorAtChr(p.numberError())
 		return token.Illegal, p.str[offset:p.chrOffset]
 	}
 
 	return tkn, p.str[offset:p.chrOffset]
 }
+
+// numberError returns the code of the error at the current character, which
+// does not belong in a numeric literal.
+func (p *parser) numberError() Code {
+	if p.chr == '_' {
+		return CodeNumericSeparator
+	}
+	return CodeInvalidNumber
+}
@@ -0,0 +1,497 @@
+package parser
+
+import (
+	"github.com/t14raptor/go-fast/ast"
+	"github.com/t14raptor/go-fast/token"
+)
+
+func (p *parser) parseModuleItem() ast.Stmt {
+	switch p.token {
+	case token.Import:
+		if tok := p.peek(); tok != token.LeftParenthesis && tok != token.Period {
+			if !p.isModule() {
+				p.errorAt(p.idx, CodeImportOutsideModule).Length = len("import")
+			}
+			return p.parseImportDeclaration()
+		}
+	case token.Export:
+		return p.parseModuleExport()
+	case token.At:
+		p.parseClassDecorators(true)
+		if p.token == token.Export {
+			return p.parseModuleExport()
+		}
+	}
+	return p.parseStatement()
+}
+
+// parseModuleExport parses an export declaration at the top level, which is
+// an error in a script.
+func (p *parser) parseModuleExport() ast.Stmt {
+	if !p.isModule() {
+		p.errorAt(p.idx, CodeExportOutsideModule).Length = len("export")
+	}
+	return p.parseExportDeclaration()
+}
+
+// expectContextual consumes an identifier with the given name, such as "from" or "as".
+func (p *parser) expectContextual(name string) ast.Idx {
+	idx := p.idx
+	if p.token != token.Identifier || p.literal != name {
+		p.errorUnexpectedToken(p.token)
+	}
+	p.next()
+	return idx
+}
+
+func (p *parser) parseModuleSpecifier() *ast.StringLiteral {
+	literal, parsedLiteral, idx := p.literal, p.parsedLiteral, p.idx
+	p.expect(token.String)
+	return p.makeString(idx, parsedLiteral, literal)
+}
+
+func (p *parser) parseModuleExportName() *ast.ModuleExportName {
+	if p.token == token.String {
+		return &ast.ModuleExportName{Name: p.parseModuleSpecifier()}
+	}
+	if !token.ID(p.token) {
+		idx := p.expect(token.Identifier)
+		return &ast.ModuleExportName{Name: &ast.Identifier{Idx: idx}}
+	}
+	return &ast.ModuleExportName{Name: p.parseIdentifier()}
+}
+
+func (p *parser) parseImportedBinding() *ast.Identifier {
+	p.tokenToBindingId()
+	if p.token != token.Identifier {
+		idx := p.expect(token.Identifier)
+		return &ast.Identifier{Idx: idx}
+	}
+	return p.parseIdentifier()
+}
+
+func (p *parser) parseImportDeclaration() ast.Stmt {
+	node := &ast.ImportDeclaration{
+		Import: p.expect(token.Import),
+	}
+
+	if p.opts.TypeScript {
+		if stmt := p.parseTypeScriptImport(node.Import); stmt != nil {
+			return stmt
+		}
+	}
+
+	typeOnly := false
+	if p.token != token.String {
+		if p.isBindingId(p.token) {
+			node.Specifiers = append(node.Specifiers, ast.ImportSpecifier{
+				Specifier: &ast.ImportDefaultSpecifier{Local: p.parseImportedBinding()},
+			})
+			if p.token == token.Comma {
+				p.next()
+				typeOnly = p.parseImportClause(&node.Specifiers)
+			}
+		} else {
+			typeOnly = p.parseImportClause(&node.Specifiers)
+		}
+		p.expectContextual("from")
+	}
+
+	node.Source = p.parseModuleSpecifier()
+	p.semicolon()
+
+	if typeOnly && len(node.Specifiers) == 0 {
+		return p.strip(node.Import)
+	}
+	return node
+}
+
+// parseImportClause parses the namespace import or named imports of an import
+// declaration. It reports whether type-only specifiers were dropped.
+func (p *parser) parseImportClause(specifiers *ast.ImportSpecifiers) (typeOnly bool) {
+	switch p.token {
+	case token.Multiply:
+		star := p.idx
+		p.next()
+		p.expectContextual("as")
+		*specifiers = append(*specifiers, ast.ImportSpecifier{
+			Specifier: &ast.ImportNamespaceSpecifier{
+				Star:  star,
+				Local: p.parseImportedBinding(),
+			},
+		})
+	case token.LeftBrace:
+		p.next()
+		for p.token != token.RightBrace && p.token != token.Eof {
+			dropped := p.skipSpecifierTypeModifier()
+			tkn := p.token
+			imported := p.parseModuleExportName()
+
+			var local *ast.Identifier
+			if p.token == token.Identifier && p.literal == "as" {
+				p.next()
+				local = p.parseImportedBinding()
+			} else if ident, ok := imported.Name.(*ast.Identifier); ok && p.isBindingId(tkn) {
+				local = &ast.Identifier{Idx: ident.Idx, Name: ident.Name}
+			} else {
+				p.errorUnexpectedToken(p.token)
+				local = &ast.Identifier{Idx: imported.Idx0()}
+			}
+
+			if dropped {
+				typeOnly = true
+			} else {
+				*specifiers = append(*specifiers, ast.ImportSpecifier{
+					Specifier: &ast.ImportNamedSpecifier{
+						Imported: imported,
+						Local:    local,
+					},
+				})
+			}
+			if p.token != token.RightBrace {
+				p.expect(token.Comma)
+			}
+		}
+		p.expect(token.RightBrace)
+	default:
+		p.errorUnexpectedToken(p.token)
+	}
+	return typeOnly
+}
+
+func (p *parser) parseExportDeclaration() ast.Stmt {
+	idx := p.expect(token.Export)
+
+	if p.token == token.At {
+		p.parseClassDecorators(false)
+	} else if p.token != token.Default {
+		p.expectDecoratedClass(false)
+	}
+
+	if p.opts.TypeScript {
+		if stmt := p.parseTypeScriptExport(idx); stmt != nil {
+			return stmt
+		}
+	}
+
+	switch p.token {
+	case token.Multiply:
+		p.next()
+		node := &ast.ExportAllDeclaration{Export: idx}
+		if p.token == token.Identifier && p.literal == "as" {
+			p.next()
+			node.Exported = p.parseModuleExportName()
+		}
+		p.expectContextual("from")
+		node.Source = p.parseModuleSpecifier()
+		p.semicolon()
+		return node
+	case token.LeftBrace:
+		return p.parseExportNamedSpecifiers(idx)
+	case token.Default:
+		p.next()
+		if p.token == token.At {
+			p.parseClassDecorators(false)
+		} else {
+			p.expectDecoratedClass(false)
+		}
+		if p.opts.TypeScript {
+			if stmt := p.parseTypeScriptDeclaration(); stmt != nil {
+				if stmt == p.stripped {
+					return stmt
+				}
+				return &ast.ExportDefaultDeclaration{Export: idx, Declaration: p.makeStmt(stmt)}
+			}
+		}
+		return p.parseExportDefaultDeclaration(idx)
+	case token.Var, token.Let, token.Const:
+		return &ast.ExportNamedDeclaration{
+			Export:      idx,
+			Declaration: p.makeStmt(p.parseLexicalDeclaration(p.token)),
+		}
+	case token.Async:
+		if f := p.parseMaybeAsyncFunction(true); f != nil {
+			return &ast.ExportNamedDeclaration{
+				Export:      idx,
+				Declaration: p.makeStmt(&ast.FunctionDeclaration{Function: f}),
+			}
+		}
+	case token.Function:
+		return &ast.ExportNamedDeclaration{
+			Export:      idx,
+			Declaration: p.makeStmt(&ast.FunctionDeclaration{Function: p.parseFunction(true, false, p.idx)}),
+		}
+	case token.Class:
+		return &ast.ExportNamedDeclaration{
+			Export:      idx,
+			Declaration: p.makeStmt(&ast.ClassDeclaration{Class: p.parseClass(true)}),
+		}
+	}
+
+	p.errorUnexpectedToken(p.token)
+	p.nextStatement()
+	return &ast.BadStatement{From: idx, To: p.idx}
+}
+
+func (p *parser) parseExportNamedSpecifiers(idx ast.Idx) *ast.ExportNamedDeclaration {
+	node := &ast.ExportNamedDeclaration{Export: idx}
+
+	// Local names must be binding identifiers unless re-exporting from another module,
+	// which is only known once the closing brace has been reached.
+	var invalid []*ast.ModuleExportName
+
+	p.expect(token.LeftBrace)
+	for p.token != token.RightBrace && p.token != token.Eof {
+		dropped := p.skipSpecifierTypeModifier()
+		tkn := p.token
+		local := p.parseModuleExportName()
+		if _, ok := local.Name.(*ast.StringLiteral); ok || (tkn != token.String && !p.isBindingId(tkn)) {
+			invalid = append(invalid, local)
+		}
+
+		var exported *ast.ModuleExportName
+		if p.token == token.Identifier && p.literal == "as" {
+			p.next()
+			exported = p.parseModuleExportName()
+		} else {
+			exported = local.Clone()
+		}
+
+		if !dropped {
+			node.Specifiers = append(node.Specifiers, ast.ExportSpecifier{
+				Local:    local,
+				Exported: exported,
+			})
+		}
+		if p.token != token.RightBrace {
+			p.expect(token.Comma)
+		}
+	}
+	node.RightBrace = p.expect(token.RightBrace)
+
+	if p.token == token.Identifier && p.literal == "from" {
+		p.next()
+		node.Source = p.parseModuleSpecifier()
+	} else if len(invalid) > 0 {
+		p.errorAt(invalid[0].Idx0(), CodeExportSpecifierNotLocal)
+	}
+	p.semicolon()
+
+	return node
+}
+
+func (p *parser) parseExportDefaultDeclaration(idx ast.Idx) *ast.ExportDefaultDeclaration {
+	node := &ast.ExportDefaultDeclaration{Export: idx}
+
+	switch p.token {
+	case token.Async:
+		if f := p.parseMaybeAsyncFunction(false); f != nil {
+			p.setExportDefaultFunction(node, f)
+			return node
+		}
+	case token.Function:
+		p.setExportDefaultFunction(node, p.parseFunction(false, false, p.idx))
+		return node
+	case token.Class:
+		class := p.parseClass(false)
+		if class.Name != nil && class.Name.Name != "" {
+			node.Declaration = p.makeStmt(&ast.ClassDeclaration{Class: class})
+		} else {
+			node.Expression = p.makeExpr(class)
+		}
+		return node
+	}
+
+	node.Expression = p.makeExpr(p.parseAssignmentExpression())
+	p.semicolon()
+
+	return node
+}
+
+func (p *parser) setExportDefaultFunction(node *ast.ExportDefaultDeclaration, f *ast.FunctionLiteral) {
+	if f.Name != nil {
+		node.Declaration = p.makeStmt(&ast.FunctionDeclaration{Function: f})
+	} else {
+		node.Expression = p.makeExpr(f)
+	}
+}
+
+// parseImportExpression parses `import(...)` and `import.meta`.
+func (p *parser) parseImportExpression() ast.Expr {
+	idx := p.expect(token.Import)
+
+	if p.token == token.Period {
+		p.next()
+		if p.token != token.Identifier || p.literal != "meta" {
+			p.errorUnexpectedToken(p.token)
+			p.nextStatement()
+			return &ast.InvalidExpression{From: idx, To: p.idx}
+		}
+		if !p.isModule() {
+			p.error(CodeImportMetaOutsideModule)
+		}
+		return &ast.MetaProperty{
+			Meta: &ast.Identifier{
+				Name: token.Import.String(),
+				Idx:  idx,
+			},
+			Property: p.parseIdentifier(),
+			Idx:      idx,
+		}
+	}
+
+	node := &ast.ImportExpression{Import: idx}
+	p.expect(token.LeftParenthesis)
+	node.Source = p.makeExpr(p.parseAssignmentExpression())
+	if p.token == token.Comma {
+		p.next()
+		if p.token != token.RightParenthesis {
+			node.Options = p.makeExpr(p.parseAssignmentExpression())
+			if p.token == token.Comma {
+				p.next()
+			}
+		}
+	}
+	node.RightParenthesis = p.expect(token.RightParenthesis)
+
+	return node
+}
+
+// checkModule reports the early errors of a module that need all of its top
+// level: names exported twice, local exports of names the module does not
+// declare, and imported bindings that are declared again.
+func (p *parser) checkModule(body ast.Statements) {
+	declared := map[string]*ast.Identifier{}
+	declare := func(ident *ast.Identifier) {
+		if declared[ident.Name] == nil {
+			declared[ident.Name] = ident
+		}
+	}
+	var imports []*ast.Identifier
+	var locals []*ast.ModuleExportName
+	exported := map[string]*Span{}
+	export := func(name string, idx ast.Idx, length int) {
+		if first := exported[name]; first != nil {
+			e := p.errorAt(idx, CodeDuplicateExport, name)
+			e.Length = length
+			e.Related = first
+		} else {
+			exported[name] = p.span(idx, length, "The first export")
+		}
+	}
+	exportName := func(n *ast.ModuleExportName) {
+		switch name := n.Name.(type) {
+		case *ast.Identifier:
+			export(name.Name, name.Idx, len(name.Name))
+		case *ast.StringLiteral:
+			export(name.Value, name.Idx, int(name.Idx1()-name.Idx))
+		}
+	}
+
+	for _, stmt := range body {
+		var decl ast.Stmt
+		switch s := stmt.Stmt.(type) {
+		case *ast.ImportDeclaration:
+			for _, spec := range s.Specifiers {
+				if local := importedBinding(spec.Specifier); local != nil {
+					imports = append(imports, local)
+				}
+			}
+		case *ast.ExportNamedDeclaration:
+			for _, spec := range s.Specifiers {
+				exportName(spec.Exported)
+				if s.Source == nil {
+					locals = append(locals, spec.Local)
+				}
+			}
+			if s.Declaration != nil {
+				decl = s.Declaration.Stmt
+				boundDeclarationNames(decl, func(ident *ast.Identifier) {
+					export(ident.Name, ident.Idx, len(ident.Name))
+				})
+			}
+		case *ast.ExportAllDeclaration:
+			if s.Exported != nil {
+				exportName(s.Exported)
+			}
+		case *ast.ExportDefaultDeclaration:
+			export("default", s.Export, len("export"))
+			if s.Declaration != nil {
+				decl = s.Declaration.Stmt
+			}
+		default:
+			decl = s
+		}
+		if decl != nil {
+			boundDeclarationNames(decl, declare)
+		}
+	}
+
+	if len(locals) > 0 || len(imports) > 0 {
+		// Variables declared in nested blocks belong to the module too.
+		v := &varNames{declare: declare}
+		v.V = v
+		for i := range body {
+			body[i].VisitWith(v)
+		}
+	}
+
+	imported := map[string]*ast.Identifier{}
+	for _, local := range imports {
+		first := imported[local.Name]
+		if first == nil {
+			first = declared[local.Name]
+		}
+		if first != nil {
+			e := p.errorAtNode(local, CodeDuplicateImport, local.Name)
+			e.Related = p.span(first.Idx, len(first.Name), "The first declaration")
+		} else {
+			imported[local.Name] = local
+		}
+	}
+
+	// The declarations of TypeScript, which may be exported, are stripped.
+	if p.opts.TypeScript {
+		return
+	}
+	for _, local := range locals {
+		if ident, ok := local.Name.(*ast.Identifier); ok && declared[ident.Name] == nil && imported[ident.Name] == nil {
+			p.errorAtNode(ident, CodeUndeclaredExport, ident.Name)
+		}
+	}
+}
+
+// boundDeclarationNames calls fn with every name declared by decl.
+func boundDeclarationNames(decl ast.Stmt, fn func(*ast.Identifier)) {
+	switch decl := decl.(type) {
+	case *ast.VariableDeclaration:
+		for _, item := range decl.List {
+			ast.BoundNames(item.Target, fn)
+		}
+	case *ast.FunctionDeclaration:
+		if decl.Function.Name != nil {
+			fn(decl.Function.Name)
+		}
+	case *ast.ClassDeclaration:
+		if decl.Class.Name != nil {
+			fn(decl.Class.Name)
+		}
+	}
+}
+
+// varNames collects the variables declared with var outside of functions.
+type varNames struct {
+	ast.NoopVisitor
+
+	declare func(*ast.Identifier)
+}
+
+func (v *varNames) VisitVariableDeclaration(n *ast.VariableDeclaration) {
+	if n.Token == token.Var {
+		boundDeclarationNames(n, v.declare)
+	}
+}
+
+func (v *varNames) VisitFunctionLiteral(*ast.FunctionLiteral)           {}
+func (v *varNames) VisitArrowFunctionLiteral(*ast.ArrowFunctionLiteral) {}
+func (v *varNames) VisitClassLiteral(*ast.ClassLiteral)                 {}
@@ -1,7 +1,13 @@
 package parser
 
 import (
+	"fmt"
+	"io"
+	"io/fs"
+	"unsafe"
+
 	"github.com/t14raptor/go-fast/ast"
+	"github.com/t14raptor/go-fast/limit"
 	"github.com/t14raptor/go-fast/token"
 )
 
@@ -9,6 +15,9 @@ import (
 type parser struct {
 	str    string
 	length int
+	base   ast.Idx // Added to the positions of all nodes
+	// file indexes the lines of str once it is needed, see source.
+	file *ast.SourceFile
 
 	chr       rune // The current character
 	chrOffset int  // The offset of current character
@@ -23,7 +32,23 @@ type parser struct {
 	insertSemicolon   bool // If we see a newline, then insert an implicit semicolon
 	implicitSemicolon bool // An implicit semicolon exists
 
-	errors ErrorList
+	opts Options
+	// limits enforces opts.Limits while parsing.
+	limits limit.Tracker
+
+	errors   ErrorList
+	comments []*ast.Comment
+
+	// The statement of the last stripped TypeScript declaration, see strip.
+	stripped ast.Stmt
+	// The parameter properties of the last parameter list.
+	paramProperties []*ast.Identifier
+	// The start of the consequent of the innermost conditional expression,
+	// see tryArrowFunction.
+	consequent ast.Idx
+	// The decorators in front of the class that is parsed next, see
+	// parseClassDecorators.
+	decorators ast.Decorators
 
 	recover struct {
 		// Scratch when trying to seek to the next statement, etc.
@@ -31,8 +56,7 @@ type parser struct {
 		count int
 	}
 
-	exprArena *miniArena[ast.Expression]
-	stmtArena *miniArena[ast.Statement]
+	arenas arenas
 }
 
 // newParser ...
@@ -42,24 +66,266 @@ func newParser(src string) *parser {
 		str:    src,
 		length: len(src),
 
-		exprArena: newArena[ast.Expression](1024),
-		stmtArena: newArena[ast.Statement](1024),
+		arenas: newArenas(),
 	}
 }
 
+// Options configures how source text is parsed.
+type Options struct {
+	// SourceType selects the script or module goal. The zero value parses as
+	// a script. Only modules may have import and export declarations and use
+	// import.meta and await at the top level, and they are always strict.
+	SourceType ast.SourceType
+	// Comments collects comments onto Program.Comments and attaches them to
+	// the nearest statements and expressions in Program.CommentMap.
+	Comments bool
+	// Tolerant resynchronizes at the next statement after a syntax error
+	// instead of skipping ahead to the next statement keyword, so that as
+	// much of a broken source as possible ends up in the program. Failed
+	// parts are represented by BadStatement and InvalidExpression nodes and
+	// the returned error is an *ErrorList holding every SyntaxError.
+	Tolerant bool
+	// JSX enables JSX elements and fragments in expression position. The
+	// resolver and the other transforms do not see references in JSX names,
+	// so lower JSX with the transform/jsx package before running them.
+	JSX bool
+	// TypeScript enables TypeScript syntax and strips it to plain JavaScript:
+	// type annotations, type parameters and arguments, interfaces, type
+	// aliases, declare statements, type-only imports and exports, as and
+	// satisfies expressions, non-null assertions, overloads and parameter
+	// decorators are dropped.
+	// Enums and parameter properties are lowered to regular statements, and
+	// imports that are never referenced are removed, as they may only import
+	// types. Namespaces are only supported if they declare types alone.
+	TypeScript bool
+	// Limits bounds the work spent on hostile input. MaxDepth limits the
+	// nesting of statements, expressions, patterns and types, MaxNodes the
+	// number of statements and expressions created, and once Context is done
+	// parsing stops. An exceeded limit fails the whole parse with one of the
+	// errors of package limit instead of an *ErrorList.
+	Limits limit.Limits
+	// NoWebCompat turns off the web compatibility extensions of Annex B of
+	// the specification for scripts: HTML-like comments, function
+	// declarations as the body of an if or labelled statement and
+	// initializers in for-in heads of var declarations. Modules never have
+	// them. Program.WebCompat passes the setting on to the resolver, which
+	// hoists functions declared in blocks only with web compatibility. Legacy
+	// octal literals and escapes belong to the language itself and stay
+	// allowed in sloppy mode code either way.
+	NoWebCompat bool
+}
+
 // ParseFile parses the source code of a single JavaScript/ECMAScript source file and returns
 // the corresponding ast.Program node.
 func ParseFile(src string) (*ast.Program, error) {
-	return newParser(src).parse()
+	return ParseFileWithOptions(src, Options{})
 }
 
-// parse ...
-func (p *parser) parse() (*ast.Program, error) {
+// ParseFileWithOptions is like ParseFile but parses according to opts.
+//
+// The returned program is never nil, even when err is not. It is empty when
+// a limit of opts.Limits was exceeded.
+func ParseFileWithOptions(src string, opts Options) (*ast.Program, error) {
+	p := newParser(src)
+	p.opts = opts
+	return p.parse()
+}
+
+// ParseBytes is like ParseFileWithOptions but parses src without copying it
+// to a string. The literals and names of the program share its memory, so
+// src must not be modified while the program is in use.
+func ParseBytes(src []byte, opts Options) (*ast.Program, error) {
+	return ParseFileWithOptions(unsafe.String(unsafe.SliceData(src), len(src)), opts)
+}
+
+// ParseReader is like ParseBytes but parses the source read from r. If r
+// reports its size through a Len or Stat method, like *os.File,
+// *bytes.Reader and *strings.Reader do, the source is read into a buffer of
+// that size, so that even huge sources are held in memory only once.
+//
+// If reading fails, the returned program is empty and the read error is
+// returned.
+func ParseReader(r io.Reader, opts Options) (*ast.Program, error) {
+	src, err := readAll(r)
+	if err != nil {
+		return &ast.Program{}, err
+	}
+	return ParseBytes(src, opts)
+}
+
+// readAll is like io.ReadAll, but starts with a buffer of the size that r
+// reports, if any.
+func readAll(r io.Reader) ([]byte, error) {
+	size := 512
+	switch r := r.(type) {
+	case interface{ Len() int }:
+		size = r.Len()
+	case interface{ Stat() (fs.FileInfo, error) }:
+		if info, err := r.Stat(); err == nil && info.Mode().IsRegular() {
+			size = int(info.Size())
+		}
+	}
+	// One more byte lets the final read see the end without growing.
+	buf := make([]byte, 0, size+1)
+	for {
+		n, err := r.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		if err == io.EOF {
+			return buf, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(buf) == cap(buf) {
+			buf = append(buf, 0)[:len(buf)]
+		}
+	}
+}
+
+// Parser parses one source after the other, reusing memory between parses.
+// The most common nodes of a program, such as identifiers, literals, calls
+// and member expressions, are allocated in blocks, which the Parser takes
+// back when the program is released with Program.Release. A batch of parses
+// whose programs are released once they have been processed thus allocates
+// the blocks only once, which saves bytes rather than allocations: every
+// parse allocates its nodes in blocks.
+//
+// A Parser must not be used by multiple goroutines at once, but programs
+// may be released from any goroutine. Use one Parser per goroutine or keep
+// them in a sync.Pool.
+type Parser struct {
+	p parser
+}
+
+// NewParser returns a Parser ready for use.
+func NewParser() *Parser {
+	pr := &Parser{p: *newParser("")}
+	pr.Reset()
+	return pr
+}
+
+// Reset drops everything the Parser holds from the last parse except its
+// memory for reuse, such as the source and its comments. Parse resets the
+// Parser itself, so Reset is only needed to let go of these before the
+// Parser sits idle, for example before putting it back into a pool.
+func (pr *Parser) Reset() {
+	pr.p = parser{
+		chr:    ' ',
+		arenas: pr.p.arenas,
+	}
+}
+
+// Parse parses src according to opts like ParseFileWithOptions. Release the
+// returned program once it is no longer needed to have its memory reused.
+func (pr *Parser) Parse(src string, opts Options) (*ast.Program, error) {
+	pr.Reset()
+	p := &pr.p
+	p.str = src
+	p.length = len(src)
+	p.opts = opts
+	program, err := p.parse()
+	program.OnRelease = p.arenas.take()
+	return program, err
+}
+
+// ParseExpression parses src as a single expression according to opts, as
+// if it appeared in the top level of a script or module. It is an error if
+// anything but whitespace and comments follows the expression. The comments
+// are not kept.
+//
+// The positions of the returned nodes are shifted by offset, so that a
+// snippet found at byte offset n of a host file lines up with the host when
+// parsed with an offset of n. The positions in a returned error are relative
+// to src.
+//
+// The returned expression is nil when a limit of opts.Limits was exceeded.
+func ParseExpression(src string, offset ast.Idx, opts Options) (expr *ast.Expression, err error) {
+	p := newParser(src)
+	p.base = offset
+	p.opts = opts
+	defer limit.Catch(&err)
+	p.begin()
+	defer p.closeScope()
+	p.next()
+	expr = p.makeExpr(p.parseExpression())
+	if p.token != token.Eof {
+		p.errorUnexpectedToken(p.token)
+	}
+	return expr, p.errors.Err()
+}
+
+// ParseStatements parses src as a list of statements according to opts, as
+// if they appeared in the top level of a script or module. Positions are
+// shifted by offset and limits are handled just like with ParseExpression.
+func ParseStatements(src string, offset ast.Idx, opts Options) (list ast.Statements, err error) {
+	p := newParser(src)
+	p.base = offset
+	p.opts = opts
+	defer limit.Catch(&err)
+	p.begin()
+	defer p.closeScope()
+	p.next()
+	list = p.parseStatementList()
+	if p.token != token.Eof {
+		p.errorUnexpectedToken(p.token)
+	}
+	return list, p.errors.Err()
+}
+
+// begin starts tracking the limits of the options and opens the scope of the
+// top level.
+func (p *parser) begin() {
+	p.limits.Start(p.opts.Limits)
 	p.openScope()
+	if p.isModule() {
+		// Module code is always strict and may use await at the top level.
+		p.scope.strict = true
+		p.scope.inAsync = true
+		p.scope.allowAwait = true
+	}
+}
+
+// parse ...
+func (p *parser) parse() (program *ast.Program, err error) {
+	// The empty program is returned when a limit is exceeded.
+	program = &ast.Program{}
+	defer limit.Catch(&err)
+	p.begin()
 	defer p.closeScope()
 	p.next()
-	program := p.parseProgram()
-	return program, p.errors.Err()
+	program = p.parseProgram()
+	if p.opts.TypeScript {
+		removeUnusedImports(program)
+	}
+	if p.opts.Comments {
+		program.Comments = p.comments
+		program.CommentMap = attachComments(program, p.str, p.comments)
+	}
+	program.Source = p.source()
+	if len(p.errors) == 0 {
+		return program, nil
+	}
+	// The list must not point into the parser, which a Parser reuses.
+	errs := p.errors
+	return program, &errs
+}
+
+// source returns the line index of the source text, building it on first use.
+func (p *parser) source() *ast.SourceFile {
+	if p.file == nil {
+		p.file = ast.NewSourceFile(p.str)
+	}
+	return p.file
+}
+
+func (p *parser) isModule() bool {
+	return p.opts.SourceType == ast.SourceTypeModule
+}
+
+// webCompat reports whether the extensions of Annex B are enabled, which the
+// statement level ones further restrict to sloppy mode code.
+func (p *parser) webCompat() bool {
+	return !p.opts.NoWebCompat && !p.isModule()
 }
 
 // next ...
@@ -95,7 +361,12 @@ func (p *parser) semicolon() {
 }
 
 func (p *parser) idxOf(offset int) ast.Idx {
-	return ast.Idx(1 + offset)
+	return p.base + ast.Idx(1+offset)
+}
+
+// offsetOf is the inverse of idxOf.
+func (p *parser) offsetOf(idx ast.Idx) int {
+	return int(idx-p.base) - 1
 }
 
 func (p *parser) expect(value token.Token) ast.Idx {
@@ -107,14 +378,92 @@ func (p *parser) expect(value token.Token) ast.Idx {
 	return idx
 }
 
+// expectClosing is like expect for the token that closes the bracket, brace
+// or parenthesis at open, to which an error relates.
+func (p *parser) expectClosing(value token.Token, open ast.Idx) ast.Idx {
+	idx := p.idx
+	if p.token != value {
+		p.errorUnclosed(value, open)
+	}
+	p.next()
+	return idx
+}
+
+// expectComma expects the comma between two elements of a list that closing
+// ends, relating an error to the opening token at open.
+func (p *parser) expectComma(closing token.Token, open ast.Idx) {
+	if p.token != token.Comma {
+		p.errorUnclosed(closing, open)
+	}
+	p.next()
+}
+
+// errorUnclosed reports the current token, which should have been closing,
+// and relates the error to the bracket, brace or parenthesis at open.
+func (p *parser) errorUnclosed(closing token.Token, open ast.Idx) {
+	e := p.errorUnexpectedToken(p.token)
+	opening := map[token.Token]byte{
+		token.RightBrace:       '{',
+		token.RightBracket:     '[',
+		token.RightParenthesis: '(',
+	}[closing]
+	// The opening token may be missing as well.
+	if offset := p.offsetOf(open); offset < len(p.str) && p.str[offset] == opening {
+		e.Related = p.span(open, 1, fmt.Sprintf("The '%c' opened here", opening))
+		if p.token == token.Eof {
+			e.Hint = fmt.Sprintf("Add the missing '%s'", closing)
+		}
+	}
+}
+
 func (p *parser) makeExpr(expr ast.Expr) *ast.Expression {
-	expression := p.exprArena.make()
+	p.limits.Count()
+	expression := p.arenas.exprs.make()
 	expression.Expr = expr
 	return expression
 }
 
+func (p *parser) makeIdent(idx ast.Idx, name string) *ast.Identifier {
+	p.limits.Count()
+	ident := p.arenas.idents.make()
+	ident.Idx = idx
+	ident.Name = name
+	return ident
+}
+
 func (p *parser) makeStmt(stmt ast.Stmt) *ast.Statement {
-	statement := p.stmtArena.make()
+	p.limits.Count()
+	statement := p.arenas.stmts.make()
 	statement.Stmt = stmt
 	return statement
 }
+
+func (p *parser) makeString(idx ast.Idx, value, raw string) *ast.StringLiteral {
+	lit := p.arenas.strings.make()
+	lit.Idx = idx
+	lit.Value = value
+	lit.Raw = &raw
+	return lit
+}
+
+func (p *parser) makeNumber(idx ast.Idx, value float64, raw string) *ast.NumberLiteral {
+	lit := p.arenas.numbers.make()
+	lit.Idx = idx
+	lit.Value = value
+	lit.Raw = &raw
+	return lit
+}
+
+func (p *parser) makeMember(object *ast.Expression, prop ast.MemberProp) *ast.MemberExpression {
+	member := p.arenas.members.make()
+	member.Object = object
+	member.Property = p.arenas.props.make()
+	member.Property.Prop = prop
+	return member
+}
+
+func (p *parser) makeTarget(target ast.Target) *ast.BindingTarget {
+	bindingTarget := p.arenas.targets.make()
+	bindingTarget.Target = target
+	return bindingTarget
+}
@@ -1,9 +1,18 @@
 package parser_test
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
+	"testing/iotest"
 
+	"github.com/t14raptor/go-fast/ast"
+	"github.com/t14raptor/go-fast/generator"
+	"github.com/t14raptor/go-fast/limit"
 	"github.com/t14raptor/go-fast/parser"
+	"github.com/t14raptor/go-fast/resolver"
+	"github.com/t14raptor/go-fast/token"
 )
 
 func TestIssue26(t *testing.T) {
@@ -17,3 +26,1240 @@ for (a.b in c) {
 		t.Fatalf("Failed to parse code: %v", err)
 	}
 }
+
+func TestModuleDeclarations(t *testing.T) {
+	code := `import "side-effect";
+import def, * as ns from "a";
+import { x, y as z, "str" as w } from "b";
+export * as all from "c";
+export { x as v, z } from "d";
+export const a = 1;
+export function f() {}
+export default class {}`
+	opts := parser.Options{SourceType: ast.SourceTypeModule}
+	program, err := parser.ParseFileWithOptions(code, opts)
+	if err != nil {
+		t.Fatalf("Failed to parse code: %v", err)
+	}
+
+	imp, ok := program.Body[2].Stmt.(*ast.ImportDeclaration)
+	if !ok {
+		t.Fatalf("Expected ImportDeclaration, got %T", program.Body[2].Stmt)
+	}
+	if len(imp.Specifiers) != 3 || imp.Source.Value != "b" {
+		t.Fatalf("Unexpected import declaration: %+v", imp)
+	}
+	if spec := imp.Specifiers[1].Specifier.(*ast.ImportNamedSpecifier); spec.Local.Name != "z" {
+		t.Errorf("Expected local binding z, got %s", spec.Local.Name)
+	}
+
+	if _, ok := program.Body[7].Stmt.(*ast.ExportDefaultDeclaration); !ok {
+		t.Fatalf("Expected ExportDefaultDeclaration, got %T", program.Body[7].Stmt)
+	}
+
+	for _, code := range []string{
+		`if (a) import "b";`,
+		`function f() { export const a = 1; }`,
+		`export { "a" };`,
+		`import { default } from "a";`,
+	} {
+		if _, err := parser.ParseFileWithOptions(code, opts); err == nil {
+			t.Errorf("Expected error for %q", code)
+		}
+	}
+
+	// Scripts have no import and export declarations.
+	_, err = parser.ParseFile(`import x from 'y'; export const a = 1;`)
+	var list *parser.ErrorList
+	if !errors.As(err, &list) || len(*list) != 2 ||
+		(*list)[0].Code != parser.CodeImportOutsideModule || (*list)[0].Offset != 0 || (*list)[0].Length != 6 ||
+		(*list)[1].Code != parser.CodeExportOutsideModule || (*list)[1].Offset != 19 || (*list)[1].Length != 6 {
+		t.Errorf("Expected import and export to be rejected in a script, got %v", err)
+	}
+}
+
+func TestSourceTypeModule(t *testing.T) {
+	opts := parser.Options{SourceType: ast.SourceTypeModule}
+
+	program, err := parser.ParseFileWithOptions(`const data = await fetch(url);`, opts)
+	if err != nil {
+		t.Fatalf("Failed to parse code: %v", err)
+	}
+	if program.SourceType != ast.SourceTypeModule {
+		t.Errorf("Expected module source type, got %s", program.SourceType)
+	}
+
+	if _, err := parser.ParseFile(`const data = await fetch(url);`); err == nil {
+		t.Error("Expected error for top-level await in script")
+	}
+
+	for _, code := range []string{
+		`with (obj) {}`,
+		`var let = 1;`,
+		`function f() { var await; }`,
+	} {
+		if _, err := parser.ParseFile(code); err != nil {
+			t.Errorf("Unexpected error for %q in script: %v", code, err)
+		}
+		if _, err := parser.ParseFileWithOptions(code, opts); err == nil {
+			t.Errorf("Expected error for %q in module", code)
+		}
+	}
+
+	for _, code := range []string{
+		`import x from "y";`,
+		`import "y";`,
+		`export const a = 1;`,
+		`export * from "y";`,
+		`x(import.meta.url);`,
+	} {
+		if _, err := parser.ParseFileWithOptions(code, opts); err != nil {
+			t.Errorf("Unexpected error for %q in module: %v", code, err)
+		}
+		if _, err := parser.ParseFile(code); err == nil {
+			t.Errorf("Expected error for %q in script", code)
+		}
+	}
+	if _, err := parser.ParseFile(`import("y").then(f);`); err != nil {
+		t.Errorf("Unexpected error for a dynamic import in script: %v", err)
+	}
+}
+
+func TestModuleEarlyErrors(t *testing.T) {
+	opts := parser.Options{SourceType: ast.SourceTypeModule}
+
+	for code, want := range map[string]parser.SyntaxError{
+		"var a, c; export {a as b, c as b}": {Code: parser.CodeDuplicateExport, Offset: 31, Length: 1,
+			Related: &parser.Span{Message: "The first export", Line: 1, Column: 24, Offset: 23, Length: 1}},
+		"export default 1; export default 2": {Code: parser.CodeDuplicateExport, Offset: 18, Length: 6,
+			Related: &parser.Span{Message: "The first export", Line: 1, Column: 1, Offset: 0, Length: 6}},
+		"export function f() {} export {f}": {Code: parser.CodeDuplicateExport, Offset: 31, Length: 1,
+			Related: &parser.Span{Message: "The first export", Line: 1, Column: 17, Offset: 16, Length: 1}},
+		"export {undeclared}": {Code: parser.CodeUndeclaredExport, Offset: 8, Length: 10},
+		`import {a} from "x"; import {a} from "y";`: {Code: parser.CodeDuplicateImport, Offset: 29, Length: 1,
+			Related: &parser.Span{Message: "The first declaration", Line: 1, Column: 9, Offset: 8, Length: 1}},
+		`import {a} from "x"; let a;`: {Code: parser.CodeDuplicateImport, Offset: 8, Length: 1,
+			Related: &parser.Span{Message: "The first declaration", Line: 1, Column: 26, Offset: 25, Length: 1}},
+	} {
+		_, err := parser.ParseFileWithOptions(code, opts)
+		var list *parser.ErrorList
+		if !errors.As(err, &list) || len(*list) != 1 {
+			t.Errorf("Expected one error for %q, got %v", code, err)
+			continue
+		}
+		e := (*list)[0]
+		if e.Code != want.Code || e.Offset != want.Offset || e.Length != want.Length ||
+			(e.Related == nil) != (want.Related == nil) || e.Related != nil && *e.Related != *want.Related {
+			t.Errorf("Unexpected error for %q: %+v, related %+v", code, e, e.Related)
+		}
+	}
+
+	for _, code := range []string{
+		`if (a) { var a } export {a as b, a as c}`,
+		`import {a} from "x"; export {a, a as default}`,
+		`export {a} from "x"; export {a as b} from "x"`,
+		`export default function f() {} export {f}`,
+		`export const {a, b: [c]} = o; export {a as d, c as e}`,
+	} {
+		if _, err := parser.ParseFileWithOptions(code, opts); err != nil {
+			t.Errorf("Unexpected error for %q: %v", code, err)
+		}
+	}
+
+	// The variables of a function are not declared by the module.
+	if _, err := parser.ParseFileWithOptions(`function f() { var a } export {a}`, opts); err == nil {
+		t.Error("Expected error for an export of a function's variable")
+	}
+}
+
+func TestImportExpression(t *testing.T) {
+	program, err := parser.ParseFileWithOptions(`import("./chunk.js", { with: { type: "json" } }); import.meta.url;`, parser.Options{SourceType: ast.SourceTypeModule})
+	if err != nil {
+		t.Fatalf("Failed to parse code: %v", err)
+	}
+
+	stmt := program.Body[0].Stmt.(*ast.ExpressionStatement)
+	imp, ok := stmt.Expression.Expr.(*ast.ImportExpression)
+	if !ok {
+		t.Fatalf("Expected ImportExpression, got %T", stmt.Expression.Expr)
+	}
+	if imp.Options == nil {
+		t.Error("Expected import options")
+	}
+
+	stmt = program.Body[1].Stmt.(*ast.ExpressionStatement)
+	member := stmt.Expression.Expr.(*ast.MemberExpression)
+	if meta, ok := member.Object.Expr.(*ast.MetaProperty); !ok || meta.Meta.Name != "import" || meta.Property.Name != "meta" {
+		t.Errorf("Expected import.meta, got %T", member.Object.Expr)
+	}
+
+	for _, code := range []string{
+		`import.meta;`,
+		`new import("x");`,
+		`import();`,
+	} {
+		if _, err := parser.ParseFile(code); err == nil {
+			t.Errorf("Expected error for %q", code)
+		}
+	}
+}
+
+func TestBigIntLiteral(t *testing.T) {
+	program, err := parser.ParseFile(`0x1fn; 123n; 0n; 0b101n; 0o17n; ({ 1n: a });`)
+	if err != nil {
+		t.Fatalf("Failed to parse code: %v", err)
+	}
+
+	for i, want := range []string{"31", "123", "0", "5", "15"} {
+		stmt := program.Body[i].Stmt.(*ast.ExpressionStatement)
+		lit, ok := stmt.Expression.Expr.(*ast.BigIntLiteral)
+		if !ok {
+			t.Fatalf("Expected BigIntLiteral, got %T", stmt.Expression.Expr)
+		}
+		if lit.Value.String() != want {
+			t.Errorf("Expected %s, got %s", want, lit.Value)
+		}
+	}
+
+	for _, code := range []string{
+		`1.5n;`,
+		`1e3n;`,
+		`017n;`,
+		`1nn;`,
+	} {
+		if _, err := parser.ParseFile(code); err == nil {
+			t.Errorf("Expected error for %q", code)
+		}
+	}
+}
+
+func TestLogicalAssignment(t *testing.T) {
+	program, err := parser.ParseFile(`a &&= b; a ||= b; a.b ??= c;`)
+	if err != nil {
+		t.Fatalf("Failed to parse code: %v", err)
+	}
+
+	for i, want := range []token.Token{token.LogicalAnd, token.LogicalOr, token.Coalesce} {
+		stmt := program.Body[i].Stmt.(*ast.ExpressionStatement)
+		assign, ok := stmt.Expression.Expr.(*ast.AssignExpression)
+		if !ok {
+			t.Fatalf("Expected AssignExpression, got %T", stmt.Expression.Expr)
+		}
+		if assign.Operator != want {
+			t.Errorf("Expected operator %s, got %s", want, assign.Operator)
+		}
+	}
+
+	if _, err := parser.ParseFile(`[a] ||= b;`); err == nil {
+		t.Error("Expected error for destructuring logical assignment")
+	}
+}
+
+func TestNumericSeparators(t *testing.T) {
+	for _, test := range []struct {
+		code  string
+		value float64
+	}{
+		{`1_000_000`, 1000000},
+		{`0b1010_1010`, 0xaa},
+		{`0xdead_beef`, 0xdeadbeef},
+		{`0o7_7`, 077},
+		{`1_0.0_1e1_0`, 10.01e10},
+		{`.5_5`, .55},
+	} {
+		program, err := parser.ParseFile(test.code)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", test.code, err)
+		}
+		lit := program.Body[0].Stmt.(*ast.ExpressionStatement).Expression.Expr.(*ast.NumberLiteral)
+		if lit.Value != test.value {
+			t.Errorf("%q: expected %v, got %v", test.code, test.value, lit.Value)
+		}
+		if lit.Raw == nil || *lit.Raw != test.code {
+			t.Errorf("%q: expected raw to be preserved", test.code)
+		}
+		if got := generator.Generate(program); !strings.Contains(got, test.code) {
+			t.Errorf("%q: expected verbatim output, got %q", test.code, got)
+		}
+	}
+
+	program, err := parser.ParseFile(`1_0n;`)
+	if err != nil {
+		t.Fatalf("Failed to parse code: %v", err)
+	}
+	if lit := program.Body[0].Stmt.(*ast.ExpressionStatement).Expression.Expr.(*ast.BigIntLiteral); lit.Value.Int64() != 10 {
+		t.Errorf("Expected 10n, got %sn", lit.Value)
+	}
+
+	for _, code := range []string{
+		`1__0;`,
+		`1_;`,
+		`1_.5;`,
+		`1._5;`,
+		`1e_5;`,
+		`1e5_;`,
+		`0x_1;`,
+		`0b_1;`,
+		`0_1;`,
+		`07_7;`,
+		`1_n;`,
+	} {
+		if _, err := parser.ParseFile(code); err == nil {
+			t.Errorf("Expected error for %q", code)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	code := `/*! license */
+// @ts-ignore
+const a = /*#__PURE__*/ foo(); // tail
+if (a) b(); // after b
+else c();
+const f = (x) => /* body */ x;`
+	program, err := parser.ParseFileWithOptions(code, parser.Options{Comments: true})
+	if err != nil {
+		t.Fatalf("Failed to parse code: %v", err)
+	}
+
+	expected := []string{"/*! license */", "// @ts-ignore", "/*#__PURE__*/", "// tail", "// after b", "/* body */"}
+	if len(program.Comments) != len(expected) {
+		t.Fatalf("Expected %d comments, got %d", len(expected), len(program.Comments))
+	}
+	for i, c := range program.Comments {
+		if got := code[c.Idx0()-1 : c.Idx1()-1]; got != expected[i] {
+			t.Errorf("Comment %d: expected %q, got %q", i, expected[i], got)
+		}
+	}
+
+	decl := program.Body[0].Stmt
+	if c := program.CommentMap[decl]; c == nil || len(c.Leading) != 1 || len(c.Trailing) != 1 {
+		t.Errorf("Expected one leading and one trailing comment on the declaration, got %+v", c)
+	}
+	// The license is the file header, which belongs to the program.
+	if c := program.CommentMap[program]; c == nil || len(c.Leading) != 1 || c.Leading[0].Text != "! license " {
+		t.Errorf("Expected the license to lead the program, got %+v", c)
+	}
+
+	out := generator.Generate(program)
+	for _, comment := range expected {
+		if !strings.Contains(out, comment) {
+			t.Errorf("Expected %q in output:\n%s", comment, out)
+		}
+	}
+	if _, err := parser.ParseFile(out); err != nil {
+		t.Errorf("Failed to reparse output: %v\n%s", err, out)
+	}
+
+	program, err = parser.ParseFile(code)
+	if err != nil {
+		t.Fatalf("Failed to parse code: %v", err)
+	}
+	if program.Comments != nil || program.CommentMap != nil {
+		t.Error("Expected no comments without the option")
+	}
+}
+
+func TestTolerant(t *testing.T) {
+	code := `let a = ; b();
+function f() {
+	let x = 1 +;
+	more();
+}
+} after();
+switch (x) { case 1: }
+({a} = c`
+	program, err := parser.ParseFileWithOptions(code, parser.Options{Tolerant: true})
+	if program == nil {
+		t.Fatal("Expected a program")
+	}
+	var list *parser.ErrorList
+	if !errors.As(err, &list) || len(*list) != 4 {
+		t.Fatalf("Expected 4 errors, got %v", err)
+	}
+
+	out := generator.Generate(program)
+	for _, call := range []string{"b()", "more()", "after()", "switch (x)"} {
+		if !strings.Contains(out, call) {
+			t.Errorf("Expected %q to be recovered, got:\n%s", call, out)
+		}
+	}
+
+	program, err = parser.ParseFile(code)
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+	if out := generator.Generate(program); strings.Contains(out, "after()") {
+		t.Errorf("Expected the default mode to skip to the next statement keyword, got:\n%s", out)
+	}
+}
+
+func TestTolerantResolve(t *testing.T) {
+	code := `x = 1;
+async function* f(a, {b, c = 2}, ...[d, ...e]) { yield await a; }
+function g(...rest) { return class A extends B { m(...x) { return x; } }; }
+const h = async (a, ...b) => ({a, ...b});
+try { h(); } catch ({message}) { label: for (let [i] of a) break label; }
+switch (x) { case 1: let y; default: }`
+	// Every truncation of the code has to give a tree the resolver and the
+	// generator can walk.
+	for i := range len(code) {
+		program, _ := parser.ParseFileWithOptions(code[:i], parser.Options{Tolerant: true})
+		if program == nil {
+			t.Fatalf("Expected a program for %q", code[:i])
+		}
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("Resolving %q panicked: %v", code[:i], r)
+				}
+			}()
+			resolver.Resolve(program)
+			generator.Generate(program)
+		}()
+	}
+}
+
+func TestDirectives(t *testing.T) {
+	program, err := parser.ParseFile(`'use strict'; "other"
+function f(a, b) { "use asm"; return a }
+function g() { ("not a directive") }`)
+	if err != nil {
+		t.Fatalf("Failed to parse code: %v", err)
+	}
+	if !program.Strict {
+		t.Error("Expected the program to be strict")
+	}
+	if len(program.Directives) != 2 || program.Directives[0].Value != "use strict" || program.Directives[1].Raw != `"other"` {
+		t.Errorf("Unexpected directives %+v", program.Directives)
+	}
+	f := program.Body[0].Stmt.(*ast.FunctionDeclaration).Function
+	if !f.Strict || len(f.Body.Directives) != 1 || f.Body.Directives[0].Value != "use asm" || len(f.Body.List) != 1 {
+		t.Errorf("Unexpected body of f: %+v", f.Body)
+	}
+	g := program.Body[1].Stmt.(*ast.FunctionDeclaration).Function
+	if len(g.Body.Directives) != 0 || len(g.Body.List) != 1 {
+		t.Errorf("Expected a parenthesized string to be a statement, got %+v", g.Body)
+	}
+	if out := generator.Generate(program); !strings.Contains(out, "'use strict';") || !strings.Contains(out, `"use asm";`) {
+		t.Errorf("Expected directives in the output, got:\n%s", out)
+	}
+
+	program, err = parser.ParseFile(`function f() { "use strict" } function g(a, a) { return 010 }`)
+	if err != nil {
+		t.Fatalf("Failed to parse code: %v", err)
+	}
+	if program.Strict || program.Body[1].Stmt.(*ast.FunctionDeclaration).Function.Strict {
+		t.Error("Expected strictness to be limited to f")
+	}
+}
+
+func TestStrictMode(t *testing.T) {
+	for _, code := range []string{
+		`"use strict"; with (a) {}`,
+		`"use strict"; 010;`,
+		`"use strict"; "\07";`,
+		`"\07"; "use strict";`,
+		`"use strict"; "\8";`,
+		`"use strict"; 08.5;`,
+		`"use strict"; ({ 010: 1 });`,
+		`"use strict"; delete x;`,
+		`"use strict"; var eval;`,
+		`"use strict"; arguments = 1;`,
+		`"use strict"; eval++;`,
+		`"use strict"; ({ eval } = x);`,
+		`"use strict"; ({ ...eval } = x);`,
+		`"use strict"; for (eval in a);`,
+		`"use strict"; for (arguments of a);`,
+		`"use strict"; for ([eval] of a);`,
+		`"use strict"; for ({ x: arguments } in a);`,
+		`"use strict"; for ({ ...eval } of a);`,
+		`"use strict"; try {} catch (arguments) {}`,
+		`function f(a, a) { "use strict" }`,
+		`function eval() { "use strict" }`,
+		`function f(a = 1) { "use strict" }`,
+		`function f({ a }) { "use strict" }`,
+		`(a, a) => 1;`,
+		`({ m(a, a) {} });`,
+		`class A { m() { with (a) {} } }`,
+		`class eval {}`,
+		`with (a) {}`,
+	} {
+		sourceType := ast.SourceTypeScript
+		if code == `with (a) {}` {
+			sourceType = ast.SourceTypeModule
+		}
+		if _, err := parser.ParseFileWithOptions(code, parser.Options{SourceType: sourceType}); err == nil {
+			t.Errorf("Expected error for %q", code)
+		}
+	}
+
+	for _, code := range []string{
+		`with (a) {} 010; "\07"; delete x; var eval; arguments = 1;`,
+		`08; 09.5; 019e1;`,
+		`function f(a, a) {}`,
+		`function f(a = 1) {}`,
+		`("use strict"); with (a) {}`,
+		`function f() { "use strict" } with (a) {}`,
+		`"use strict"; delete x.y; 0; 0.5; "\0";`,
+		`for (eval in a); for ([arguments] of a);`,
+	} {
+		if _, err := parser.ParseFile(code); err != nil {
+			t.Errorf("Unexpected error for %q: %v", code, err)
+		}
+	}
+}
+
+func TestParseExpression(t *testing.T) {
+	expr, err := parser.ParseExpression(`a + b(1)`, 0, parser.Options{})
+	if err != nil {
+		t.Fatalf("Failed to parse expression: %v", err)
+	}
+	if _, ok := expr.Expr.(*ast.BinaryExpression); !ok {
+		t.Fatalf("Expected a binary expression, got %T", expr.Expr)
+	}
+	if expr.Expr.Idx0() != 1 || expr.Expr.Idx1() != 9 {
+		t.Errorf("Unexpected position %d-%d", expr.Expr.Idx0(), expr.Expr.Idx1())
+	}
+
+	host := `let x = foo(bar);`
+	expr, err = parser.ParseExpression(host[8:16], 8, parser.Options{})
+	if err != nil {
+		t.Fatalf("Failed to parse expression: %v", err)
+	}
+	program, err := parser.ParseFile(host)
+	if err != nil {
+		t.Fatalf("Failed to parse code: %v", err)
+	}
+	orig := program.Body[0].Stmt.(*ast.VariableDeclaration).List[0].Initializer.Expr
+	if expr.Expr.Idx0() != orig.Idx0() || expr.Expr.Idx1() != orig.Idx1() {
+		t.Errorf("Expected position %d-%d, got %d-%d", orig.Idx0(), orig.Idx1(), expr.Expr.Idx0(), expr.Expr.Idx1())
+	}
+
+	for _, code := range []string{``, `a b`, `a;`, `a)`, `let x = 1`} {
+		if _, err := parser.ParseExpression(code, 0, parser.Options{}); err == nil {
+			t.Errorf("Expected error for %q", code)
+		}
+	}
+
+	_, err = parser.ParseExpression("a +", 100, parser.Options{})
+	var list *parser.ErrorList
+	if !errors.As(err, &list) || (*list)[0].Offset != 3 {
+		t.Errorf("Expected an error relative to the source, got %v", err)
+	}
+
+	expr, err = parser.ParseExpression(`<a>{b as T}</a>`, 0, parser.Options{JSX: true, TypeScript: true})
+	if err != nil {
+		t.Errorf("Unexpected error with JSX and TypeScript: %v", err)
+	} else if _, ok := expr.Expr.(*ast.JSXElement); !ok {
+		t.Errorf("Expected a JSX element, got %T", expr.Expr)
+	}
+	if _, err := parser.ParseExpression(`await x`, 0, parser.Options{SourceType: ast.SourceTypeModule}); err != nil {
+		t.Errorf("Expected await in a module expression, got %v", err)
+	}
+	expr, err = parser.ParseExpression(strings.Repeat("(", 100000)+"a"+strings.Repeat(")", 100000), 0, parser.Options{Limits: limit.Limits{MaxDepth: 1000}})
+	if !errors.As(err, new(*limit.DepthError)) || expr != nil {
+		t.Errorf("Expected a depth error, got %v", err)
+	}
+}
+
+func TestParseStatements(t *testing.T) {
+	list, err := parser.ParseStatements(`let a = 1; f(a) // done`, 10, parser.Options{})
+	if err != nil {
+		t.Fatalf("Failed to parse statements: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("Expected 2 statements, got %d", len(list))
+	}
+	if list[0].Stmt.Idx0() != 11 || list[1].Stmt.Idx1() != 26 {
+		t.Errorf("Unexpected position %d-%d", list[0].Stmt.Idx0(), list[1].Stmt.Idx1())
+	}
+
+	list, err = parser.ParseStatements(``, 0, parser.Options{})
+	if err != nil || len(list) != 0 {
+		t.Errorf("Expected no statements, got %v, %v", list, err)
+	}
+
+	list, err = parser.ParseStatements(strings.Repeat("a; ", 10000), 0, parser.Options{Limits: limit.Limits{MaxNodes: 1000}})
+	if !errors.As(err, new(*limit.NodeError)) || list != nil {
+		t.Errorf("Expected a node error, got %v", err)
+	}
+
+	for _, code := range []string{`a; }`, `import x from "y";`, `return 1;`} {
+		if _, err := parser.ParseStatements(code, 0, parser.Options{}); err == nil {
+			t.Errorf("Expected error for %q", code)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	code := "a = b / 2 // half\nx = /[/]/g.test(`${y}s`) /* end */"
+	type tok struct {
+		kind    token.Token
+		literal string
+		newLine bool
+	}
+	var got []tok
+	for tk := range parser.Tokenize(code, parser.TokenizeOptions{Comments: true}) {
+		if code[tk.Idx-1:tk.Idx1()-1] != tk.Literal {
+			t.Errorf("Position of %q does not match its literal", tk.Literal)
+		}
+		got = append(got, tok{tk.Kind, tk.Literal, tk.NewLineBefore})
+	}
+	want := []tok{
+		{token.Identifier, "a", false},
+		{token.Assign, "=", false},
+		{token.Identifier, "b", false},
+		{token.Slash, "/", false},
+		{token.Number, "2", false},
+		{token.Comment, "// half", false},
+		{token.Identifier, "x", true},
+		{token.Assign, "=", false},
+		{token.RegExp, "/[/]/g", false},
+		{token.Period, ".", false},
+		{token.Identifier, "test", false},
+		{token.LeftParenthesis, "(", false},
+		{token.Template, "`${", false},
+		{token.Identifier, "y", false},
+		{token.Template, "}s`", false},
+		{token.RightParenthesis, ")", false},
+		{token.Comment, "/* end */", false},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d tokens, got %d: %v", len(want), len(got), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Token %d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+
+	var literals []string
+	for tk := range parser.Tokenize("#!node\n'\\x41' /*", parser.TokenizeOptions{Comments: true, Whitespace: true}) {
+		literals = append(literals, tk.Kind.String()+" "+tk.Literal+" "+tk.Parsed)
+	}
+	if strings.Join(literals, "|") != "Comment #!node node|Whitespace \n |String '\\x41' A|Whitespace   |Illegal /* " {
+		t.Errorf("Unexpected tokens %q", literals)
+	}
+}
+
+func TestJSX(t *testing.T) {
+	opts := parser.Options{JSX: true}
+	for _, code := range []string{
+		`<div className="a" {...props} data-id={1} disabled>text &amp; {value}</div>;`,
+		`f(<>a<b>c</b></>);`,
+		`() => <Foo.Bar a:b='x' c=<i /> />;`,
+		`<ul>{items.map((item) => <li key={item}>{item}</li>)}</ul>;`,
+		`<a>{...children}</a>;`,
+		`<a>{}</a> < b;`,
+	} {
+		program, err := parser.ParseFileWithOptions(code, opts)
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", code, err)
+			continue
+		}
+		if len(program.Body) != 1 {
+			t.Errorf("Expected 1 statement for %q, got %d", code, len(program.Body))
+		}
+		if got := generator.Generate(program); strings.TrimSpace(got) != code {
+			t.Errorf("Expected %q to be generated as is, got %q", code, got)
+		}
+	}
+
+	program, _ := parser.ParseFileWithOptions("x = <a href=\"&lt;\">  1 &gt; 0 </a>", opts)
+	elem := program.Body[0].Stmt.(*ast.ExpressionStatement).Expression.Expr.(*ast.AssignExpression).Right.Expr.(*ast.JSXElement)
+	attr := elem.Opening.Attributes[0].Attribute.(*ast.JSXAttribute)
+	if attr.Name.Name != "href" || attr.Value.Expr.(*ast.StringLiteral).Value != "<" {
+		t.Errorf("Unexpected attribute %s=%v", attr.Name.Name, attr.Value.Expr)
+	}
+	text := elem.Children[0].Child.(*ast.JSXText)
+	if text.Value != "  1 > 0 " || text.Raw != "  1 &gt; 0 " {
+		t.Errorf("Unexpected text %q (%q)", text.Value, text.Raw)
+	}
+	if elem.Idx0() != 5 || elem.Idx1() != 35 {
+		t.Errorf("Unexpected element range %d-%d", elem.Idx0(), elem.Idx1())
+	}
+
+	program, _ = parser.ParseFileWithOptions(`x = <a b="&amp &copy; &#x41;&bogus;">&amp &copy &#66;&#xD800;</a>`, opts)
+	elem = program.Body[0].Stmt.(*ast.ExpressionStatement).Expression.Expr.(*ast.AssignExpression).Right.Expr.(*ast.JSXElement)
+	if value := elem.Opening.Attributes[0].Attribute.(*ast.JSXAttribute).Value.Expr.(*ast.StringLiteral).Value; value != "&amp © A&bogus;" {
+		t.Errorf("Unexpected attribute value %q", value)
+	}
+	if text := elem.Children[0].Child.(*ast.JSXText); text.Value != "&amp &copy B&#xD800;" {
+		t.Errorf("Expected references without a semicolon to be left as they are, got %q", text.Value)
+	}
+
+	for code, msg := range map[string]string{
+		`<a></b>`:        "Expected corresponding JSX closing tag for <a>",
+		`<a b={} />`:     "JSX attributes must only be assigned a non-empty expression",
+		`<a>`:            "Unterminated JSX contents",
+		`<a b={...c} />`: "Unexpected token ...",
+	} {
+		_, err := parser.ParseFileWithOptions(code, opts)
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("Expected %q for %q, got %v", msg, code, err)
+		}
+	}
+
+	program, _ = parser.ParseFileWithOptions("<p>{/* a */}{// b\n}</p>;", parser.Options{JSX: true, Comments: true})
+	if got := generator.Generate(program); !strings.Contains(got, "<p>{/* a */}{// b\n}</p>") {
+		t.Errorf("Expected comments to stay inside of empty containers, got %q", got)
+	}
+
+	if _, err := parser.ParseFile(`<a />`); err == nil {
+		t.Error("Expected JSX to be rejected without the JSX option")
+	}
+}
+
+func TestTypeScript(t *testing.T) {
+	opts := parser.Options{TypeScript: true, SourceType: ast.SourceTypeModule}
+	for code, want := range map[string]string{
+		`let x: Map<string, Array<number>>= new Map<string, number>();`:                                `let x = new Map();`,
+		`const f = <T,>(a: T, b?: string): T => a;`:                                                    `const f = (a, b) => a;`,
+		`const v = (el as HTMLInputElement)!.value satisfies string;`:                                  `const v = el.value;`,
+		`const y = <any>window, z = f<T>(1) < g;`:                                                      `const y = window, z = f(1) < g;`,
+		`const c = a < b && c > d, s = a >> b;`:                                                        `const c = a < b && c > d, s = a >> b;`,
+		`function f(a: string): void; function f(this: T, a: any) { return a; }`:                       `function f(a) { return a; }`,
+		"interface A { x: number }\ntype B<T> = T extends string ? `a${T}` : never;\nf();":             `f();`,
+		`declare const c: number; declare module "m" { export const x: number; } f();`:                 `f();`,
+		`namespace N { export type T = string; } f();`:                                                 `f();`,
+		`let type = 1, declare = type;`:                                                                `let type = 1, declare = type;`,
+		`class A { constructor(public a: number, private b = 2) { f(); } }`:                            `class A { constructor(a, b = 2) { this.a = a; this.b = b; f(); } }`,
+		`import type { A } from "a"; import { type B, c, d } from "b"; import e = require("e"); c(e);`: `import {c} from "b"; const e = require("e"); c(e);`,
+		`export type { A }; export interface I {} export default interface J {} export = x;`:           `module.exports = x;`,
+		`export default function (a: number) { return a; }`:                                            `export default function(a) { return a; }`,
+		`enum E { A, B = 5, C, D = "d", F = B << 1 }`:                                                  `var E = (function(E) { E[E["A"] = 0] = "A"; E[E["B"] = 5] = "B"; E[E["C"] = 6] = "C"; E["D"] = "d"; E[E["F"] = E.B << 1] = "F"; return E; })(E || ({}));`,
+		`let x = a ? (b) : c => d;`:                                                                    `let x = a ? b : (c) => d;`,
+		`let x = a ? (b): c => d : e;`:                                                                 `let x = a ? (b) => d : e;`,
+	} {
+		program, err := parser.ParseFileWithOptions(code, opts)
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", code, err)
+			continue
+		}
+		if got := strings.Join(strings.Fields(generator.Generate(program)), " "); got != want {
+			t.Errorf("Expected %q to be stripped to %q, got %q", code, want, got)
+		}
+	}
+
+	program, _ := parser.ParseFileWithOptions(`class C extends B {
+	declare a: string;
+	b?: number = 1;
+	abstract m(): void;
+	[key: string]: any;
+	n<T>(): T {}
+	constructor(readonly c) { super(); f(); }
+}`, opts)
+	class := program.Body[0].Stmt.(*ast.ClassDeclaration).Class
+	if class.SuperClass == nil || len(class.Body) != 3 {
+		t.Fatalf("Expected a derived class with 3 elements, got %d", len(class.Body))
+	}
+	ctor := class.Body[2].Element.(*ast.MethodDefinition).Body.Body.List
+	if len(ctor) != 3 {
+		t.Fatalf("Expected 3 statements in the constructor, got %d", len(ctor))
+	}
+	if _, ok := ctor[1].Stmt.(*ast.ExpressionStatement).Expression.Expr.(*ast.AssignExpression); !ok {
+		t.Errorf("Expected the parameter property to be assigned after the super call, got %T", ctor[1].Stmt)
+	}
+
+	program, _ = parser.ParseFileWithOptions(`import React from "react"; import type { P } from "p"; const C = <T,>(p: P<T>) => <a>{p as any}</a>;`,
+		parser.Options{TypeScript: true, JSX: true, SourceType: ast.SourceTypeModule})
+	if got := strings.Join(strings.Fields(generator.Generate(program)), " "); got != `import React from "react"; const C = (p) => <a>{p}</a>;` {
+		t.Errorf("Unexpected TSX output %q", got)
+	}
+
+	for code, msg := range map[string]string{
+		`namespace N { export const x = 1; }`: "TypeScript namespaces with runtime code are not supported",
+		`enum E { A = "a", B }`:               "Enum member must have initializer",
+		`let x: = 1;`:                         "Unexpected token =",
+	} {
+		_, err := parser.ParseFileWithOptions(code, opts)
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("Expected %q for %q, got %v", msg, code, err)
+		}
+	}
+
+	if _, err := parser.ParseFile(`let x: number;`); err == nil {
+		t.Error("Expected type annotations to be rejected without the TypeScript option")
+	}
+}
+
+func TestDecorators(t *testing.T) {
+	opts := parser.Options{SourceType: ast.SourceTypeModule}
+	for code, want := range map[string]string{
+		`@a @b.c(1) @(d[0]) class A {}`:                          `@a @b.c(1) @(d[0]) class A { }`,
+		`class A { @a m() {} @b static x = 1; @c accessor #y; }`: `class A { @a m() {} @b static x = 1; @c accessor #y; }`,
+		`class A { accessor = 1; accessor` + "\n" + `b; }`:       `class A { accessor = 1; accessor; b; }`,
+		`@a export class A {}`:                                   `export @a class A { }`,
+		`export default @a class {}`:                             `export default @a class { }`,
+		`const A = @a class {};`:                                 `const A = @a class { };`,
+	} {
+		program, err := parser.ParseFileWithOptions(code, opts)
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", code, err)
+			continue
+		}
+		if got := strings.Join(strings.Fields(generator.Generate(program)), " "); got != want {
+			t.Errorf("Expected %q to generate %q, got %q", code, want, got)
+		}
+	}
+
+	program, _ := parser.ParseFileWithOptions(`@Component({}) class C {
+	@Input() static accessor [name] = 1;
+}`, opts)
+	class := program.Body[0].Stmt.(*ast.ClassDeclaration).Class
+	if len(class.Decorators) != 1 || class.Idx0() != 1 {
+		t.Errorf("Expected the class to start at its decorator, got %d", class.Idx0())
+	}
+	accessor, ok := class.Body[0].Element.(*ast.ClassAccessorProperty)
+	if !ok || !accessor.Static || !accessor.Computed || len(accessor.Decorators) != 1 {
+		t.Errorf("Expected a static computed accessor with a decorator, got %#v", class.Body[0].Element)
+	}
+
+	program, err := parser.ParseFileWithOptions(`class C { constructor(@Inject(T) private readonly t: T) {} }`,
+		parser.Options{TypeScript: true})
+	if err != nil {
+		t.Errorf("Unexpected error for parameter decorators: %v", err)
+	} else if got := strings.Join(strings.Fields(generator.Generate(program)), " "); got != `class C { constructor(t) { this.t = t; } }` {
+		t.Errorf("Expected parameter decorators to be dropped, got %q", got)
+	}
+
+	for _, code := range []string{
+		`@a const x = 1;`,
+		`@a export const x = 1;`,
+		`@a export @b class A {}`,
+		`class A { @a static {} }`,
+		`class A { @a constructor() {} }`,
+		`x = @a function () {};`,
+		`function f(@a x) {}`,
+	} {
+		_, err := parser.ParseFileWithOptions(code, opts)
+		if err == nil {
+			t.Errorf("Expected an error for %q", code)
+		}
+	}
+}
+
+func TestUsingDeclarations(t *testing.T) {
+	opts := parser.Options{SourceType: ast.SourceTypeModule}
+	for code, want := range map[string]string{
+		`using a = f(), b = null;`:                          `using a = f(), b = null;`,
+		`await using a = f();`:                              `await using a = f();`,
+		`for (using a of b) {} for (await using a of b) {}`: `for (using a of b) {} for (await using a of b) {}`,
+		`for (using a = f(); ; ) break;`:                    `for (using a = f(); ; ) break;`,
+		"using\na = 1; using[0] = 1; let using;":            `using; a = 1; using[0] = 1; let using;`,
+		`for (using of b) {} for (using.x of b) {}`:         `for (using of b) {} for (using.x of b) {}`,
+		"async function f() { await\nusing; }":              `async function f() { await using; }`,
+		`function f() { using a = b; } using: for (;;) {}`:  `function f() { using a = b; } using: for (; ; ) {}`,
+	} {
+		program, err := parser.ParseFileWithOptions(code, opts)
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", code, err)
+			continue
+		}
+		if got := strings.Join(strings.Fields(generator.Generate(program)), " "); got != want {
+			t.Errorf("Expected %q to generate %q, got %q", code, want, got)
+		}
+	}
+
+	program, _ := parser.ParseFileWithOptions(`await using a = b;`, opts)
+	if decl, ok := program.Body[0].Stmt.(*ast.VariableDeclaration); !ok || decl.Token != token.AwaitUsing {
+		t.Errorf("Expected an await using declaration, got %#v", program.Body[0].Stmt)
+	}
+
+	for code, msg := range map[string]string{
+		`using a;`:                            "Missing initializer in using declaration",
+		`using a = 1, {b} = c;`:               "Using declarations may not have binding patterns",
+		`for (using a in b) {}`:               "for-in loop variable declaration may not be a using declaration",
+		`if (a) using b = c;`:                 "Lexical declaration cannot appear in a single-statement context",
+		`switch (a) { case 1: using b = c; }`: "Using declarations may not appear directly in a case clause",
+		`export using a = b;`:                 "Unexpected identifier",
+	} {
+		_, err := parser.ParseFileWithOptions(code, opts)
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("Expected %q for %q, got %v", msg, code, err)
+		}
+	}
+
+	if _, err := parser.ParseFile(`using a = b;`); err == nil || !strings.Contains(err.Error(), "top level of a script") {
+		t.Errorf("Expected using to be rejected at the top level of a script, got %v", err)
+	}
+	if _, err := parser.ParseFile(`{ using a = b; }`); err != nil {
+		t.Errorf("Unexpected error for using in a block of a script: %v", err)
+	}
+}
+
+func TestForAwait(t *testing.T) {
+	opts := parser.Options{SourceType: ast.SourceTypeModule}
+	for code, want := range map[string]string{
+		`for await (const x of y) {}`:                          `for await (const x of y) {}`,
+		`async function f() { for await (x of g()) await x; }`: `async function f() { for await (x of g()) await x; }`,
+		`const r = await fetch(u); await r.text();`:            `const r = await fetch(u); await r.text();`,
+		`async function f() { for await (async of y) {} }`:     `async function f() { for await (async of y) {} }`,
+	} {
+		program, err := parser.ParseFileWithOptions(code, opts)
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", code, err)
+			continue
+		}
+		if got := strings.Join(strings.Fields(generator.Generate(program)), " "); got != want {
+			t.Errorf("Expected %q to generate %q, got %q", code, want, got)
+		}
+	}
+
+	program, _ := parser.ParseFileWithOptions(`for await (const x of y);`, opts)
+	if stmt, ok := program.Body[0].Stmt.(*ast.ForOfStatement); !ok || !stmt.Await {
+		t.Errorf("Expected a for await...of statement, got %#v", program.Body[0].Stmt)
+	}
+
+	for code, msg := range map[string]string{
+		`for await (x in y) {}`:                  "for await may only be used with for-of loops",
+		`for await (;;) {}`:                      "for await may only be used with for-of loops",
+		`function f() { for await (x of y) {} }`: "Unexpected token await",
+		`for (async of y);`:                      "Unexpected identifier",
+	} {
+		_, err := parser.ParseFileWithOptions(code, opts)
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("Expected %q for %q, got %v", msg, code, err)
+		}
+	}
+	if _, err := parser.ParseFile(`for await (x of y) {}`); err == nil {
+		t.Error("Expected for await to be rejected at the top level of a script")
+	}
+}
+
+func TestRegExpErrors(t *testing.T) {
+	for code, want := range map[string]string{
+		`x = /a(b/`:      "Invalid regular expression: /a(b/: Unterminated group (line 1, column 7)",
+		`x = /[z-a]/`:    "Invalid regular expression: /[z-a]/: Range out of order in character class (line 1, column 7)",
+		`x = /\p{Foo}/u`: "Invalid regular expression: /\\p{Foo}/: Invalid property name (line 1, column 6)",
+		`x = /a/gg`:      "Invalid regular expression flags (line 1, column 9)",
+		`x = /a/uv`:      "Invalid regular expression flags (line 1, column 8)",
+	} {
+		_, err := parser.ParseFile(code)
+		if err == nil || err.Error() != want {
+			t.Errorf("Expected %q for %q, got %v", want, code, err)
+		}
+	}
+	for _, code := range []string{`/{/`, `/]/`, `/\1/`, `/(?<a>.)\k<a>/`, `/[\p{L}--\q{a}]/v`, `/\u{1F600}/u`} {
+		if _, err := parser.ParseFile(code); err != nil {
+			t.Errorf("Unexpected error for %q: %v", code, err)
+		}
+	}
+}
+
+func TestLimits(t *testing.T) {
+	nested := func(open, inner, close string) string {
+		return strings.Repeat(open, 100000) + inner + strings.Repeat(close, 100000)
+	}
+	for _, code := range []string{
+		nested("(", "a", ")"),
+		nested("[", "", "]"),
+		nested("x = {a: ", "1", "}"),
+		nested("{", "", "}"),
+		nested("if (a) ", "b", ""),
+		nested("!", "a", ""),
+		nested("new ", "a", ""),
+		nested("a => ", "a", ""),
+		nested("`${", "", "}`"),
+		nested("let [", "a", "] = b"),
+		"x = /" + nested("(", "a", ")") + "/",
+		"x = /" + nested("(?=", "a", ")") + "/",
+		"x = /" + nested("[", "a", "]") + "/v",
+	} {
+		program, err := parser.ParseFileWithOptions(code, parser.Options{Limits: limit.Limits{MaxDepth: 1000}})
+		var depthErr *limit.DepthError
+		if !errors.As(err, &depthErr) || depthErr.MaxDepth != 1000 {
+			t.Errorf("Expected a depth error for %.20q, got %v", code, err)
+		}
+		if program == nil {
+			t.Errorf("Expected a program for %.20q", code)
+		}
+	}
+	code := "let x: " + strings.Repeat("keyof ", 100000) + "A"
+	if _, err := parser.ParseFileWithOptions(code, parser.Options{TypeScript: true, Limits: limit.Limits{MaxDepth: 1000}}); !errors.As(err, new(*limit.DepthError)) {
+		t.Errorf("Expected a depth error for nested types, got %v", err)
+	}
+
+	code = strings.Repeat("a, ", 10000) + "a;"
+	_, err := parser.ParseFileWithOptions(code, parser.Options{Limits: limit.Limits{MaxNodes: 1000}})
+	if !errors.As(err, new(*limit.NodeError)) {
+		t.Errorf("Expected a node error, got %v", err)
+	}
+	_, err = parser.ParseFileWithOptions(code, parser.Options{Limits: limit.Limits{MaxNodes: 100000}})
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = parser.ParseFileWithOptions(code, parser.Options{Limits: limit.Limits{Context: ctx}})
+	if !errors.As(err, new(*limit.CanceledError)) || !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a canceled error, got %v", err)
+	}
+
+	program, err := parser.ParseFile(strings.Repeat("[", 1000) + strings.Repeat("]", 1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := generator.GenerateWithOptions(program, generator.Options{Limits: limit.Limits{MaxDepth: 100}}); !errors.As(err, new(*limit.DepthError)) {
+		t.Errorf("Expected a depth error from the generator, got %v", err)
+	}
+	if _, err := generator.GenerateWithOptions(program, generator.Options{Limits: limit.Limits{MaxNodes: 100}}); !errors.As(err, new(*limit.NodeError)) {
+		t.Errorf("Expected a node error from the generator, got %v", err)
+	}
+	if _, err := generator.GenerateWithOptions(program, generator.Options{Limits: limit.Limits{MaxDepth: 10000}}); err != nil {
+		t.Errorf("Unexpected error from the generator: %v", err)
+	}
+}
+
+func TestSourceFile(t *testing.T) {
+	code := "a;\r\nx = 'é😀' + b;\u2028c"
+	program, err := parser.ParseFile(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := program.Source
+	if file == nil || file.Text() != code || file.LineCount() != 3 {
+		t.Fatalf("Unexpected source file %v", file)
+	}
+	b := program.Body[1].Stmt.(*ast.ExpressionStatement).Expression.Expr.(*ast.AssignExpression).Right.Expr.(*ast.BinaryExpression).Right.Expr
+	for unit, want := range map[ast.ColumnUnit]ast.Position{
+		ast.ColumnBytes:      {Line: 2, Column: 15},
+		ast.ColumnUTF16:      {Line: 2, Column: 12},
+		ast.ColumnCodePoints: {Line: 2, Column: 11},
+	} {
+		if got := file.Position(b.Idx0(), unit); got != want {
+			t.Errorf("Position(%d, %d) = %v; want %v", b.Idx0(), unit, got, want)
+		}
+		if got := file.Idx(want, unit); got != b.Idx0() {
+			t.Errorf("Idx(%v, %d) = %d; want %d", want, unit, got, b.Idx0())
+		}
+	}
+	c := program.Body[2].Stmt.(*ast.ExpressionStatement).Expression.Expr
+	if got := file.Position(c.Idx0(), ast.ColumnUTF16); got != (ast.Position{Line: 3, Column: 0}) {
+		t.Errorf("Position(%d) = %v; want 3:0", c.Idx0(), got)
+	}
+
+	_, err = parser.ParseFile("a;\u2028'é' +")
+	if err == nil || err.Error() != "Unexpected end of input (line 2, column 7)" {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestParser(t *testing.T) {
+	sources := []string{
+		"var a = [1, 2, 3].map(x => x * 2);",
+		"function f(a, b) { if (a) { return b; } return f(b, a); }",
+		"class A { m() { for (let i = 0; i < 10; i++) g(i); } }",
+		"let = ;",
+	}
+	pr := parser.NewParser()
+	kept, _ := pr.Parse(sources[0], parser.Options{})
+	for i := 0; i < 3; i++ {
+		for _, src := range sources {
+			want, wantErr := parser.ParseFile(src)
+			program, err := pr.Parse(src, parser.Options{})
+			if (err == nil) != (wantErr == nil) || err != nil && err.Error() != wantErr.Error() {
+				t.Errorf("Parse(%q) failed with %v; want %v", src, err, wantErr)
+			}
+			if got := generator.Generate(program); got != generator.Generate(want) {
+				t.Errorf("Parse(%q) = %s; want %s", src, got, generator.Generate(want))
+			}
+			program.Release()
+		}
+	}
+	// A program that is not released stays intact.
+	want, _ := parser.ParseFile(sources[0])
+	if got := generator.Generate(kept); got != generator.Generate(want) {
+		t.Errorf("Kept program changed to %s", got)
+	}
+}
+
+// benchmarkSource is a script of realistic size for the parse benchmarks.
+var benchmarkSource = strings.Repeat(`
+function debounce(fn, wait) {
+	let timeout;
+	return function (...args) {
+		clearTimeout(timeout);
+		timeout = setTimeout(() => fn.apply(this, args), wait);
+	};
+}
+const items = [1, 2, 3].map((x, i) => ({ id: i, value: x * 2, label: 'item ' + x }));
+for (const { id, value } of items) {
+	if (value > 2 && id !== 0) console.log(id, value);
+}
+`, 20)
+
+func BenchmarkParseFile(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := parser.ParseFile(benchmarkSource); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParser(b *testing.B) {
+	b.ReportAllocs()
+	pr := parser.NewParser()
+	for i := 0; i < b.N; i++ {
+		program, err := pr.Parse(benchmarkSource, parser.Options{})
+		if err != nil {
+			b.Fatal(err)
+		}
+		program.Release()
+	}
+}
+
+func TestParseBytesAndReader(t *testing.T) {
+	code := "var s = 'héllo', t = `x${s}y`; /* c */ f(s, t);"
+	opts := parser.Options{Comments: true}
+	want := generator.Generate(must(parser.ParseFileWithOptions(code, opts)))
+	for name, parse := range map[string]func() (*ast.Program, error){
+		"ParseBytes":  func() (*ast.Program, error) { return parser.ParseBytes([]byte(code), opts) },
+		"ParseReader": func() (*ast.Program, error) { return parser.ParseReader(strings.NewReader(code), opts) },
+		"ParseReader without a size": func() (*ast.Program, error) {
+			return parser.ParseReader(iotest.OneByteReader(strings.NewReader(code)), opts)
+		},
+	} {
+		program, err := parse()
+		if err != nil {
+			t.Errorf("%s failed: %v", name, err)
+			continue
+		}
+		if got := generator.Generate(program); got != want {
+			t.Errorf("%s = %s; want %s", name, got, want)
+		}
+		if len(program.Comments) != 1 || program.Comments[0].Text != " c " {
+			t.Errorf("%s lost the comment", name)
+		}
+	}
+
+	errRead := errors.New("read failed")
+	program, err := parser.ParseReader(iotest.ErrReader(errRead), opts)
+	if program == nil || !errors.Is(err, errRead) {
+		t.Errorf("Expected the read error, got %v", err)
+	}
+}
+
+func must(program *ast.Program, err error) *ast.Program {
+	if err != nil {
+		panic(err)
+	}
+	return program
+}
+
+func TestWebCompat(t *testing.T) {
+	for code, want := range map[string]string{
+		"<!-- a\nx = 1 <!-- b\n--> c":    "// a x = 1; // b // c",
+		"x = 1; /*\n*/ --> a\ny = a-->b": "x = 1; /* */ // a y = a-- > b;",
+		"if (a) function f() {}":         "if (a) function f() {}",
+		"l: function f() {}":             "l: function f() {}",
+		"for (var a = 1 in b);":          "for (var a = 1 in b) ;",
+	} {
+		program, err := parser.ParseFileWithOptions(code, parser.Options{Comments: true})
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", code, err)
+			continue
+		}
+		if !program.WebCompat {
+			t.Errorf("Expected %q to be parsed with web compatibility", code)
+		}
+		if got := strings.Join(strings.Fields(generator.Generate(program)), " "); got != want {
+			t.Errorf("Expected %q to generate %q, got %q", code, want, got)
+		}
+		for _, opts := range []parser.Options{{NoWebCompat: true}, {SourceType: ast.SourceTypeModule}} {
+			if _, err := parser.ParseFileWithOptions(code, opts); err == nil {
+				t.Errorf("Expected error for %q with %+v", code, opts)
+			}
+		}
+	}
+
+	program := must(parser.ParseFileWithOptions("<!-- a\n--> b", parser.Options{Comments: true}))
+	if c := program.Comments; len(c) != 2 || c[0].Kind != ast.CommentHTMLOpen || c[1].Kind != ast.CommentHTMLClose ||
+		c[0].Text != " a" || c[1].Idx0() != 8 || c[1].Idx1() != 13 {
+		t.Errorf("Unexpected HTML-like comments %+v", c)
+	}
+
+	for code, msg := range map[string]string{
+		`"use strict"; if (a) function f() {}`: "In strict mode code, functions can only be declared",
+		`if (a) async function f() {}`:         "Async functions can only be declared",
+		`l: function* f() {}`:                  "Generators can only be declared",
+		`while (a) function f() {}`:            "In non-strict mode code, functions can only be declared",
+		`do l: function f() {} while (a)`:      "Labelled function declaration not allowed",
+		`if (a) l: function f() {}`:            "Labelled function declaration not allowed",
+		`for (var [a] = 1 in b);`:              "for-in loop variable declaration may not have an initializer",
+		`for (let a = 1 in b);`:                "for-in loop variable declaration may not have an initializer",
+	} {
+		_, err := parser.ParseFile(code)
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("Expected %q for %q, got %v", msg, code, err)
+		}
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	for code, want := range map[string]parser.Code{
+		"a +":                       parser.CodeUnexpectedEnd,
+		"a b":                       parser.CodeUnexpectedIdentifier,
+		"'use strict'; with (a) {}": parser.CodeWithInStrictMode,
+		"'use strict'; 010":         parser.CodeOctalInStrictMode,
+		"return":                    parser.CodeIllegalReturn,
+		"a: a: ;":                   parser.CodeDuplicateLabel,
+		"while (a) continue b":      parser.CodeUndefinedLabel,
+		"a ?? b || c":               parser.CodeMixedCoalesce,
+		"class A { constructor() {} get constructor() {} }": parser.CodeConstructorAccessor,
+	} {
+		_, err := parser.ParseFile(code)
+		var list *parser.ErrorList
+		if !errors.As(err, &list) || (*list)[0].Code != want {
+			t.Errorf("Expected %v for %q, got %v", want, code, err)
+		}
+	}
+
+	for code, want := range map[string]parser.SyntaxError{
+		"if (a) {\n  b()\n": {Code: parser.CodeUnexpectedEnd, Offset: 15, Hint: "Add the missing '}'",
+			Related: &parser.Span{Message: "The '{' opened here", Line: 1, Column: 8, Offset: 7, Length: 1}},
+		"f(a, [b, c)": {Code: parser.CodeUnexpectedToken, Offset: 10, Length: 1,
+			Related: &parser.Span{Message: "The '[' opened here", Line: 1, Column: 6, Offset: 5, Length: 1}},
+		"switch (a) { default: default: }": {Code: parser.CodeDuplicateDefault, Offset: 22, Length: 7,
+			Related: &parser.Span{Message: "The first default clause", Line: 1, Column: 14, Offset: 13, Length: 7}},
+		"'use strict'; function f(a, a) {}": {Code: parser.CodeDuplicateParameter, Offset: 28, Length: 1,
+			Related: &parser.Span{Message: "The first declaration", Line: 1, Column: 26, Offset: 25, Length: 1}},
+		"'use strict'; 0777": {Code: parser.CodeOctalInStrictMode, Offset: 14, Length: 4,
+			Hint: "Write octal numbers with the 0o prefix"},
+		"x = 'abc":              {Code: parser.CodeUnterminatedString, Offset: 8, Hint: "Add the closing quote"},
+		"x = 'abc\ny'":          {Code: parser.CodeUnterminatedString, Offset: 8, Length: 1, Hint: "Add the closing quote"},
+		"x = `a${b}c":           {Code: parser.CodeUnterminatedTemplate, Offset: 11, Hint: "Add the closing backtick"},
+		"x = 0b12":              {Code: parser.CodeInvalidNumber, Offset: 7, Length: 1},
+		"x = 1__0":              {Code: parser.CodeNumericSeparator, Offset: 6, Length: 1, Hint: "Put separators only between two digits"},
+		"x = 1_":                {Code: parser.CodeNumericSeparator, Offset: 5, Length: 1, Hint: "Put separators only between two digits"},
+		"x = 0_1":               {Code: parser.CodeNumericSeparator, Offset: 5, Length: 1, Hint: "Put separators only between two digits"},
+		"x = 1._5":              {Code: parser.CodeNumericSeparator, Offset: 6, Length: 1, Hint: "Put separators only between two digits"},
+		"x = 0x_1":              {Code: parser.CodeNumericSeparator, Offset: 6, Length: 1, Hint: "Put separators only between two digits"},
+		"x = 0x":                {Code: parser.CodeInvalidNumber, Offset: 6},
+		"x = 'a\\u{110000}'":    {Code: parser.CodeInvalidEscape, Offset: 6, Length: 10},
+		"x = 'a\\xZ1'":          {Code: parser.CodeInvalidEscape, Offset: 6, Length: 2},
+		"x = `${a}\\u{110000}`": {Code: parser.CodeInvalidEscape, Offset: 9, Length: 10},
+	} {
+		_, err := parser.ParseFile(code)
+		var list *parser.ErrorList
+		if !errors.As(err, &list) {
+			t.Errorf("Expected error for %q, got %v", code, err)
+			continue
+		}
+		e := (*list)[0]
+		if e.Code != want.Code || e.Offset != want.Offset || e.Length != want.Length || e.Hint != want.Hint ||
+			(e.Related == nil) != (want.Related == nil) || e.Related != nil && *e.Related != *want.Related {
+			t.Errorf("Unexpected error for %q: %+v, related %+v", code, e, e.Related)
+		}
+	}
+
+	if got := parser.CodeDuplicateLabel.String(); got != "DuplicateLabel" {
+		t.Errorf("Expected DuplicateLabel, got %s", got)
+	}
+	if got := parser.Code(-1).String(); got != "code(-1)" {
+		t.Errorf("Expected code(-1), got %s", got)
+	}
+}
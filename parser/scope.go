@@ -11,6 +11,7 @@ type scope struct {
 	inAsync      bool
 	allowAwait   bool
 	allowYield   bool
+	strict       bool
 
 	labels []string
 }
@@ -19,6 +20,7 @@ func (p *parser) openScope() {
 	p.scope = &scope{
 		outer:   p.scope,
 		allowIn: true,
+		strict:  p.scope != nil && p.scope.strict,
 	}
 }
 
@@ -9,7 +9,7 @@ func (p *parser) parseBlockStatement() *ast.BlockStatement {
 	node := &ast.BlockStatement{}
 	node.LeftBrace = p.expect(token.LeftBrace)
 	node.List = p.parseStatementList()
-	node.RightBrace = p.expect(token.RightBrace)
+	node.RightBrace = p.expectClosing(token.RightBrace, node.LeftBrace)
 
 	return node
 }
@@ -22,18 +22,32 @@ func (p *parser) parseEmptyStatement() ast.Stmt {
 func (p *parser) parseStatementList() (list ast.Statements) {
 	for p.token != token.RightBrace && p.token != token.Eof {
 		p.scope.allowLet = true
-		list = append(list, ast.Statement{Stmt: p.parseStatement()})
+		idx := p.idx
+		list = p.appendStatement(list, p.parseStatement())
+		p.skipStalled(idx)
 	}
 
 	return
 }
 
 func (p *parser) parseStatement() ast.Stmt {
+	p.limits.Enter()
+	defer p.limits.Leave()
 	if p.token == token.Eof {
 		p.errorUnexpectedToken(p.token)
 		return &ast.BadStatement{From: p.idx, To: p.idx + 1}
 	}
 
+	if p.token == token.At {
+		p.parseClassDecorators(false)
+	}
+
+	if p.opts.TypeScript {
+		if stmt := p.parseTypeScriptDeclaration(); stmt != nil {
+			return stmt
+		}
+	}
+
 	switch p.token {
 	case token.Semicolon:
 		return p.parseEmptyStatement()
@@ -65,6 +79,10 @@ func (p *parser) parseStatement() ast.Stmt {
 		p.insertSemicolon = true
 	case token.Const:
 		return p.parseLexicalDeclaration(p.token)
+	case token.Identifier, token.Await:
+		if tok := p.usingDeclaration(false); tok != 0 {
+			return p.parseLexicalDeclaration(tok)
+		}
 	case token.Async:
 		if f := p.parseMaybeAsyncFunction(true); f != nil {
 			return &ast.FunctionDeclaration{
@@ -87,6 +105,14 @@ func (p *parser) parseStatement() ast.Stmt {
 		return p.parseThrowStatement()
 	case token.Try:
 		return p.parseTryStatement()
+	case token.Import:
+		if tok := p.peek(); tok != token.LeftParenthesis && tok != token.Period {
+			p.error(CodeImportNotTopLevel)
+			return p.parseImportDeclaration()
+		}
+	case token.Export:
+		p.error(CodeExportNotTopLevel)
+		return p.parseExportDeclaration()
 	}
 
 	expression := p.parseExpression()
@@ -98,12 +124,15 @@ func (p *parser) parseStatement() ast.Stmt {
 		label := identifier.Name
 		for _, value := range p.scope.labels {
 			if label == value {
-				p.error(label)
+				p.errorAtNode(identifier, CodeDuplicateLabel, label)
 			}
 		}
 		p.scope.labels = append(p.scope.labels, label) // Push the label
 		p.scope.allowLet = false
 		statement := p.parseStatement()
+		if fn, ok := statement.(*ast.FunctionDeclaration); ok && !p.functionClause(fn.Function) {
+			p.errorFunctionClause(fn.Function)
+		}
 		p.scope.labels = p.scope.labels[:len(p.scope.labels)-1] // Pop the label
 		return &ast.LabelledStatement{
 			Label:     identifier,
@@ -131,7 +160,10 @@ func (p *parser) parseTryStatement() ast.Stmt {
 		var parameter *ast.BindingTarget
 		if p.token == token.LeftParenthesis {
 			p.next()
-			parameter = &ast.BindingTarget{Target: p.parseBindingTarget()}
+			parameter = p.makeTarget(p.parseBindingTarget())
+			if p.opts.TypeScript {
+				p.skipBindingAnnotation()
+			}
 			p.expect(token.RightParenthesis)
 		}
 		node.Catch = &ast.CatchStatement{
@@ -147,7 +179,7 @@ func (p *parser) parseTryStatement() ast.Stmt {
 	}
 
 	if node.Catch == nil && node.Finally == nil {
-		p.error("Missing catch or finally after try")
+		p.error(CodeMissingCatchOrFinally)
 		return &ast.BadStatement{From: node.Try, To: node.Body.Idx1()}
 	}
 
@@ -155,9 +187,13 @@ func (p *parser) parseTryStatement() ast.Stmt {
 }
 
 func (p *parser) parseFunctionParameterList() ast.ParameterList {
+	if p.opts.TypeScript && p.token == token.Less {
+		p.skipTypeParameters()
+	}
 	opening := p.expect(token.LeftParenthesis)
 	var list ast.VariableDeclarators
 	var rest ast.Expr
+	var properties []*ast.Identifier
 	if !p.scope.inFuncParams {
 		p.scope.inFuncParams = true
 		defer func() {
@@ -168,14 +204,43 @@ func (p *parser) parseFunctionParameterList() ast.ParameterList {
 		if p.token == token.Ellipsis {
 			p.next()
 			rest = p.reinterpretAsDestructBindingTarget(p.parseAssignmentExpression())
+			if p.opts.TypeScript {
+				p.skipBindingAnnotation()
+			}
 			break
 		}
-		p.parseVariableDeclaration(&list)
+		property := false
+		if p.opts.TypeScript {
+			if p.token == token.This && p.peek() == token.Colon {
+				// A this parameter only declares the type of this.
+				p.next()
+				p.next()
+				p.skipType()
+				if p.token != token.RightParenthesis {
+					p.expect(token.Comma)
+				}
+				continue
+			}
+			// Parameter decorators only exist in TypeScript and are dropped.
+			p.parseDecorators()
+			property = p.skipParameterModifiers()
+		}
+		param := p.parseVariableDeclaration(&list)
+		if id, ok := param.Target.Target.(*ast.Identifier); ok && property {
+			properties = append(properties, id)
+		}
 		if p.token != token.RightParenthesis {
-			p.expect(token.Comma)
+			p.expectComma(token.RightParenthesis, opening)
+		}
+	}
+	closing := p.expectClosing(token.RightParenthesis, opening)
+	if p.opts.TypeScript {
+		p.paramProperties = properties
+		if p.token == token.Colon {
+			p.next()
+			p.skipType()
 		}
 	}
-	closing := p.expect(token.RightParenthesis)
 
 	return ast.ParameterList{
 		Opening: opening,
@@ -227,7 +292,9 @@ func (p *parser) parseFunction(declaration, async bool, start ast.Idx) *ast.Func
 	if p.token == token.Identifier {
 		name = p.parseIdentifier()
 	} else if declaration {
-		// Use expect error handling
+		// A declaration keeps a name, like a class does, so that a tolerant
+		// parse still has a well-formed tree. Use expect error handling.
+		name = &ast.Identifier{Idx: p.idx}
 		p.expect(token.Identifier)
 	}
 	node.Name = name
@@ -248,25 +315,43 @@ func (p *parser) parseFunction(declaration, async bool, start ast.Idx) *ast.Func
 	}
 
 	node.ParameterList = p.parseFunctionParameterList()
-	node.Body = p.parseFunctionBlock(async, async, p.scope.allowYield)
+	node.Body, node.Strict = p.parseFunctionBlock(async, async, p.scope.allowYield, &node.ParameterList)
+	p.checkParameters(&node.ParameterList, node.Strict, false)
+	if name != nil && node.Strict && isEvalOrArguments(name.Name) {
+		p.errorAtNode(name, CodeEvalOrArgumentsInStrictMode)
+	}
 
 	return node
 }
 
-func (p *parser) parseFunctionBlock(async, allowAwait, allowYield bool) (body *ast.BlockStatement) {
+// parseFunctionBlock parses the body of a function with the given parameters
+// and reports whether it is strict mode code. Class static blocks pass nil
+// params, as they have no directive prologue.
+func (p *parser) parseFunctionBlock(async, allowAwait, allowYield bool, params *ast.ParameterList) (body *ast.BlockStatement, strict bool) {
 	p.openScope()
 	p.scope.inFunction = true
 	p.scope.inAsync = async
 	p.scope.allowAwait = allowAwait
 	p.scope.allowYield = allowYield
 	defer p.closeScope()
-	body = p.parseBlockStatement()
-	return
+	if params == nil {
+		return p.parseBlockStatement(), p.scope.strict
+	}
+	body = &ast.BlockStatement{}
+	body.LeftBrace = p.expect(token.LeftBrace)
+	body.Directives, body.List = p.parseDirectives()
+	body.List = append(body.List, p.parseStatementList()...)
+	body.RightBrace = p.expectClosing(token.RightBrace, body.LeftBrace)
+	if hasUseStrict(body.Directives) && !isSimpleParameterList(params) {
+		p.errorAt(body.LeftBrace, CodeUseStrictWithComplexParameters)
+	}
+	return body, p.scope.strict
 }
 
-func (p *parser) parseArrowFunctionBody(async bool) *ast.ConciseBody {
+func (p *parser) parseArrowFunctionBody(async bool, params *ast.ParameterList) (*ast.ConciseBody, bool) {
 	if p.token == token.LeftBrace {
-		return &ast.ConciseBody{Body: p.parseFunctionBlock(async, async, false)}
+		body, strict := p.parseFunctionBlock(async, async, false, params)
+		return &ast.ConciseBody{Body: body}, strict
 	}
 	if async != p.scope.inAsync || async != p.scope.allowAwait {
 		inAsync := p.scope.inAsync
@@ -284,7 +369,7 @@ func (p *parser) parseArrowFunctionBody(async bool) *ast.ConciseBody {
 
 	return &ast.ConciseBody{
 		Body: p.makeExpr(p.parseAssignmentExpression()),
-	}
+	}, p.scope.strict
 }
 
 func (p *parser) parseClass(declaration bool) *ast.ClassLiteral {
@@ -293,13 +378,24 @@ func (p *parser) parseClass(declaration bool) *ast.ClassLiteral {
 	}
 
 	node := &ast.ClassLiteral{
-		Class: p.expect(token.Class),
+		Decorators: p.decorators,
+		Class:      p.expect(token.Class),
+	}
+	p.decorators = nil
+
+	// All parts of a class are strict mode code.
+	if !p.scope.strict {
+		p.scope.strict = true
+		defer func() {
+			p.scope.strict = false
+		}()
 	}
 
 	p.tokenToBindingId()
 	name := &ast.Identifier{}
 	if p.token == token.Identifier {
 		name = p.parseIdentifier()
+		p.checkStrictIdentifier(name)
 	} else if declaration {
 		// Use expect error handling
 		p.expect(token.Identifier)
@@ -307,12 +403,28 @@ func (p *parser) parseClass(declaration bool) *ast.ClassLiteral {
 
 	node.Name = name
 
-	if p.token != token.LeftBrace {
+	if p.opts.TypeScript && p.token == token.Less {
+		p.skipTypeParameters()
+	}
+
+	if p.token != token.LeftBrace && !(p.opts.TypeScript && p.isContextual("implements")) {
 		p.expect(token.Extends)
 		node.SuperClass = p.makeExpr(p.parseLeftHandSideExpressionAllowCall())
+		if p.opts.TypeScript && p.token == token.Less {
+			p.skipTypeArguments()
+		}
 	}
 
-	p.expect(token.LeftBrace)
+	if p.opts.TypeScript && p.isContextual("implements") {
+		p.next()
+		p.skipType()
+		for p.token == token.Comma {
+			p.next()
+			p.skipType()
+		}
+	}
+
+	leftBrace := p.expect(token.LeftBrace)
 
 	for p.token != token.RightBrace && p.token != token.Eof {
 		if p.token == token.Semicolon {
@@ -320,30 +432,55 @@ func (p *parser) parseClass(declaration bool) *ast.ClassLiteral {
 			continue
 		}
 		start := p.idx
+		decorators := p.parseDecorators()
+		ambient := false
+		if p.opts.TypeScript {
+			ambient = p.skipMemberModifiers()
+		}
 		static := false
 		if p.token == token.Static {
-			switch p.peek() {
+			switch tok := p.peek(); tok {
 			case token.Assign, token.Semicolon, token.RightBrace, token.LeftParenthesis:
 				// treat as identifier
 			default:
+				if p.isTypeScriptMemberName(tok) {
+					break
+				}
+				staticIdx := p.idx
 				p.next()
 				if p.token == token.LeftBrace {
+					if decorators != nil {
+						p.errorAt(decorators[0].At, CodeDecoratorsNotValid)
+					}
 					b := &ast.ClassStaticBlock{
-						Static: start,
+						Static: staticIdx,
 					}
-					b.Block = p.parseFunctionBlock(false, true, false)
+					b.Block, _ = p.parseFunctionBlock(false, true, false, nil)
 					node.Body = append(node.Body, ast.ClassElement{Element: b})
 					continue
 				}
 				static = true
 			}
 		}
+		if p.opts.TypeScript {
+			ambient = p.skipMemberModifiers() || ambient
+			if p.token == token.LeftBracket && p.skipIndexSignature() {
+				if !p.implicitSemicolon && p.token != token.Semicolon && p.token != token.RightBrace {
+					p.errorUnexpectedToken(p.token)
+					break
+				}
+				continue
+			}
+		}
+		accessor := p.parseAccessorKeyword()
 
 		var kind ast.PropertyKind
 		var async bool
 		methodBodyStart := p.idx
-		if p.literal == "get" || p.literal == "set" {
-			if tok := p.peek(); tok != token.Semicolon && tok != token.LeftParenthesis {
+		if accessor {
+			// The key follows.
+		} else if p.literal == "get" || p.literal == "set" {
+			if tok := p.peek(); tok != token.Semicolon && tok != token.LeftParenthesis && !p.isTypeScriptMemberName(tok) {
 				if p.literal == "get" {
 					kind = ast.PropertyKindGet
 				} else {
@@ -352,7 +489,7 @@ func (p *parser) parseClass(declaration bool) *ast.ClassLiteral {
 				p.next()
 			}
 		} else if p.token == token.Async {
-			if tok := p.peek(); tok != token.Semicolon && tok != token.LeftParenthesis {
+			if tok := p.peek(); tok != token.Semicolon && tok != token.LeftParenthesis && !p.isTypeScriptMemberName(tok) {
 				async = true
 				kind = ast.PropertyKindMethod
 				p.next()
@@ -373,35 +510,48 @@ func (p *parser) parseClass(declaration bool) *ast.ClassLiteral {
 		_, private := value.(*ast.PrivateIdentifier)
 
 		if static && !private && keyName == "prototype" {
-			p.error("Classes may not have a static property named 'prototype'")
+			p.error(CodeStaticPrototype)
 		}
 
-		if kind == "" && p.token == token.LeftParenthesis {
+		if p.opts.TypeScript && (p.token == token.QuestionMark || p.token == token.Not && kind == "") {
+			// An optional member or a field with a definite assignment assertion.
+			p.next()
+		}
+
+		if kind == "" && !accessor && (p.token == token.LeftParenthesis || p.opts.TypeScript && p.token == token.Less) {
 			kind = ast.PropertyKindMethod
 		}
 
+		if kind != "" && p.opts.TypeScript && p.skipMethodSignature() {
+			continue
+		}
+
 		if kind != "" {
 			// method
 			if keyName == "constructor" && !computed {
 				if !static {
+					if decorators != nil {
+						p.errorAt(decorators[0].At, CodeDecoratorsNotValid)
+					}
 					if kind != ast.PropertyKindMethod {
-						p.error("Class constructor may not be an accessor")
+						p.error(CodeConstructorAccessor)
 					} else if async {
-						p.error("Class constructor may not be an async method")
+						p.error(CodeConstructorAsync)
 					} else if generator {
-						p.error("Class constructor may not be a generator")
+						p.error(CodeConstructorGenerator)
 					}
 				} else if private {
-					p.error("Class constructor may not be a private method")
+					p.error(CodeConstructorPrivate)
 				}
 			}
 			md := &ast.MethodDefinition{
-				Idx:      start,
-				Key:      p.makeExpr(value),
-				Kind:     kind,
-				Body:     p.parseMethodDefinition(methodBodyStart, kind, generator, async),
-				Static:   static,
-				Computed: computed,
+				Idx:        start,
+				Decorators: decorators,
+				Key:        p.makeExpr(value),
+				Kind:       kind,
+				Body:       p.parseMethodDefinition(methodBodyStart, kind, generator, async),
+				Static:     static,
+				Computed:   computed,
 			}
 			node.Body = append(node.Body, ast.ClassElement{Element: md})
 		} else {
@@ -413,7 +563,11 @@ func (p *parser) parseClass(declaration bool) *ast.ClassLiteral {
 				}
 			}
 			if isCtor {
-				p.error("Classes may not have a field named 'constructor'")
+				p.error(CodeConstructorField)
+			}
+			if p.opts.TypeScript && p.token == token.Colon {
+				p.next()
+				p.skipType()
 			}
 			var initializer ast.Expr
 			if p.token == token.Assign {
@@ -425,17 +579,38 @@ func (p *parser) parseClass(declaration bool) *ast.ClassLiteral {
 				p.errorUnexpectedToken(p.token)
 				break
 			}
-			node.Body = append(node.Body, ast.ClassElement{Element: &ast.FieldDefinition{
-				Idx:         start,
-				Key:         p.makeExpr(value),
-				Initializer: p.makeExpr(initializer),
-				Static:      static,
-				Computed:    computed,
-			}})
+			if ambient {
+				continue
+			}
+			var init *ast.Expression
+			if initializer != nil {
+				init = p.makeExpr(initializer)
+			}
+			var element ast.Element
+			if accessor {
+				element = &ast.ClassAccessorProperty{
+					Idx:         start,
+					Decorators:  decorators,
+					Key:         p.makeExpr(value),
+					Initializer: init,
+					Static:      static,
+					Computed:    computed,
+				}
+			} else {
+				element = &ast.FieldDefinition{
+					Idx:         start,
+					Decorators:  decorators,
+					Key:         p.makeExpr(value),
+					Initializer: init,
+					Static:      static,
+					Computed:    computed,
+				}
+			}
+			node.Body = append(node.Body, ast.ClassElement{Element: element})
 		}
 	}
 
-	node.RightBrace = p.expect(token.RightBrace)
+	node.RightBrace = p.expectClosing(token.RightBrace, leftBrace)
 
 	return node
 }
@@ -456,7 +631,7 @@ func (p *parser) parseReturnStatement() ast.Stmt {
 	idx := p.expect(token.Return)
 
 	if !p.scope.inFunction {
-		p.error("Illegal return statement")
+		p.error(CodeIllegalReturn)
 		p.nextStatement()
 		return &ast.BadStatement{From: idx, To: p.idx}
 	}
@@ -479,9 +654,9 @@ func (p *parser) parseThrowStatement() ast.Stmt {
 
 	if p.implicitSemicolon {
 		if p.chr == -1 { // Hackish
-			p.error("Unexpected end of input")
+			p.error(CodeUnexpectedEnd)
 		} else {
-			p.error("Illegal newline after throw")
+			p.error(CodeNewlineAfterThrow)
 		}
 		p.nextStatement()
 		return &ast.BadStatement{From: idx, To: p.idx}
@@ -525,7 +700,9 @@ func (p *parser) parseSwitchStatement() ast.Stmt {
 		clause := p.parseCaseStatement()
 		if clause.Test == nil {
 			if node.Default != -1 {
-				p.error("Already saw a default in switch")
+				e := p.errorAt(clause.Case, CodeDuplicateDefault)
+				e.Length = len("default")
+				e.Related = p.span(node.Body[node.Default].Case, len("default"), "The first default clause")
 			}
 			node.Default = index
 		}
@@ -537,6 +714,9 @@ func (p *parser) parseSwitchStatement() ast.Stmt {
 
 func (p *parser) parseWithStatement() ast.Stmt {
 	withPos := p.idx
+	if p.scope.strict {
+		p.error(CodeWithInStrictMode)
+	}
 	p.expect(token.With)
 	p.expect(token.LeftParenthesis)
 	node := &ast.WithStatement{
@@ -545,7 +725,7 @@ func (p *parser) parseWithStatement() ast.Stmt {
 	}
 	p.expect(token.RightParenthesis)
 	p.scope.allowLet = false
-	node.Body = p.makeStmt(p.parseStatement())
+	node.Body = p.makeStmt(p.parseClause(false))
 
 	return node
 }
@@ -570,7 +750,8 @@ func (p *parser) parseCaseStatement() ast.CaseStatement {
 			break
 		}
 		p.scope.allowLet = true
-		node.Consequent = append(node.Consequent, ast.Statement{Stmt: p.parseStatement()})
+		stmt := p.forbidUsing(p.parseStatement(), "directly in a case clause")
+		node.Consequent = p.appendStatement(node.Consequent, stmt)
 	}
 
 	return node
@@ -583,7 +764,55 @@ func (p *parser) parseIterationStatement() ast.Stmt {
 		p.scope.inIteration = inIteration
 	}()
 	p.scope.allowLet = false
-	return p.parseStatement()
+	return p.parseClause(false)
+}
+
+// parseClause parses the body of an if, iteration or with statement, which
+// may not be a declaration. Annex B makes an exception for a function
+// declaration as the body of an if statement.
+func (p *parser) parseClause(ifClause bool) ast.Stmt {
+	stmt := p.parseStatement()
+	switch s := stmt.(type) {
+	case *ast.FunctionDeclaration:
+		if !ifClause || !p.functionClause(s.Function) {
+			p.errorFunctionClause(s.Function)
+		}
+	case *ast.LabelledStatement:
+		for {
+			inner, ok := s.Statement.Stmt.(*ast.LabelledStatement)
+			if !ok {
+				break
+			}
+			s = inner
+		}
+		if fn, ok := s.Statement.Stmt.(*ast.FunctionDeclaration); ok && p.functionClause(fn.Function) {
+			// Otherwise the labelled statement has reported it already.
+			p.errorAt(fn.Function.Function, CodeLabelledFunctionInStatement)
+		}
+	}
+	return stmt
+}
+
+// functionClause reports whether fn may be declared as the body of an if or
+// labelled statement, which Annex B allows for plain functions in sloppy
+// mode code.
+func (p *parser) functionClause(fn *ast.FunctionLiteral) bool {
+	return !p.scope.strict && p.webCompat() && !fn.Async && !fn.Generator
+}
+
+func (p *parser) errorFunctionClause(fn *ast.FunctionLiteral) {
+	code := CodeFunctionInStatement
+	switch {
+	case fn.Async:
+		code = CodeAsyncFunctionInStatement
+	case fn.Generator:
+		code = CodeGeneratorInStatement
+	case p.scope.strict:
+		code = CodeStrictFunctionInStatement
+	case p.webCompat():
+		code = CodeSloppyFunctionInStatement
+	}
+	p.errorAt(fn.Function, code)
 }
 
 func (p *parser) parseForIn(idx ast.Idx, into ast.ForInto) *ast.ForInStatement {
@@ -600,7 +829,7 @@ func (p *parser) parseForIn(idx ast.Idx, into ast.ForInto) *ast.ForInStatement {
 	}
 }
 
-func (p *parser) parseForOf(idx ast.Idx, into ast.ForInto) *ast.ForOfStatement {
+func (p *parser) parseForOf(idx ast.Idx, into ast.ForInto, await bool) *ast.ForOfStatement {
 	// Already have consumed "<into> of"
 
 	source := p.parseAssignmentExpression()
@@ -611,6 +840,7 @@ func (p *parser) parseForOf(idx ast.Idx, into ast.ForInto) *ast.ForOfStatement {
 		Into:   &into,
 		Source: p.makeExpr(source),
 		Body:   p.makeStmt(p.parseIterationStatement()),
+		Await:  await,
 	}
 }
 
@@ -640,6 +870,14 @@ func (p *parser) parseFor(idx ast.Idx, initializer *ast.ForLoopInitializer) *ast
 
 func (p *parser) parseForOrForInStatement() ast.Stmt {
 	idx := p.expect(token.For)
+	await := false
+	if p.token == token.Await {
+		if !p.scope.allowAwait || !p.scope.inAsync {
+			p.errorUnexpectedToken(token.Await)
+		}
+		await = true
+		p.next()
+	}
 	p.expect(token.LeftParenthesis)
 
 	var initializer *ast.ForLoopInitializer
@@ -658,10 +896,12 @@ func (p *parser) parseForOrForInStatement() ast.Stmt {
 			default:
 				tok = token.Identifier
 			}
+		} else if using := p.usingDeclaration(true); using != 0 {
+			tok = using
 		}
-		if tok == token.Var || tok == token.Let || tok == token.Const {
-			idx := p.idx
-			p.next()
+		using := tok == token.Using || tok == token.AwaitUsing
+		if tok == token.Var || tok == token.Let || tok == token.Const || using {
+			idx := p.expectDeclarationKind(tok)
 
 			list := p.parseVariableDeclarationList()
 			if len(list) == 1 {
@@ -673,9 +913,15 @@ func (p *parser) parseForOrForInStatement() ast.Stmt {
 					forOf = true
 				}
 			}
+			if using {
+				if forIn {
+					p.errorAt(idx, CodeForInUsing)
+				}
+				p.checkUsingBindings(list, forIn || forOf)
+			}
 			if forIn || forOf {
-				if list[0].Initializer != nil {
-					p.error("for-in loop variable declaration may not have an initializer")
+				if list[0].Initializer != nil && !(forIn && p.forInInitializer(tok, list[0])) {
+					p.error(CodeForInInitializer)
 				}
 				into = ast.ForInto{Into: &ast.VariableDeclaration{
 					Token: tok,
@@ -691,7 +937,14 @@ func (p *parser) parseForOrForInStatement() ast.Stmt {
 				}}
 			}
 		} else {
-			expr := p.parseExpression()
+			var expr ast.Expr
+			if await && p.token == token.Async && p.asyncOf() {
+				// The [lookahead ≠ async of] restriction, which keeps "async
+				// of =>" an arrow function, only applies to plain for-of.
+				expr = p.parseIdentifier()
+			} else {
+				expr = p.parseExpression()
+			}
 			if p.token == token.In {
 				p.next()
 				forIn = true
@@ -701,14 +954,16 @@ func (p *parser) parseForOrForInStatement() ast.Stmt {
 			}
 			if forIn || forOf {
 				switch e := expr.(type) {
-				case *ast.Identifier, *ast.PrivateDotExpression, *ast.VariableDeclarator, *ast.MemberExpression:
+				case *ast.Identifier:
+					p.checkStrictIdentifier(e)
+				case *ast.PrivateDotExpression, *ast.VariableDeclarator, *ast.MemberExpression:
 					// These are all acceptable
 				case *ast.ObjectLiteral:
 					expr = p.reinterpretAsObjectAssignmentPattern(e)
 				case *ast.ArrayLiteral:
 					expr = p.reinterpretAsArrayAssignmentPattern(e)
 				default:
-					p.error("Invalid left-hand side in for-in or for-of")
+					p.error(CodeInvalidForInTarget)
 					p.nextStatement()
 					return &ast.BadStatement{From: idx, To: p.idx}
 				}
@@ -720,22 +975,42 @@ func (p *parser) parseForOrForInStatement() ast.Stmt {
 		p.scope.allowIn = allowIn
 	}
 
+	if await && !forOf {
+		p.errorAt(idx, CodeForAwaitWithoutOf)
+	}
 	if forIn {
 		return p.parseForIn(idx, into)
 	}
 	if forOf {
-		return p.parseForOf(idx, into)
+		return p.parseForOf(idx, into, await)
 	}
 
 	p.expect(token.Semicolon)
 	return p.parseFor(idx, initializer)
 }
 
+// asyncOf reports whether the current token async is followed by of.
+func (p *parser) asyncOf() bool {
+	var state parserState
+	p.mark(&state)
+	defer p.restore(&state)
+	p.next()
+	return p.isContextual("of")
+}
+
+// forInInitializer reports whether decl, the declaration of a for-in loop,
+// may have an initializer, which Annex B allows for a var declaration of a
+// single name in sloppy mode code.
+func (p *parser) forInInitializer(tok token.Token, decl ast.VariableDeclarator) bool {
+	_, simple := decl.Target.Target.(*ast.Identifier)
+	return tok == token.Var && simple && !p.scope.strict && p.webCompat()
+}
+
 func (p *parser) ensurePatternInit(list []ast.VariableDeclarator) {
 	for _, item := range list {
 		if _, ok := item.Target.Target.(ast.Pattern); ok {
 			if item.Initializer == nil {
-				p.error("Missing initializer in destructuring declaration")
+				p.error(CodeMissingDestructuringInitializer)
 				break
 			}
 		}
@@ -743,12 +1018,15 @@ func (p *parser) ensurePatternInit(list []ast.VariableDeclarator) {
 }
 
 func (p *parser) parseLexicalDeclaration(tok token.Token) *ast.VariableDeclaration {
-	idx := p.expect(tok)
+	idx := p.expectDeclarationKind(tok)
 	if !p.scope.allowLet && tok != token.Var {
-		p.error("Lexical declaration cannot appear in a single-statement context")
+		p.error(CodeLexicalDeclarationInStatement)
 	}
 
 	list := p.parseVariableDeclarationList()
+	if tok == token.Using || tok == token.AwaitUsing {
+		p.checkUsingBindings(list, false)
+	}
 	p.ensurePatternInit(list)
 	p.semicolon()
 
@@ -773,7 +1051,7 @@ func (p *parser) parseDoWhileStatement() ast.Stmt {
 		node.Body = p.makeStmt(p.parseBlockStatement())
 	} else {
 		p.scope.allowLet = false
-		node.Body = p.makeStmt(p.parseStatement())
+		node.Body = p.makeStmt(p.parseClause(false))
 	}
 
 	p.expect(token.While)
@@ -815,13 +1093,13 @@ func (p *parser) parseIfStatement() ast.Stmt {
 		node.Consequent = p.makeStmt(p.parseBlockStatement())
 	} else {
 		p.scope.allowLet = false
-		node.Consequent = p.makeStmt(p.parseStatement())
+		node.Consequent = p.makeStmt(p.parseClause(true))
 	}
 
 	if p.token == token.Else {
 		p.next()
 		p.scope.allowLet = false
-		node.Alternate = p.makeStmt(p.parseStatement())
+		node.Alternate = p.makeStmt(p.parseClause(true))
 	}
 
 	return node
@@ -830,16 +1108,30 @@ func (p *parser) parseIfStatement() ast.Stmt {
 func (p *parser) parseSourceElements() (body ast.Statements) {
 	for p.token != token.Eof {
 		p.scope.allowLet = true
-		body = append(body, ast.Statement{Stmt: p.parseStatement()})
+		idx := p.idx
+		stmt := p.parseModuleItem()
+		if !p.isModule() {
+			p.forbidUsing(stmt, "at the top level of a script")
+		}
+		body = p.appendStatement(body, stmt)
+		p.skipStalled(idx)
 	}
 
 	return body
 }
 
 func (p *parser) parseProgram() *ast.Program {
-	return &ast.Program{
-		Body: p.parseSourceElements(),
+	program := &ast.Program{
+		SourceType: p.opts.SourceType,
+	}
+	program.Directives, program.Body = p.parseDirectives()
+	program.Body = append(program.Body, p.parseSourceElements()...)
+	if p.isModule() {
+		p.checkModule(program.Body)
 	}
+	program.Strict = p.scope.strict
+	program.WebCompat = p.webCompat()
+	return program
 }
 
 func (p *parser) parseBreakStatement() ast.Stmt {
@@ -864,7 +1156,7 @@ func (p *parser) parseBreakStatement() ast.Stmt {
 	if p.token == token.Identifier {
 		identifier := p.parseIdentifier()
 		if !p.scope.hasLabel(identifier.Name) {
-			p.error(identifier.Name)
+			p.errorAtNode(identifier, CodeUndefinedLabel, identifier.Name)
 			return &ast.BadStatement{From: idx, To: identifier.Idx1()}
 		}
 		p.semicolon()
@@ -877,7 +1169,7 @@ func (p *parser) parseBreakStatement() ast.Stmt {
 	p.expect(token.Identifier)
 
 illegal:
-	p.error("Illegal break statement")
+	p.error(CodeIllegalBreak)
 	p.nextStatement()
 	return &ast.BadStatement{From: idx, To: p.idx}
 }
@@ -904,7 +1196,7 @@ func (p *parser) parseContinueStatement() ast.Stmt {
 	if p.token == token.Identifier {
 		identifier := p.parseIdentifier()
 		if !p.scope.hasLabel(identifier.Name) {
-			p.error(identifier.Name)
+			p.errorAtNode(identifier, CodeUndefinedLabel, identifier.Name)
 			return &ast.BadStatement{From: idx, To: identifier.Idx1()}
 		}
 		if !p.scope.inIteration {
@@ -920,13 +1212,17 @@ func (p *parser) parseContinueStatement() ast.Stmt {
 	p.expect(token.Identifier)
 
 illegal:
-	p.error("Illegal continue statement")
+	p.error(CodeIllegalContinue)
 	p.nextStatement()
 	return &ast.BadStatement{From: idx, To: p.idx}
 }
 
 // Find the next statement after an error (recover)
 func (p *parser) nextStatement() {
+	if p.opts.Tolerant {
+		p.nextStatementTolerant()
+		return
+	}
 	for {
 		switch p.token {
 		case token.Break, token.Continue,
@@ -957,3 +1253,148 @@ func (p *parser) nextStatement() {
 		p.next()
 	}
 }
+
+// nextStatementTolerant is like nextStatement, but also stops at the end of
+// the broken statement: in front of a semicolon or closing brace, or at a
+// statement keyword or identifier that starts a new line. Stopping anywhere
+// else implies a semicolon, so that the enclosing statement ends there. The
+// statement list that the parser returns to guarantees progress, see
+// skipStalled.
+func (p *parser) nextStatementTolerant() {
+	for {
+		switch p.token {
+		case token.Semicolon, token.RightBrace:
+			if p.idx == p.recover.idx && p.recover.count < 10 {
+				p.recover.count++
+				return
+			}
+			if p.idx > p.recover.idx {
+				p.recover.idx = p.idx
+				p.recover.count = 0
+				return
+			}
+		case token.Break, token.Continue, token.Const, token.Class,
+			token.For, token.Function, token.If, token.Return, token.Switch,
+			token.Var, token.Do, token.Try, token.With, token.Import, token.Export,
+			token.While, token.Throw, token.Catch, token.Finally:
+			if p.idx == p.recover.idx && p.recover.count < 10 {
+				p.recover.count++
+				p.implicitSemicolon = true
+				return
+			}
+			if p.idx > p.recover.idx {
+				p.recover.idx = p.idx
+				p.recover.count = 0
+				p.implicitSemicolon = true
+				return
+			}
+		case token.Identifier, token.Let, token.Async:
+			if p.idx > p.recover.idx && p.onNewLine() {
+				p.recover.idx = p.idx
+				p.recover.count = 0
+				p.implicitSemicolon = true
+				return
+			}
+		case token.Eof:
+			return
+		}
+		p.next()
+	}
+}
+
+// onNewLine reports whether only blanks separate the current token from the
+// preceding line terminator.
+func (p *parser) onNewLine() bool {
+	for i := p.offsetOf(p.idx) - 1; i >= 0; i-- {
+		switch p.str[i] {
+		case ' ', '\t', '\v', '\f':
+		case '\n', '\r':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// skipStalled skips the current token if the statement that started at idx
+// did not consume anything, which can only happen after an error.
+func (p *parser) skipStalled(idx ast.Idx) {
+	if p.idx == idx && p.token != token.Eof {
+		p.next()
+	}
+}
+
+// usingDeclaration reports whether a using or await using declaration starts
+// at the current token, and returns token.Using or token.AwaitUsing if so.
+// Otherwise using is an identifier, which it is when followed by a line break
+// or anything but a binding identifier, and in the head of a for statement
+// when followed by of.
+func (p *parser) usingDeclaration(forHead bool) token.Token {
+	kind := token.Using
+	if p.token == token.Await {
+		if !p.scope.allowAwait || !p.scope.inAsync {
+			return 0
+		}
+		kind = token.AwaitUsing
+	} else if !p.isContextual("using") {
+		return 0
+	}
+
+	var state parserState
+	p.mark(&state)
+	defer p.restore(&state)
+	if kind == token.AwaitUsing {
+		p.next()
+		if p.implicitSemicolon || !p.isContextual("using") {
+			return 0
+		}
+	}
+	p.next()
+	if p.implicitSemicolon || p.token == token.Await || !p.isBindingId(p.token) ||
+		forHead && kind == token.Using && p.isContextual("of") {
+		return 0
+	}
+	return kind
+}
+
+// expectDeclarationKind consumes the keywords of a declaration of the given
+// kind and returns the position of the first.
+func (p *parser) expectDeclarationKind(tok token.Token) ast.Idx {
+	switch tok {
+	case token.Using:
+		idx := p.idx
+		p.next()
+		return idx
+	case token.AwaitUsing:
+		idx := p.idx
+		p.next()
+		p.next()
+		return idx
+	}
+	return p.expect(tok)
+}
+
+// checkUsingBindings reports binding patterns in a using declaration, and
+// missing initializers unless it is the head of a for-in or for-of statement.
+func (p *parser) checkUsingBindings(list ast.VariableDeclarators, forInOf bool) {
+	for _, item := range list {
+		if _, ok := item.Target.Target.(*ast.Identifier); !ok {
+			p.errorAt(item.Idx0(), CodeUsingPattern)
+			return
+		}
+		if item.Initializer == nil && !forInOf {
+			p.errorAt(item.Idx0(), CodeUsingWithoutInitializer)
+			return
+		}
+	}
+}
+
+// forbidUsing reports stmt if it is a using declaration, which may not appear
+// where described by where.
+func (p *parser) forbidUsing(stmt ast.Stmt, where string) ast.Stmt {
+	if decl, ok := stmt.(*ast.VariableDeclaration); ok && (decl.Token == token.Using || decl.Token == token.AwaitUsing) {
+		p.errorAt(decl.Idx, CodeUsingNotAllowed, where)
+	}
+	return stmt
+}
@@ -0,0 +1,158 @@
+package parser
+
+import (
+	"github.com/t14raptor/go-fast/ast"
+	"github.com/t14raptor/go-fast/token"
+)
+
+// parseDirectives parses the directive prologue at the start of a program or
+// function body. Parsing stops at the first statement that is not a directive,
+// which is returned as the start of the remaining body.
+func (p *parser) parseDirectives() (directives ast.Directives, body ast.Statements) {
+	for p.token == token.String {
+		p.scope.allowLet = true
+		idx, literal := p.idx, p.literal
+		stmt := p.parseStatement()
+		expr, ok := stmt.(*ast.ExpressionStatement)
+		if !ok {
+			return directives, ast.Statements{{Stmt: stmt}}
+		}
+		str, ok := expr.Expression.Expr.(*ast.StringLiteral)
+		if !ok {
+			return directives, ast.Statements{{Stmt: stmt}}
+		}
+		directive := ast.Directive{
+			Idx:    idx,
+			Value:  literal[1 : len(literal)-1],
+			Cooked: str.Value,
+			Raw:    literal,
+		}
+		if directive.Value == "use strict" && !p.scope.strict {
+			p.scope.strict = true
+			// Directives before "use strict" were parsed as sloppy mode code.
+			for _, d := range directives {
+				p.checkStrictLiteral(d.Idx, token.String, d.Raw)
+			}
+		}
+		directives = append(directives, directive)
+	}
+	return directives, nil
+}
+
+// hasUseStrict reports whether directives contains a "use strict" directive.
+func hasUseStrict(directives ast.Directives) bool {
+	for _, d := range directives {
+		if d.Value == "use strict" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkStrictLiteral reports legacy octal number literals and octal escapes
+// in string literals, which are not allowed in strict mode code.
+func (p *parser) checkStrictLiteral(idx ast.Idx, tkn token.Token, literal string) {
+	if !p.scope.strict {
+		return
+	}
+	switch tkn {
+	case token.Number:
+		if len(literal) < 2 || literal[0] != '0' || !isDecimalDigit(rune(literal[1])) {
+			return
+		}
+		for _, chr := range literal[1:] {
+			if chr > '7' {
+				p.errorAt(idx, CodeLeadingZeroInStrictMode)
+				return
+			}
+		}
+		p.errorAt(idx, CodeOctalInStrictMode)
+	case token.String:
+		for i := 1; i < len(literal)-1; i++ {
+			if literal[i] != '\\' {
+				continue
+			}
+			i++
+			switch chr := literal[i]; {
+			case chr == '0':
+				if i+1 < len(literal) && isDecimalDigit(rune(literal[i+1])) {
+					p.errorAt(idx, CodeOctalEscapeInStrictMode)
+					return
+				}
+			case '1' <= chr && chr <= '7':
+				p.errorAt(idx, CodeOctalEscapeInStrictMode)
+				return
+			case chr == '8' || chr == '9':
+				p.errorAt(idx, CodeNonOctalEscapeInStrictMode)
+				return
+			}
+		}
+	}
+}
+
+// checkStrictIdentifier reports eval and arguments used as a binding or
+// assignment target in strict mode code.
+func (p *parser) checkStrictIdentifier(ident *ast.Identifier) {
+	if p.scope.strict && isEvalOrArguments(ident.Name) {
+		p.errorAtNode(ident, CodeEvalOrArgumentsInStrictMode)
+	}
+}
+
+func isEvalOrArguments(name string) bool {
+	return name == "eval" || name == "arguments"
+}
+
+// checkStrictBinding is like checkStrictIdentifier but applies to every name
+// bound by target.
+func (p *parser) checkStrictBinding(target ast.Expr) {
+	if !p.scope.strict {
+		return
+	}
+	ast.BoundNames(target, func(ident *ast.Identifier) {
+		p.checkStrictIdentifier(ident)
+	})
+}
+
+// checkParameters checks a parameter list once the strictness of the
+// function body is known. Duplicate parameter names are only allowed in
+// sloppy mode functions with a simple parameter list, unless unique is set.
+func (p *parser) checkParameters(params *ast.ParameterList, strict, unique bool) {
+	unique = unique || strict || !isSimpleParameterList(params)
+	seen := map[string]*ast.Identifier{}
+	check := func(ident *ast.Identifier) {
+		if strict && isEvalOrArguments(ident.Name) {
+			p.errorAtNode(ident, CodeEvalOrArgumentsInStrictMode)
+		}
+		if first := seen[ident.Name]; unique && first != nil {
+			e := p.errorAtNode(ident, CodeDuplicateParameter)
+			e.Related = p.span(first.Idx, len(first.Name), "The first declaration")
+		} else if first == nil {
+			seen[ident.Name] = ident
+		}
+	}
+	for _, param := range params.List {
+		if param.Target != nil {
+			ast.BoundNames(param.Target.Target, check)
+		}
+	}
+	if params.Rest != nil {
+		ast.BoundNames(params.Rest, check)
+	}
+}
+
+// isSimpleParameterList reports whether params consists of plain identifiers
+// only, without patterns, default values or a rest parameter.
+func isSimpleParameterList(params *ast.ParameterList) bool {
+	if params.Rest != nil {
+		return false
+	}
+	for _, param := range params.List {
+		if param.Initializer != nil || param.Target == nil {
+			return false
+		}
+		if _, ok := param.Target.Target.(*ast.Identifier); !ok {
+			return false
+		}
+	}
+	return true
+}
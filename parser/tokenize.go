@@ -0,0 +1,220 @@
+package parser
+
+import (
+	"iter"
+	"strings"
+
+	"github.com/t14raptor/go-fast/ast"
+	"github.com/t14raptor/go-fast/token"
+)
+
+// Token is a single token of a source text, as produced by Tokenize.
+type Token struct {
+	Kind token.Token
+	// Literal is the source text of the token.
+	Literal string
+	// Parsed is the value of an identifier, keyword, string, template or
+	// comment token with escapes interpreted and delimiters removed.
+	Parsed string
+	Idx    ast.Idx
+	// NewLineBefore is set when a line terminator separates the token from
+	// the previous one that is not a comment or whitespace.
+	NewLineBefore bool
+}
+
+// Idx1 returns the position just past the token.
+func (t Token) Idx1() ast.Idx { return t.Idx + ast.Idx(len(t.Literal)) }
+
+// TokenizeOptions configures Tokenize.
+type TokenizeOptions struct {
+	// Comments includes comments, including a hashbang line, in the token
+	// stream as token.Comment.
+	Comments bool
+	// Whitespace includes runs of whitespace and line terminators in the
+	// token stream as token.Whitespace.
+	Whitespace bool
+}
+
+// Tokenize returns an iterator over the tokens of src, without parsing it.
+//
+// Whether a slash starts a regular expression or is a division depends on
+// the syntactic context, which Tokenize approximates by looking at the
+// previous token. A template literal is split into token.Template parts
+// around its substitutions, such as "`a${", "}b${" and "}c`". Malformed
+// input yields token.Illegal tokens; the stream always ends at the end of src.
+func Tokenize(src string, opts TokenizeOptions) iter.Seq[Token] {
+	return func(yield func(Token) bool) {
+		t := &tokenizer{p: newParser(src), opts: opts}
+		// Comments are collected to tell them apart from whitespace.
+		t.p.opts.Comments = opts.Comments || opts.Whitespace
+		t.run(yield)
+	}
+}
+
+type tokenizer struct {
+	p    *parser
+	opts TokenizeOptions
+
+	end       int         // The offset after the previous token
+	comments  int         // The first comment that is not yielded yet
+	prev      token.Token // The previous token that is not trivia
+	templates []int       // Open braces in each enclosing template substitution
+}
+
+func (t *tokenizer) run(yield func(Token) bool) {
+	p := t.p
+	for {
+		kind, literal, parsed, idx := p.scan()
+		start := p.offsetOf(idx)
+		newLine, ok := t.trivia(start, yield)
+		if !ok || kind == token.Eof {
+			return
+		}
+
+		end := p.chrOffset
+		if literal != "" {
+			// The lexer may have looked past the token already.
+			end = start + len(literal)
+		}
+		switch kind {
+		case token.Slash, token.QuotientAssign:
+			if t.regExpAllowed() {
+				kind, end = t.scanRegExp(start)
+			}
+		case token.Backtick:
+			kind, parsed, end = t.scanTemplate()
+		case token.LeftBrace:
+			if n := len(t.templates); n > 0 {
+				t.templates[n-1]++
+			}
+		case token.RightBrace:
+			if n := len(t.templates); n > 0 {
+				if t.templates[n-1] == 0 {
+					t.templates = t.templates[:n-1]
+					kind, parsed, end = t.scanTemplate()
+				} else {
+					t.templates[n-1]--
+				}
+			}
+		}
+
+		tok := Token{
+			Kind:          kind,
+			Literal:       p.str[start:end],
+			Parsed:        parsed,
+			Idx:           idx,
+			NewLineBefore: newLine,
+		}
+		t.end, t.prev = end, kind
+		if !yield(tok) {
+			return
+		}
+	}
+}
+
+// trivia yields the comments and whitespace between the previous token and
+// the offset start, as far as they were asked for. It reports whether they
+// contain a line terminator and whether iteration should go on.
+func (t *tokenizer) trivia(start int, yield func(Token) bool) (newLine bool, ok bool) {
+	p := t.p
+	newLine = strings.ContainsAny(p.str[t.end:start], "\r\n\u2028\u2029")
+	for offset := t.end; offset < start; {
+		var tok Token
+		if t.comments < len(p.comments) && p.offsetOf(p.comments[t.comments].Idx) == offset {
+			c := p.comments[t.comments]
+			t.comments++
+			tok = Token{
+				Kind:    token.Comment,
+				Literal: p.str[offset:p.offsetOf(c.Idx1())],
+				Parsed:  c.Text,
+				Idx:     c.Idx,
+			}
+		} else {
+			end := start
+			if t.comments < len(p.comments) {
+				end = min(end, p.offsetOf(p.comments[t.comments].Idx))
+			}
+			tok = t.whitespace(offset, end)
+		}
+		offset += len(tok.Literal)
+		if tok.Kind == token.Whitespace && !t.opts.Whitespace || tok.Kind == token.Comment && !t.opts.Comments {
+			continue
+		}
+		if !yield(tok) {
+			return newLine, false
+		}
+	}
+	return newLine, true
+}
+
+// whitespace returns the next token in the source text between offset and
+// end that is not a collected comment. Besides whitespace this can only be a
+// hashbang line, which the lexer skips, or an unterminated comment.
+func (t *tokenizer) whitespace(offset, end int) Token {
+	p := t.p
+	tok := Token{
+		Kind:    token.Whitespace,
+		Literal: p.str[offset:end],
+		Idx:     p.idxOf(offset),
+	}
+	i := strings.IndexFunc(tok.Literal, func(chr rune) bool {
+		return !isLineWhiteSpace(chr) && !isLineTerminator(chr)
+	})
+	switch {
+	case i > 0:
+		tok.Literal = tok.Literal[:i]
+	case i == 0 && strings.HasPrefix(tok.Literal, "#!"):
+		if i := strings.IndexFunc(tok.Literal, isLineTerminator); i >= 0 {
+			tok.Literal = tok.Literal[:i]
+		}
+		tok.Kind, tok.Parsed = token.Comment, tok.Literal[2:]
+	case i == 0:
+		tok.Kind = token.Illegal
+	}
+	return tok
+}
+
+// regExpAllowed reports whether a slash after the previous token starts a
+// regular expression, which is the case unless the token ends an operand.
+func (t *tokenizer) regExpAllowed() bool {
+	switch t.prev {
+	case token.Identifier, token.PrivateIdentifier, token.Keyword, token.EscapedReservedWord,
+		token.Number, token.BigInt, token.String, token.RegExp,
+		token.Boolean, token.Null, token.This, token.Super,
+		token.Let, token.Static, token.Async,
+		token.RightParenthesis, token.RightBracket, token.RightBrace,
+		token.Increment, token.Decrement:
+		return false
+	case token.Template:
+		// Only the head or middle of a template literal is followed by an operand.
+		return len(t.templates) > 0 && t.p.str[t.end-1] == '{'
+	}
+	return true
+}
+
+// scanRegExp scans a regular expression literal starting at offset, right
+// after its first slash was scanned as a division.
+func (t *tokenizer) scanRegExp(offset int) (token.Token, int) {
+	p := t.p
+	if _, _, err := p.scanString(offset, false); err != "" {
+		return token.Illegal, p.chrOffset
+	}
+	for isIdentifierPart(p.chr) {
+		p.read()
+	}
+	return token.RegExp, p.chrOffset
+}
+
+// scanTemplate scans a template literal part after its opening backtick or
+// the closing brace of a substitution.
+func (t *tokenizer) scanTemplate() (token.Token, string, int) {
+	p := t.p
+	_, parsed, finished, _, err := p.parseTemplateCharacters(true)
+	if err != "" {
+		return token.Illegal, "", p.chrOffset
+	}
+	if !finished {
+		t.templates = append(t.templates, 0)
+	}
+	return token.Template, parsed, p.chrOffset
+}
@@ -0,0 +1,1131 @@
+package parser
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/t14raptor/go-fast/ast"
+	"github.com/t14raptor/go-fast/token"
+)
+
+// This file implements the TypeScript syntax of Options.TypeScript. Types are
+// skipped token by token without building any nodes, declarations that only
+// exist for the type checker are stripped, and enums and parameter properties
+// are lowered to plain JavaScript.
+
+// strip returns the statement for a stripped declaration starting at idx,
+// which statement lists leave out, see appendStatement. Elsewhere it remains
+// an empty statement.
+func (p *parser) strip(idx ast.Idx) ast.Stmt {
+	p.stripped = &ast.EmptyStatement{Semicolon: idx}
+	return p.stripped
+}
+
+// appendStatement appends stmt to list, unless it is a stripped declaration.
+func (p *parser) appendStatement(list ast.Statements, stmt ast.Stmt) ast.Statements {
+	if stmt == p.stripped {
+		return list
+	}
+	p.limits.Count()
+	return append(list, ast.Statement{Stmt: stmt})
+}
+
+// isContextual reports whether the current token is the identifier name.
+func (p *parser) isContextual(name string) bool {
+	return p.token == token.Identifier && p.literal == name
+}
+
+// parseTypeScriptDeclaration parses a declaration that only exists in
+// TypeScript, or a function without a body, if one starts at the current
+// token. Otherwise it returns nil without consuming anything.
+func (p *parser) parseTypeScriptDeclaration() ast.Stmt {
+	start := p.idx
+	switch p.token {
+	case token.Keyword:
+		if p.literal == "enum" {
+			return p.parseEnum(start)
+		}
+	case token.Const:
+		if p.peek() == token.Keyword {
+			p.next()
+			return p.parseEnum(start)
+		}
+	case token.Function, token.Async:
+		if p.skipFunctionSignature() {
+			return p.strip(start)
+		}
+	case token.Identifier:
+		switch p.literal {
+		case "interface", "type", "namespace", "module", "declare", "abstract":
+		default:
+			return nil
+		}
+		var state parserState
+		p.mark(&state)
+		keyword := p.literal
+		p.next()
+		if p.implicitSemicolon {
+			p.restore(&state)
+			return nil
+		}
+		switch keyword {
+		case "interface":
+			if p.isBindingId(p.token) {
+				p.skipInterface()
+				return p.strip(start)
+			}
+		case "type":
+			if p.isBindingId(p.token) {
+				p.skipTypeAlias()
+				return p.strip(start)
+			}
+		case "namespace", "module":
+			if p.isBindingId(p.token) {
+				return p.parseNamespace(start)
+			}
+		case "declare":
+			switch p.token {
+			case token.Var, token.Let, token.Const, token.Function, token.Class, token.Async, token.Keyword, token.Identifier:
+				p.skipDeclare()
+				return p.strip(start)
+			}
+		case "abstract":
+			if p.token == token.Class {
+				return &ast.ClassDeclaration{Class: p.parseClass(true)}
+			}
+		}
+		p.restore(&state)
+	}
+	return nil
+}
+
+// skipFunctionSignature skips a function declaration without a body, which is
+// an overload or ambient declaration. It reports false without consuming
+// anything if the function has a body.
+func (p *parser) skipFunctionSignature() bool {
+	var state parserState
+	p.mark(&state)
+	if p.token == token.Async {
+		p.next()
+		if p.token != token.Function {
+			p.restore(&state)
+			return false
+		}
+	}
+	p.next()
+	if p.token == token.Multiply {
+		p.next()
+	}
+	if token.ID(p.token) {
+		p.next()
+	}
+	if p.skipSignature() {
+		p.semicolon()
+		return true
+	}
+	p.restore(&state)
+	return false
+}
+
+// skipSignature skips the type parameters, parameters and return type of a
+// function or method and reports whether they are not followed by a body.
+func (p *parser) skipSignature() bool {
+	if p.token == token.Less {
+		p.skipTypeParameters()
+	}
+	if p.token != token.LeftParenthesis {
+		return false
+	}
+	p.skipBalanced()
+	if p.token == token.Colon {
+		p.next()
+		p.skipType()
+	}
+	return p.token != token.LeftBrace
+}
+
+// skipMethodSignature skips a method without a body in a class, which is an
+// overload or abstract method. It reports false without consuming anything
+// if the method has a body.
+func (p *parser) skipMethodSignature() bool {
+	var state parserState
+	p.mark(&state)
+	if p.skipSignature() {
+		return true
+	}
+	p.restore(&state)
+	return false
+}
+
+func (p *parser) skipInterface() {
+	p.next()
+	if p.token == token.Less {
+		p.skipTypeParameters()
+	}
+	if p.token == token.Extends {
+		p.next()
+		p.skipType()
+		for p.token == token.Comma {
+			p.next()
+			p.skipType()
+		}
+	}
+	if p.token != token.LeftBrace {
+		p.errorUnexpectedToken(p.token)
+		return
+	}
+	p.skipBalanced()
+}
+
+func (p *parser) skipTypeAlias() {
+	p.next()
+	if p.token == token.Less {
+		p.skipTypeParameters()
+	}
+	p.expect(token.Assign)
+	p.skipType()
+	p.semicolon()
+}
+
+// skipDeclare skips an ambient declaration after its declare keyword.
+func (p *parser) skipDeclare() {
+	if p.isContextual("global") || p.isContextual("module") || p.isContextual("namespace") {
+		p.next()
+		for p.token != token.LeftBrace && p.token != token.Semicolon && p.token != token.Eof && !p.implicitSemicolon {
+			p.next()
+		}
+		if p.token == token.LeftBrace {
+			p.skipBalanced()
+		} else {
+			p.semicolon()
+		}
+		return
+	}
+	// The declaration is parsed like any other and dropped; functions and
+	// methods without a body are already allowed in TypeScript.
+	p.parseStatement()
+}
+
+// parseNamespace parses a namespace after its namespace or module keyword.
+// Namespaces that only declare types are stripped, others are an error.
+func (p *parser) parseNamespace(start ast.Idx) ast.Stmt {
+	p.next()
+	for p.token == token.Period {
+		p.next()
+		p.expect(token.Identifier)
+	}
+	p.expect(token.LeftBrace)
+	instantiated := false
+	for p.token != token.RightBrace && p.token != token.Eof {
+		p.scope.allowLet = true
+		idx := p.idx
+		if p.token == token.Export {
+			p.next()
+		}
+		if p.parseStatement() != p.stripped {
+			instantiated = true
+		}
+		p.skipStalled(idx)
+	}
+	p.expect(token.RightBrace)
+	if instantiated {
+		p.errorAt(start, CodeTSNamespaceWithCode)
+	}
+	return p.strip(start)
+}
+
+// parseEnum parses an enum declaration, starting at its enum keyword, and
+// lowers it to a variable holding the members and the reverse mapping of the
+// numeric ones:
+//
+//	var E = function (E) {
+//		E[E["A"] = 0] = "A";
+//		E["B"] = "b";
+//		return E;
+//	}(E || {});
+//
+// The parameter of the function merges the members into an earlier
+// declaration of the same enum.
+func (p *parser) parseEnum(start ast.Idx) ast.Stmt {
+	if p.literal != "enum" {
+		p.errorUnexpectedToken(p.token)
+	}
+	p.next()
+	p.tokenToBindingId()
+	if p.token != token.Identifier {
+		p.expect(token.Identifier)
+		p.nextStatement()
+		return &ast.BadStatement{From: start, To: p.idx}
+	}
+	name := p.parseIdentifier()
+	p.checkStrictIdentifier(name)
+	enum := func() *ast.Expression {
+		return &ast.Expression{Expr: &ast.Identifier{Idx: name.Idx, Name: name.Name}}
+	}
+	member := func(key ast.Expr) *ast.Expression {
+		return &ast.Expression{Expr: &ast.MemberExpression{
+			Object:   enum(),
+			Property: &ast.MemberProperty{Prop: &ast.ComputedProperty{Expr: &ast.Expression{Expr: key}}},
+		}}
+	}
+	assign := func(left, right *ast.Expression) *ast.Expression {
+		return &ast.Expression{Expr: &ast.AssignExpression{Left: left, Operator: token.Assign, Right: right}}
+	}
+
+	refs := &enumMembers{enum: name.Name, names: map[string]bool{}}
+	refs.V = refs
+	var body ast.Statements
+	// The value of the previous member, if it is a known number, and its
+	// name, to number the members without an initializer.
+	value, known, previous := 0.0, true, ""
+
+	p.expect(token.LeftBrace)
+	for p.token != token.RightBrace && p.token != token.Eof {
+		idx := p.idx
+		if p.token != token.String && !token.ID(p.token) {
+			p.errorUnexpectedToken(p.token)
+			p.nextStatement()
+			return &ast.BadStatement{From: start, To: p.idx}
+		}
+		key := p.parsedLiteral
+		p.next()
+
+		var init *ast.Expression
+		if p.token == token.Assign {
+			p.next()
+			init = p.makeExpr(p.parseAssignmentExpression())
+			init.VisitWith(refs)
+		}
+		refs.names[key] = true
+
+		var expr ast.Expr
+		if init != nil {
+			expr = init.Expr
+		}
+		numeric := true
+		switch expr := expr.(type) {
+		case nil:
+			switch {
+			case known:
+				init = &ast.Expression{Expr: &ast.NumberLiteral{Idx: idx, Value: value}}
+			case previous != "":
+				init = &ast.Expression{Expr: &ast.BinaryExpression{
+					Operator: token.Plus,
+					Left:     member(&ast.StringLiteral{Value: previous}),
+					Right:    &ast.Expression{Expr: &ast.NumberLiteral{Value: 1}},
+				}}
+			default:
+				p.errorAt(idx, CodeTSEnumInitializer)
+			}
+		case *ast.NumberLiteral:
+			value, known = expr.Value, true
+		case *ast.UnaryExpression:
+			if number, ok := expr.Operand.Expr.(*ast.NumberLiteral); ok && expr.Operator == token.Minus {
+				value, known = -number.Value, true
+			} else {
+				known = false
+			}
+		case *ast.StringLiteral:
+			numeric, known = false, false
+		case *ast.TemplateLiteral:
+			numeric = len(expr.Expressions) > 0
+			known = false
+		default:
+			known = false
+		}
+		value++
+		previous = key
+		if !numeric {
+			previous = ""
+		}
+
+		keyLiteral := &ast.StringLiteral{Idx: idx, Value: key}
+		var stmt *ast.Expression
+		if numeric && init != nil {
+			stmt = assign(member(assign(member(keyLiteral), init).Expr), &ast.Expression{Expr: &ast.StringLiteral{Value: key}})
+		} else if init != nil {
+			stmt = assign(member(keyLiteral), init)
+		}
+		if stmt != nil {
+			body = append(body, ast.Statement{Stmt: &ast.ExpressionStatement{Expression: stmt}})
+		}
+
+		if p.token != token.RightBrace {
+			p.expect(token.Comma)
+		}
+	}
+	end := p.expect(token.RightBrace)
+	body = append(body, ast.Statement{Stmt: &ast.ReturnStatement{Return: end, Argument: enum()}})
+
+	fn := &ast.FunctionLiteral{
+		Function: start,
+		ParameterList: ast.ParameterList{
+			List: ast.VariableDeclarators{{Target: &ast.BindingTarget{Target: &ast.Identifier{Idx: name.Idx, Name: name.Name}}}},
+		},
+		Body:   &ast.BlockStatement{List: body},
+		Strict: p.scope.strict,
+	}
+	call := &ast.CallExpression{
+		Callee: &ast.Expression{Expr: fn},
+		ArgumentList: ast.Expressions{{Expr: &ast.BinaryExpression{
+			Operator: token.LogicalOr,
+			Left:     enum(),
+			Right:    &ast.Expression{Expr: &ast.ObjectLiteral{}},
+		}}},
+	}
+	return &ast.VariableDeclaration{
+		Idx:   start,
+		Token: token.Var,
+		List: ast.VariableDeclarators{{
+			Target:      &ast.BindingTarget{Target: name},
+			Initializer: &ast.Expression{Expr: call},
+		}},
+	}
+}
+
+// enumMembers rewrites references to the earlier members of an enum in the
+// initializer of a member, as in B = A + 1, to property accesses.
+type enumMembers struct {
+	ast.NoopVisitor
+
+	enum  string
+	names map[string]bool
+}
+
+func (v *enumMembers) VisitExpression(n *ast.Expression) {
+	if id, ok := n.Expr.(*ast.Identifier); ok && v.names[id.Name] {
+		n.Expr = &ast.MemberExpression{
+			Object:   &ast.Expression{Expr: &ast.Identifier{Idx: id.Idx, Name: v.enum}},
+			Property: &ast.MemberProperty{Prop: &ast.Identifier{Idx: id.Idx, Name: id.Name}},
+		}
+		return
+	}
+	n.VisitChildrenWith(v)
+}
+
+func (v *enumMembers) VisitPropertyKeyed(n *ast.PropertyKeyed) {
+	if n.Computed {
+		n.Key.VisitWith(v)
+	}
+	n.Value.VisitWith(v)
+}
+
+// skipParameterModifiers skips the accessibility and readonly modifiers of a
+// parameter and reports whether there were any, which makes the parameter a
+// parameter property.
+func (p *parser) skipParameterModifiers() (found bool) {
+	for p.token == token.Identifier {
+		switch p.literal {
+		case "public", "private", "protected", "readonly", "override":
+		default:
+			return found
+		}
+		switch p.peek() {
+		case token.Comma, token.RightParenthesis, token.Colon, token.Assign, token.QuestionMark:
+			// The modifier is the name of the parameter.
+			return found
+		}
+		p.next()
+		found = true
+	}
+	return found
+}
+
+// addParameterProperties assigns the parameter properties of a constructor to
+// the instance at the start of its body, or right after the super call in a
+// derived class.
+func addParameterProperties(body *ast.BlockStatement, params []*ast.Identifier) {
+	stmts := make(ast.Statements, 0, len(params))
+	for _, param := range params {
+		stmts = append(stmts, ast.Statement{Stmt: &ast.ExpressionStatement{Expression: &ast.Expression{Expr: &ast.AssignExpression{
+			Left: &ast.Expression{Expr: &ast.MemberExpression{
+				Object:   &ast.Expression{Expr: &ast.ThisExpression{Idx: param.Idx}},
+				Property: &ast.MemberProperty{Prop: &ast.Identifier{Idx: param.Idx, Name: param.Name}},
+			}},
+			Operator: token.Assign,
+			Right:    &ast.Expression{Expr: &ast.Identifier{Idx: param.Idx, Name: param.Name}},
+		}}}})
+	}
+	at := 0
+	for i, stmt := range body.List {
+		if isSuperCall(stmt.Stmt) {
+			at = i + 1
+			break
+		}
+	}
+	body.List = slices.Insert(body.List, at, stmts...)
+}
+
+func isSuperCall(stmt ast.Stmt) bool {
+	expr, ok := stmt.(*ast.ExpressionStatement)
+	if !ok {
+		return false
+	}
+	call, ok := expr.Expression.Expr.(*ast.CallExpression)
+	if !ok {
+		return false
+	}
+	_, ok = call.Callee.Expr.(*ast.SuperExpression)
+	return ok
+}
+
+// skipMemberModifiers skips the TypeScript modifiers of a class member and
+// reports whether the member is declare or abstract, which leaves nothing to
+// emit for it.
+func (p *parser) skipMemberModifiers() (ambient bool) {
+	for p.token == token.Identifier {
+		switch p.literal {
+		case "public", "private", "protected", "readonly", "override":
+		case "declare", "abstract":
+			ambient = true
+		default:
+			return ambient
+		}
+		switch tok := p.peek(); tok {
+		case token.Semicolon, token.LeftParenthesis, token.Assign, token.RightBrace:
+			return false
+		default:
+			if p.isTypeScriptMemberName(tok) {
+				return false
+			}
+		}
+		p.next()
+	}
+	return ambient
+}
+
+// isTypeScriptMemberName reports whether a modifier like static or get that is
+// followed by tok is the name of a class member instead, as in static?: T.
+func (p *parser) isTypeScriptMemberName(tok token.Token) bool {
+	if !p.opts.TypeScript {
+		return false
+	}
+	switch tok {
+	case token.Colon, token.QuestionMark, token.Not, token.Less:
+		return true
+	}
+	return false
+}
+
+// skipIndexSignature skips an index signature like [key: string]: T in a
+// class body and reports false without consuming anything if the [ starts a
+// computed key instead.
+func (p *parser) skipIndexSignature() bool {
+	var state parserState
+	p.mark(&state)
+	p.next()
+	if token.ID(p.token) {
+		p.next()
+		if p.token == token.Colon {
+			p.restore(&state)
+			p.skipBalanced()
+			if p.token == token.Colon {
+				p.next()
+				p.skipType()
+			}
+			return true
+		}
+	}
+	p.restore(&state)
+	return false
+}
+
+// skipBindingAnnotation skips the optional marker of a parameter, the definite
+// assignment assertion of a variable and the type annotation after a binding.
+func (p *parser) skipBindingAnnotation() {
+	if p.token == token.QuestionMark && p.scope.inFuncParams || p.token == token.Not {
+		p.next()
+	}
+	if p.token == token.Colon {
+		p.next()
+		p.skipType()
+	}
+}
+
+// parseTypeScriptImport parses an import declaration after its import keyword
+// if it is type-only, which is stripped, or an import alias like
+// import x = require("x"), which is lowered to a const declaration. Otherwise
+// it returns nil without consuming anything.
+func (p *parser) parseTypeScriptImport(start ast.Idx) ast.Stmt {
+	var state parserState
+	p.mark(&state)
+	if p.isContextual("type") {
+		p.next()
+		typeOnly := p.token == token.LeftBrace || p.token == token.Multiply
+		if p.token == token.Identifier {
+			// import type from "x" imports a binding named type.
+			typeOnly = p.literal != "from" || p.peek() != token.String
+		}
+		if typeOnly {
+			for p.token != token.String && p.token != token.Eof {
+				p.next()
+			}
+			p.next()
+			if p.token == token.RightParenthesis {
+				p.next()
+			}
+			p.semicolon()
+			return p.strip(start)
+		}
+		p.restore(&state)
+	}
+	if !p.isBindingId(p.token) || p.peek() != token.Assign {
+		return nil
+	}
+	name := p.parseImportedBinding()
+	p.expect(token.Assign)
+	value := p.parseAssignmentExpression()
+	p.semicolon()
+	return &ast.VariableDeclaration{
+		Idx:   start,
+		Token: token.Const,
+		List: ast.VariableDeclarators{{
+			Target:      &ast.BindingTarget{Target: name},
+			Initializer: p.makeExpr(value),
+		}},
+	}
+}
+
+// parseTypeScriptExport parses an export declaration after its export keyword
+// if it uses TypeScript syntax. Otherwise it returns nil without consuming
+// anything.
+func (p *parser) parseTypeScriptExport(idx ast.Idx) ast.Stmt {
+	switch {
+	case p.token == token.Assign:
+		// export = x is lowered to module.exports = x.
+		p.next()
+		value := p.parseAssignmentExpression()
+		p.semicolon()
+		return &ast.ExpressionStatement{Expression: p.makeExpr(&ast.AssignExpression{
+			Left: p.makeExpr(&ast.MemberExpression{
+				Object:   p.makeExpr(&ast.Identifier{Idx: idx, Name: "module"}),
+				Property: &ast.MemberProperty{Prop: &ast.Identifier{Idx: idx, Name: "exports"}},
+			}),
+			Operator: token.Assign,
+			Right:    p.makeExpr(value),
+		})}
+	case p.isContextual("as"):
+		// export as namespace X only declares a global for the type checker.
+		p.next()
+		p.expectContextual("namespace")
+		p.parseIdentifier()
+		p.semicolon()
+		return p.strip(idx)
+	case p.isContextual("type"):
+		switch p.peek() {
+		case token.LeftBrace:
+			p.next()
+			p.parseExportNamedSpecifiers(idx)
+			return p.strip(idx)
+		case token.Multiply:
+			p.next()
+			p.next()
+			if p.isContextual("as") {
+				p.next()
+				p.parseModuleExportName()
+			}
+			p.expectContextual("from")
+			p.parseModuleSpecifier()
+			p.semicolon()
+			return p.strip(idx)
+		}
+	case p.token == token.Import:
+		start := p.idx
+		p.next()
+		if stmt := p.parseTypeScriptImport(start); stmt != nil {
+			if stmt == p.stripped {
+				return stmt
+			}
+			return &ast.ExportNamedDeclaration{Export: idx, Declaration: p.makeStmt(stmt)}
+		}
+		p.errorUnexpectedToken(p.token)
+		p.nextStatement()
+		return &ast.BadStatement{From: idx, To: p.idx}
+	}
+	if stmt := p.parseTypeScriptDeclaration(); stmt != nil {
+		if stmt == p.stripped {
+			return stmt
+		}
+		if _, ok := stmt.(*ast.BadStatement); ok {
+			return stmt
+		}
+		return &ast.ExportNamedDeclaration{Export: idx, Declaration: p.makeStmt(stmt)}
+	}
+	return nil
+}
+
+// skipSpecifierTypeModifier skips the type modifier of an import or export
+// specifier like { type A } and reports whether there was one.
+func (p *parser) skipSpecifierTypeModifier() bool {
+	if !p.opts.TypeScript || !p.isContextual("type") {
+		return false
+	}
+	var state parserState
+	p.mark(&state)
+	p.next()
+	if p.token == token.Comma || p.token == token.RightBrace {
+		// { type } names a binding called type.
+		p.restore(&state)
+		return false
+	}
+	if p.isContextual("as") {
+		// { type as x } renames type, { type as } and { type as as x } do not.
+		var as parserState
+		p.mark(&as)
+		p.next()
+		renamed := p.token != token.Comma && p.token != token.RightBrace && !p.isContextual("as")
+		p.restore(&as)
+		if renamed {
+			p.restore(&state)
+			return false
+		}
+	}
+	return true
+}
+
+// tryArrowFunction parses an arrow function with type annotations, type
+// parameters or a return type, starting at its ( or <. It returns nil without
+// consuming anything if there is no arrow function.
+func (p *parser) tryArrowFunction(start ast.Idx, async bool) ast.Expr {
+	var state parserState
+	p.mark(&state)
+	recover := p.recover
+	restore := func() ast.Expr {
+		p.restore(&state)
+		p.recover = recover
+		return nil
+	}
+
+	// Check the tokens after the parameters first, which is cheaper than
+	// parsing them in vain.
+	if async {
+		p.next()
+	}
+	if p.token == token.Less {
+		p.skipTypeParameters()
+	}
+	if p.token != token.LeftParenthesis || len(p.errors) > state.errorCount {
+		return restore()
+	}
+	p.skipBalanced()
+	if p.token != token.Colon && (p.token != token.Arrow || p.implicitSemicolon) {
+		return restore()
+	}
+	returnType := p.token == token.Colon
+	p.restore(&state)
+
+	if async {
+		p.next()
+		if !p.scope.allowAwait {
+			p.scope.allowAwait = true
+			defer func() {
+				p.scope.allowAwait = false
+			}()
+		}
+	}
+	params := p.parseFunctionParameterList()
+	if p.token != token.Arrow || p.implicitSemicolon || len(p.errors) > state.errorCount {
+		return restore()
+	}
+	arrow := p.parseArrowFunction(start, params, async)
+	if returnType && start == p.consequent && p.token != token.Colon {
+		// In a ? (b) : c => d the colon belongs to the conditional, which
+		// needs one left for its alternate.
+		return restore()
+	}
+	return arrow
+}
+
+// parseTypeAssertion parses a type assertion like <T>x, which is only allowed
+// without JSX.
+func (p *parser) parseTypeAssertion() ast.Expr {
+	p.next()
+	p.skipType()
+	p.expectTypeClose()
+	return p.parseUnaryExpression()
+}
+
+// tryTypeArguments skips the type arguments of a call or an instantiation
+// expression like f<T>, and reports false without consuming anything if the
+// < is a less-than operator instead.
+func (p *parser) tryTypeArguments() bool {
+	var state parserState
+	p.mark(&state)
+	recover := p.recover
+	p.skipTypeArguments()
+	if len(p.errors) == state.errorCount && p.canFollowTypeArguments() {
+		return true
+	}
+	p.restore(&state)
+	p.recover = recover
+	return false
+}
+
+// canFollowTypeArguments reports whether the current token may follow type
+// arguments in an expression, which decides a < b > c in favour of the
+// relational operators.
+func (p *parser) canFollowTypeArguments() bool {
+	switch p.token {
+	case token.LeftParenthesis, token.Backtick:
+		return true
+	case token.Less, token.Greater, token.Plus, token.Minus:
+		return false
+	case token.RightParenthesis, token.RightBracket, token.RightBrace, token.Comma, token.Semicolon,
+		token.Colon, token.QuestionMark, token.Eof,
+		token.Equal, token.StrictEqual, token.NotEqual, token.StrictNotEqual,
+		token.LogicalAnd, token.LogicalOr, token.Coalesce,
+		token.Multiply, token.Slash, token.Remainder, token.Exponent,
+		token.And, token.Or, token.ExclusiveOr,
+		token.ShiftLeft, token.ShiftRight, token.UnsignedShiftRight,
+		token.LessOrEqual, token.GreaterOrEqual, token.InstanceOf, token.In:
+		return true
+	}
+	return p.implicitSemicolon || p.isContextual("as") || p.isContextual("satisfies")
+}
+
+// skipType skips a type, such as a union, intersection, function or
+// conditional type.
+func (p *parser) skipType() {
+	if p.token == token.Or || p.token == token.And {
+		p.next()
+	}
+	p.skipTypeOperand()
+	for p.token == token.Or || p.token == token.And {
+		p.next()
+		p.skipTypeOperand()
+	}
+	if p.token == token.Extends && !p.implicitSemicolon {
+		p.next()
+		p.skipType()
+		p.expect(token.QuestionMark)
+		p.skipType()
+		p.expect(token.Colon)
+		p.skipType()
+	}
+}
+
+func (p *parser) skipTypeOperand() {
+	p.limits.Enter()
+	defer p.limits.Leave()
+	switch p.token {
+	case token.Less:
+		// A generic function type.
+		p.skipTypeParameters()
+		p.skipFunctionType()
+		return
+	case token.New:
+		p.next()
+		if p.token == token.Less {
+			p.skipTypeParameters()
+		}
+		p.skipFunctionType()
+		return
+	case token.LeftParenthesis:
+		p.skipBalanced()
+		if p.token == token.Arrow {
+			p.next()
+			p.skipType()
+			return
+		}
+	case token.LeftBrace, token.LeftBracket:
+		p.skipBalanced()
+	case token.Backtick:
+		p.skipTemplate()
+	case token.Minus:
+		p.next()
+		if p.token != token.Number && p.token != token.BigInt {
+			p.errorUnexpectedToken(p.token)
+			return
+		}
+		p.next()
+	case token.String, token.Number, token.BigInt:
+		p.next()
+	case token.Typeof:
+		p.next()
+		if p.token == token.Import {
+			p.skipImportType()
+		} else {
+			p.skipTypeReference()
+		}
+	case token.Import:
+		p.skipImportType()
+	case token.Identifier:
+		switch p.literal {
+		case "keyof", "unique", "readonly", "infer", "asserts", "abstract":
+			switch tok := p.peek(); {
+			case token.ID(tok), tok == token.LeftParenthesis, tok == token.LeftBracket, tok == token.LeftBrace,
+				tok == token.String, tok == token.Number, tok == token.Backtick:
+				p.next()
+				p.skipTypeOperand()
+				return
+			}
+		}
+		p.skipTypeReference()
+	default:
+		if !token.ID(p.token) {
+			p.errorUnexpectedToken(p.token)
+			return
+		}
+		p.skipTypeReference()
+	}
+	for p.token == token.LeftBracket && !p.implicitSemicolon {
+		p.skipBalanced()
+	}
+	if p.isContextual("is") && !p.implicitSemicolon {
+		// A type predicate like x is T.
+		p.next()
+		p.skipType()
+	}
+}
+
+// skipTypeReference skips a possibly qualified type name like A.B and its
+// type arguments.
+func (p *parser) skipTypeReference() {
+	// Keywords like void end a type as well as identifiers do.
+	p.insertSemicolon = true
+	p.next()
+	for p.token == token.Period {
+		p.next()
+		if !token.ID(p.token) {
+			p.errorUnexpectedToken(p.token)
+			return
+		}
+		p.insertSemicolon = true
+		p.next()
+	}
+	if p.token == token.Less && !p.implicitSemicolon {
+		p.skipTypeArguments()
+	}
+}
+
+// skipImportType skips a type like import("x").A<T>.
+func (p *parser) skipImportType() {
+	p.next()
+	if p.token != token.LeftParenthesis {
+		p.errorUnexpectedToken(p.token)
+		return
+	}
+	p.skipBalanced()
+	for p.token == token.Period {
+		p.next()
+		p.insertSemicolon = true
+		p.next()
+	}
+	if p.token == token.Less && !p.implicitSemicolon {
+		p.skipTypeArguments()
+	}
+}
+
+func (p *parser) skipFunctionType() {
+	if p.token != token.LeftParenthesis {
+		p.errorUnexpectedToken(p.token)
+		return
+	}
+	p.skipBalanced()
+	p.expect(token.Arrow)
+	p.skipType()
+}
+
+// skipTypeArguments skips type arguments like <A, B>.
+func (p *parser) skipTypeArguments() {
+	p.expect(token.Less)
+	for {
+		p.skipType()
+		if p.token != token.Comma {
+			break
+		}
+		p.next()
+	}
+	p.expectTypeClose()
+}
+
+// skipTypeParameters skips type parameters like <const T extends U = V>.
+func (p *parser) skipTypeParameters() {
+	p.expect(token.Less)
+	for p.token != token.Greater && p.token != token.Eof {
+		for (p.token == token.Const || p.token == token.In || p.isContextual("out")) && token.ID(p.peek()) {
+			p.next()
+		}
+		if !p.isBindingId(p.token) {
+			p.errorUnexpectedToken(p.token)
+			return
+		}
+		p.next()
+		if p.token == token.Extends {
+			p.next()
+			p.skipType()
+		}
+		if p.token == token.Assign {
+			p.next()
+			p.skipType()
+		}
+		if p.token != token.Comma {
+			break
+		}
+		p.next()
+	}
+	p.expectTypeClose()
+}
+
+// expectTypeClose consumes the > that closes type parameters or arguments,
+// splitting it off tokens like >> that the scanner reads greedily.
+func (p *parser) expectTypeClose() {
+	var rest token.Token
+	switch p.token {
+	case token.Greater:
+		p.insertSemicolon = true
+		p.next()
+		return
+	case token.ShiftRight:
+		rest = token.Greater
+	case token.UnsignedShiftRight:
+		rest = token.ShiftRight
+	case token.GreaterOrEqual:
+		rest = token.Assign
+	case token.ShiftRightAssign:
+		rest = token.GreaterOrEqual
+	case token.UnsignedShiftRightAssign:
+		rest = token.ShiftRightAssign
+	default:
+		p.errorUnexpectedToken(p.token)
+		return
+	}
+	p.token, p.literal, p.parsedLiteral = rest, rest.String(), ""
+	p.idx++
+}
+
+// skipBalanced skips the tokens from an opening (, [ or { up to and including
+// the matching closing one.
+func (p *parser) skipBalanced() {
+	depth := 0
+	for {
+		switch p.token {
+		case token.LeftParenthesis, token.LeftBracket, token.LeftBrace:
+			depth++
+		case token.RightParenthesis, token.RightBracket, token.RightBrace:
+			depth--
+		case token.Backtick:
+			p.skipTemplate()
+			continue
+		case token.Eof:
+			p.errorUnexpectedToken(token.Eof)
+			return
+		}
+		p.next()
+		if depth <= 0 {
+			return
+		}
+	}
+}
+
+// skipTemplate skips a template literal or template literal type.
+func (p *parser) skipTemplate() {
+	for {
+		_, _, finished, _, err := p.parseTemplateCharacters(true)
+		p.next()
+		if finished || err != "" {
+			return
+		}
+		for p.token != token.RightBrace && p.token != token.Eof {
+			switch p.token {
+			case token.LeftParenthesis, token.LeftBracket, token.LeftBrace:
+				p.skipBalanced()
+			case token.Backtick:
+				p.skipTemplate()
+			default:
+				p.next()
+			}
+		}
+		if p.token != token.RightBrace {
+			p.errorUnexpectedToken(p.token)
+			return
+		}
+	}
+}
+
+// removeUnusedImports removes the import specifiers whose bindings are never
+// referenced, as they may only import types, and imports that lose all of
+// their specifiers. Like the TypeScript compiler, it does not know which
+// bindings are types, so imports only used in export specifiers are kept.
+func removeUnusedImports(program *ast.Program) {
+	refs := &references{names: map[string]bool{}}
+	refs.V = refs
+	program.VisitWith(refs)
+
+	body := program.Body[:0]
+	for _, stmt := range program.Body {
+		if decl, ok := stmt.Stmt.(*ast.ImportDeclaration); ok && len(decl.Specifiers) > 0 {
+			specifiers := decl.Specifiers[:0]
+			for _, s := range decl.Specifiers {
+				if refs.names[importedBinding(s.Specifier).Name] {
+					specifiers = append(specifiers, s)
+				}
+			}
+			decl.Specifiers = specifiers
+			if len(specifiers) == 0 {
+				continue
+			}
+		}
+		body = append(body, stmt)
+	}
+	program.Body = body
+}
+
+func importedBinding(s ast.ImportSpec) *ast.Identifier {
+	switch s := s.(type) {
+	case *ast.ImportDefaultSpecifier:
+		return s.Local
+	case *ast.ImportNamespaceSpecifier:
+		return s.Local
+	case *ast.ImportNamedSpecifier:
+		return s.Local
+	}
+	return nil
+}
+
+// references collects the names of the identifiers outside of import
+// declarations, leaving out property names.
+type references struct {
+	ast.NoopVisitor
+
+	names map[string]bool
+}
+
+func (r *references) VisitIdentifier(n *ast.Identifier) {
+	r.names[n.Name] = true
+}
+
+func (r *references) VisitImportDeclaration(*ast.ImportDeclaration) {}
+
+func (r *references) VisitMemberProperty(n *ast.MemberProperty) {
+	if _, ok := n.Prop.(*ast.Identifier); !ok {
+		n.VisitChildrenWith(r)
+	}
+}
+
+func (r *references) VisitPropertyKeyed(n *ast.PropertyKeyed) {
+	if n.Computed {
+		n.Key.VisitWith(r)
+	}
+	n.Value.VisitWith(r)
+}
+
+// VisitJSXElement keeps React in scope for elements, which are lowered to
+// React.createElement calls by default.
+func (r *references) VisitJSXElement(n *ast.JSXElement) {
+	r.names["React"] = true
+	n.VisitChildrenWith(r)
+}
+
+func (r *references) VisitJSXFragment(n *ast.JSXFragment) {
+	r.names["React"] = true
+	n.VisitChildrenWith(r)
+}
+
+func (r *references) VisitJSXName(n *ast.JSXName) {
+	name := n.Name
+	if i := strings.IndexAny(name, ".:"); i >= 0 {
+		name = name[:i]
+	}
+	r.names[name] = true
+}
@@ -0,0 +1,207 @@
+// Package regexp parses ECMAScript regular expression patterns into a syntax
+// tree, reporting the early errors of the specification, and prints syntax
+// trees back into patterns.
+//
+// Without the u or v flag, patterns follow the grammar of Annex B that all
+// browsers implement, so that /{/, /]/ and /\1/ without groups are accepted
+// and the pattern is read in UTF-16 code units.
+package regexp
+
+// Span is the range of bytes a node covers in the pattern.
+type Span struct {
+	Start, End int
+}
+
+// Pos returns the span of the node.
+func (s Span) Pos() Span { return s }
+
+// Node is a node of a pattern.
+type Node interface {
+	Pos() Span
+}
+
+// Term is an element of an alternative.
+type Term interface {
+	Node
+	term()
+}
+
+// ClassElement is an element of a character class.
+type ClassElement interface {
+	Node
+	classElement()
+}
+
+type (
+	// Pattern is a parsed pattern.
+	Pattern struct {
+		Span
+		Body  *Disjunction
+		Flags Flags
+		// Groups is the number of capturing groups.
+		Groups int
+		// Names are the names of the named groups in order of appearance,
+		// each listed once.
+		Names []string
+	}
+
+	// Disjunction is a list of alternatives separated by |.
+	Disjunction struct {
+		Span
+		Alternatives []*Alternative
+	}
+
+	// Alternative is a sequence of terms.
+	Alternative struct {
+		Span
+		Terms []Term
+	}
+
+	// Character matches a single character, or a single UTF-16 code unit
+	// without the u or v flag.
+	Character struct {
+		Span
+		Value rune
+	}
+
+	// Dot is the . wildcard.
+	Dot struct {
+		Span
+	}
+
+	// Assertion is ^, $, \b or \B.
+	Assertion struct {
+		Span
+		Kind AssertionKind
+	}
+
+	// LookAround is a lookahead or lookbehind, as in (?=a) or (?<!a).
+	LookAround struct {
+		Span
+		Kind LookAroundKind
+		Body *Disjunction
+	}
+
+	// Group is a capturing or non-capturing group, as in (a), (?<name>a),
+	// (?:a) or (?i-m:a).
+	Group struct {
+		Span
+		Capturing bool
+		// Index is the number of a capturing group, counted from 1.
+		Index int
+		Name  string
+		// Enable and Disable are the flags of a modified group like (?i-m:a).
+		Enable, Disable string
+		Body            *Disjunction
+	}
+
+	// Backreference is \1 or \k<name>.
+	Backreference struct {
+		Span
+		Index int
+		Name  string
+	}
+
+	// Quantifier repeats its body between Min and Max times, with Max
+	// Unbounded for *, + and {n,}.
+	Quantifier struct {
+		Span
+		Min, Max int
+		Greedy   bool
+		Body     Term
+	}
+
+	// CharacterClassEscape is one of \d, \D, \s, \S, \w and \W.
+	CharacterClassEscape struct {
+		Span
+		Kind rune
+	}
+
+	// PropertyEscape is \p{Name=Value} or \p{Value}, or \P with Negated set.
+	PropertyEscape struct {
+		Span
+		Negated bool
+		Name    string
+		Value   string
+		// Strings is set for the properties of strings of the v flag, like
+		// RGI_Emoji.
+		Strings bool
+	}
+
+	// CharacterClass is a class like [a-z]. With the v flag its elements can
+	// be combined by intersection or subtraction instead of union.
+	CharacterClass struct {
+		Span
+		Negated  bool
+		Kind     ClassKind
+		Elements []ClassElement
+	}
+
+	// ClassRange is a range like a-z in a class.
+	ClassRange struct {
+		Span
+		Min, Max *Character
+	}
+
+	// ClassStringDisjunction is \q{abc|d} in a class with the v flag.
+	ClassStringDisjunction struct {
+		Span
+		Strings []*ClassString
+	}
+
+	// ClassString is an alternative of a ClassStringDisjunction.
+	ClassString struct {
+		Span
+		Characters []*Character
+	}
+)
+
+// Unbounded is the Max of a quantifier without an upper bound.
+const Unbounded = -1
+
+// AssertionKind is the kind of an Assertion.
+type AssertionKind int
+
+const (
+	Start           AssertionKind = iota // ^
+	End                                  // $
+	WordBoundary                         // \b
+	NonWordBoundary                      // \B
+)
+
+// LookAroundKind is the kind of a LookAround.
+type LookAroundKind int
+
+const (
+	Lookahead          LookAroundKind = iota // (?=
+	NegativeLookahead                        // (?!
+	Lookbehind                               // (?<=
+	NegativeLookbehind                       // (?<!
+)
+
+// ClassKind is the operation a CharacterClass combines its elements with.
+type ClassKind int
+
+const (
+	Union        ClassKind = iota
+	Intersection           // &&
+	Subtraction            // --
+)
+
+func (*Character) term()            {}
+func (*Dot) term()                  {}
+func (*Assertion) term()            {}
+func (*LookAround) term()           {}
+func (*Group) term()                {}
+func (*Backreference) term()        {}
+func (*Quantifier) term()           {}
+func (*CharacterClassEscape) term() {}
+func (*PropertyEscape) term()       {}
+func (*CharacterClass) term()       {}
+
+func (*Character) classElement()              {}
+func (*ClassRange) classElement()             {}
+func (*CharacterClassEscape) classElement()   {}
+func (*PropertyEscape) classElement()         {}
+func (*CharacterClass) classElement()         {}
+func (*ClassStringDisjunction) classElement() {}
@@ -0,0 +1,79 @@
+package regexp
+
+import "strings"
+
+// Flags are the flags of a regular expression.
+type Flags struct {
+	HasIndices  bool // d
+	Global      bool // g
+	IgnoreCase  bool // i
+	Multiline   bool // m
+	DotAll      bool // s
+	Unicode     bool // u
+	UnicodeSets bool // v
+	Sticky      bool // y
+}
+
+// ParseFlags parses the flags of a regular expression literal. The offset of
+// an error is relative to the flags.
+func ParseFlags(flags string) (Flags, error) {
+	var f Flags
+	for i, chr := range flags {
+		var flag *bool
+		switch chr {
+		case 'd':
+			flag = &f.HasIndices
+		case 'g':
+			flag = &f.Global
+		case 'i':
+			flag = &f.IgnoreCase
+		case 'm':
+			flag = &f.Multiline
+		case 's':
+			flag = &f.DotAll
+		case 'u':
+			flag = &f.Unicode
+		case 'v':
+			flag = &f.UnicodeSets
+		case 'y':
+			flag = &f.Sticky
+		}
+		if flag == nil || *flag {
+			return f, &Error{Offset: i, Message: "Invalid regular expression flags"}
+		}
+		*flag = true
+	}
+	if f.Unicode && f.UnicodeSets {
+		return f, &Error{Offset: strings.IndexAny(flags, "uv"), Message: "Invalid regular expression flags"}
+	}
+	return f, nil
+}
+
+// String returns the flags in the order of the flags property of a RegExp.
+func (f Flags) String() string {
+	var b strings.Builder
+	for _, flag := range []struct {
+		set bool
+		chr byte
+	}{
+		{f.HasIndices, 'd'},
+		{f.Global, 'g'},
+		{f.IgnoreCase, 'i'},
+		{f.Multiline, 'm'},
+		{f.DotAll, 's'},
+		{f.Unicode, 'u'},
+		{f.UnicodeSets, 'v'},
+		{f.Sticky, 'y'},
+	} {
+		if flag.set {
+			b.WriteByte(flag.chr)
+		}
+	}
+	return b.String()
+}
+
+// unicodeMode reports whether the pattern is read in code points with the
+// strict grammar, which the u and v flags both enable.
+func (f Flags) unicodeMode() bool {
+	return f.Unicode || f.UnicodeSets
+}
@@ -0,0 +1,960 @@
+package regexp
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/nukilabs/unicodeid"
+
+	"github.com/t14raptor/go-fast/limit"
+)
+
+// Error is an early error in a pattern.
+type Error struct {
+	// Offset is the byte offset of the error in the pattern or flags.
+	Offset  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at offset %d", e.Message, e.Offset)
+}
+
+// Parse parses pattern, the source of a regular expression without its
+// slashes, and reports the first early error in it.
+func Parse(pattern string, flags Flags) (re *Pattern, err error) {
+	return ParseTracked(pattern, flags, &limit.Tracker{})
+}
+
+// ParseTracked is like Parse but counts the nesting of groups and classes as
+// recursion of the walk that t tracks, so that a pattern nested deeper than
+// its MaxDepth panics like the methods of t do. The panic is meant for the
+// limit.Catch of that walk.
+func ParseTracked(pattern string, flags Flags, t *limit.Tracker) (re *Pattern, err error) {
+	p := &parser{
+		src:     pattern,
+		unicode: flags.unicodeMode(),
+		sets:    flags.UnicodeSets,
+		limits:  t,
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*Error)
+			if !ok {
+				panic(r)
+			}
+			re, err = nil, e
+		}
+	}()
+
+	var named bool
+	p.groups, named = p.scanGroups()
+	p.named = named || p.unicode
+	body := p.parseDisjunction()
+	if !p.eof() {
+		p.fail(p.pos, "Unmatched ')'")
+	}
+	for _, ref := range p.refs {
+		if !slices.Contains(p.names, ref.Name) {
+			p.fail(ref.Start, "Invalid named capture referenced")
+		}
+	}
+	return &Pattern{
+		Span:   Span{0, len(pattern)},
+		Body:   body,
+		Flags:  flags,
+		Groups: p.index,
+		Names:  p.names,
+	}, nil
+}
+
+type parser struct {
+	src string
+	pos int
+
+	// low is the trail surrogate of a character outside the Basic
+	// Multilingual Plane whose lead surrogate was read without the u or v
+	// flag. The character starts at lowStart.
+	low      rune
+	lowStart int
+
+	unicode bool // u or v flag
+	sets    bool // v flag
+	// named is set when \k is a reference to a named group, which it is
+	// with the u or v flag or when the pattern has named groups.
+	named bool
+
+	groups int // capturing groups in the pattern
+	index  int // capturing groups seen so far
+	names  []string
+	// active are the names of the groups that can participate in a match
+	// together with a group at the current position.
+	active []string
+	refs   []*Backreference
+
+	limits *limit.Tracker
+}
+
+func (p *parser) fail(offset int, msg string) {
+	panic(&Error{Offset: offset, Message: msg})
+}
+
+func (p *parser) eof() bool {
+	return p.low == 0 && p.pos >= len(p.src)
+}
+
+// cur returns the character at the current position, or -1 at the end.
+func (p *parser) cur() rune {
+	if p.low != 0 {
+		return p.low
+	}
+	if p.pos >= len(p.src) {
+		return -1
+	}
+	if c := p.src[p.pos]; c < utf8.RuneSelf {
+		return rune(c)
+	}
+	r, _ := utf8.DecodeRuneInString(p.src[p.pos:])
+	if !p.unicode && r > 0xFFFF {
+		r, _ = utf16.EncodeRune(r)
+	}
+	return r
+}
+
+// start returns the offset of the current character.
+func (p *parser) start() int {
+	if p.low != 0 {
+		return p.lowStart
+	}
+	return p.pos
+}
+
+// next consumes the current character and returns it, or -1 at the end.
+func (p *parser) next() rune {
+	if p.low != 0 {
+		r := p.low
+		p.low = 0
+		return r
+	}
+	if p.pos >= len(p.src) {
+		return -1
+	}
+	r, w := utf8.DecodeRuneInString(p.src[p.pos:])
+	if !p.unicode && r > 0xFFFF {
+		r, p.low = utf16.EncodeRune(r)
+		p.lowStart = p.pos
+	}
+	p.pos += w
+	return r
+}
+
+func (p *parser) eat(chr rune) bool {
+	if p.cur() == chr {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) lookingAt(s string) bool {
+	return p.low == 0 && strings.HasPrefix(p.src[p.pos:], s)
+}
+
+// scanGroups counts the capturing groups of the pattern and reports whether
+// any of them is named, which decides what \1 and \k mean before the parser
+// reaches the groups.
+func (p *parser) scanGroups() (count int, named bool) {
+	depth := 0
+	for i := 0; i < len(p.src); i++ {
+		switch p.src[i] {
+		case '\\':
+			i++
+		case '[':
+			if depth == 0 || p.sets {
+				depth++
+			}
+		case ']':
+			if depth > 0 {
+				depth--
+			}
+		case '(':
+			rest := p.src[i+1:]
+			switch {
+			case depth > 0:
+			case !strings.HasPrefix(rest, "?"):
+				count++
+			case strings.HasPrefix(rest, "?<") && !strings.HasPrefix(rest, "?<=") && !strings.HasPrefix(rest, "?<!"):
+				count++
+				named = true
+			}
+		}
+	}
+	return count, named
+}
+
+func (p *parser) parseDisjunction() *Disjunction {
+	p.limits.Enter()
+	defer p.limits.Leave()
+	d := &Disjunction{Span: Span{Start: p.start()}}
+	outer := len(p.active)
+	var names []string
+	for {
+		d.Alternatives = append(d.Alternatives, p.parseAlternative())
+		// Groups in different alternatives never participate in the same
+		// match, so they may share a name.
+		names = append(names, p.active[outer:]...)
+		p.active = p.active[:outer]
+		if !p.eat('|') {
+			break
+		}
+	}
+	p.active = append(p.active, names...)
+	d.End = p.pos
+	return d
+}
+
+func (p *parser) parseAlternative() *Alternative {
+	alt := &Alternative{Span: Span{Start: p.start()}}
+	for chr := p.cur(); chr != -1 && chr != '|' && chr != ')'; chr = p.cur() {
+		alt.Terms = append(alt.Terms, p.parseTerm())
+	}
+	alt.End = p.pos
+	return alt
+}
+
+func (p *parser) parseTerm() Term {
+	start := p.start()
+	switch p.cur() {
+	case '^', '$':
+		kind := Start
+		if p.next() == '$' {
+			kind = End
+		}
+		return p.noQuantifier(&Assertion{Span: Span{start, p.pos}, Kind: kind})
+	case '(':
+		switch {
+		case p.lookingAt("(?<="), p.lookingAt("(?<!"):
+			return p.noQuantifier(p.parseLookAround())
+		case p.lookingAt("(?="), p.lookingAt("(?!"):
+			look := p.parseLookAround()
+			if p.unicode {
+				return p.noQuantifier(look)
+			}
+			// Annex B allows quantified lookaheads.
+			return p.parseQuantifier(look)
+		}
+		return p.parseQuantifier(p.parseGroup())
+	case '\\':
+		if p.lookingAt(`\b`) || p.lookingAt(`\B`) {
+			kind := WordBoundary
+			if p.src[p.pos+1] == 'B' {
+				kind = NonWordBoundary
+			}
+			p.pos += 2
+			return p.noQuantifier(&Assertion{Span: Span{start, p.pos}, Kind: kind})
+		}
+		return p.parseQuantifier(p.parseAtomEscape())
+	case '[':
+		return p.parseQuantifier(p.parseClass())
+	case '.':
+		p.next()
+		return p.parseQuantifier(&Dot{Span: Span{start, p.pos}})
+	case '*', '+', '?':
+		p.fail(start, "Nothing to repeat")
+	case '{':
+		if _, _, _, ok := p.braced(); ok {
+			p.fail(start, "Nothing to repeat")
+		}
+		if p.unicode {
+			p.fail(start, "Lone quantifier brackets")
+		}
+	case '}', ']':
+		if p.unicode {
+			p.fail(start, "Lone quantifier brackets")
+		}
+	}
+	chr := p.next()
+	return p.parseQuantifier(&Character{Span: Span{start, p.pos}, Value: chr})
+}
+
+// noQuantifier returns term, failing if a quantifier follows it.
+func (p *parser) noQuantifier(term Term) Term {
+	switch p.cur() {
+	case '*', '+', '?':
+		p.fail(p.pos, "Nothing to repeat")
+	case '{':
+		if _, _, _, ok := p.braced(); ok {
+			p.fail(p.pos, "Nothing to repeat")
+		}
+	}
+	return term
+}
+
+// parseQuantifier parses the quantifier after atom, if any.
+func (p *parser) parseQuantifier(atom Term) Term {
+	var lo, hi int
+	switch p.cur() {
+	case '*':
+		lo, hi = 0, Unbounded
+		p.next()
+	case '+':
+		lo, hi = 1, Unbounded
+		p.next()
+	case '?':
+		lo, hi = 0, 1
+		p.next()
+	case '{':
+		var n int
+		var ok bool
+		if lo, hi, n, ok = p.braced(); !ok {
+			return atom
+		}
+		if hi != Unbounded && lo > hi {
+			p.fail(p.pos, "numbers out of order in {} quantifier")
+		}
+		p.pos += n
+	default:
+		return atom
+	}
+	greedy := !p.eat('?')
+	return &Quantifier{Span: Span{atom.Pos().Start, p.pos}, Min: lo, Max: hi, Greedy: greedy, Body: atom}
+}
+
+// braced parses the quantifier in braces at the current position without
+// consuming it, returning its bounds and length.
+func (p *parser) braced() (lo, hi, n int, ok bool) {
+	s := p.src[p.pos:]
+	lo, i, ok := digits(s, 1)
+	if !ok {
+		return 0, 0, 0, false
+	}
+	hi = lo
+	if i < len(s) && s[i] == ',' {
+		hi = Unbounded
+		if m, j, ok := digits(s, i+1); ok {
+			hi, i = m, j
+		} else {
+			i++
+		}
+	}
+	if i >= len(s) || s[i] != '}' {
+		return 0, 0, 0, false
+	}
+	return lo, hi, i + 1, true
+}
+
+// digits parses the decimal number at s[i:], saturating at math.MaxInt32
+// like the engines do.
+func digits(s string, i int) (n, end int, ok bool) {
+	for end = i; end < len(s) && isDigit(rune(s[end])); end++ {
+		n = min(n*10+int(s[end]-'0'), math.MaxInt32)
+	}
+	return n, end, end > i
+}
+
+func (p *parser) parseLookAround() *LookAround {
+	start := p.pos
+	look := &LookAround{}
+	switch {
+	case p.lookingAt("(?="):
+		look.Kind = Lookahead
+	case p.lookingAt("(?!"):
+		look.Kind = NegativeLookahead
+	case p.lookingAt("(?<="):
+		look.Kind = Lookbehind
+	default:
+		look.Kind = NegativeLookbehind
+	}
+	p.pos += 3
+	if look.Kind >= Lookbehind {
+		p.pos++
+	}
+	look.Body = p.parseDisjunction()
+	p.expectClose(start)
+	look.Span = Span{start, p.pos}
+	return look
+}
+
+func (p *parser) parseGroup() *Group {
+	start := p.pos
+	p.pos++
+	g := &Group{}
+	switch {
+	case p.lookingAt("?:"):
+		p.pos += 2
+	case p.lookingAt("?<"):
+		p.pos += 2
+		g.Capturing = true
+		g.Name = p.parseGroupName()
+		p.defineName(g.Name, start)
+	case p.lookingAt("?"):
+		p.pos++
+		g.Enable, g.Disable = p.parseModifiers(start)
+	default:
+		g.Capturing = true
+	}
+	if g.Capturing {
+		p.index++
+		g.Index = p.index
+	}
+	g.Body = p.parseDisjunction()
+	p.expectClose(start)
+	g.Span = Span{start, p.pos}
+	return g
+}
+
+func (p *parser) expectClose(start int) {
+	if !p.eat(')') {
+		p.fail(start, "Unterminated group")
+	}
+}
+
+// parseModifiers parses the flags of a modified group like (?i-m:a) up to
+// and including the colon.
+func (p *parser) parseModifiers(start int) (enable, disable string) {
+	flags := func(i int) int {
+		for i < len(p.src) && strings.IndexByte("ims", p.src[i]) >= 0 {
+			i++
+		}
+		return i
+	}
+	i := flags(p.pos)
+	enable = p.src[p.pos:i]
+	dash := i < len(p.src) && p.src[i] == '-'
+	if dash {
+		j := flags(i + 1)
+		disable = p.src[i+1 : j]
+		i = j
+	}
+	if i >= len(p.src) || p.src[i] != ':' || dash && enable == "" && disable == "" {
+		p.fail(start, "Invalid group")
+	}
+	all := enable + disable
+	for j := range all {
+		if strings.IndexByte(all[j+1:], all[j]) >= 0 {
+			p.fail(start, "Repeated flag in group modifiers")
+		}
+	}
+	p.pos = i + 1
+	return enable, disable
+}
+
+// parseGroupName parses a group name after the opening < up to and
+// including the closing >.
+func (p *parser) parseGroupName() string {
+	start := p.pos
+	var b strings.Builder
+	for {
+		if p.pos >= len(p.src) {
+			p.fail(start, "Invalid capture group name")
+		}
+		if p.src[p.pos] == '>' && b.Len() > 0 {
+			p.pos++
+			return b.String()
+		}
+		var chr rune
+		if p.lookingAt(`\u`) {
+			p.pos += 2
+			chr = p.parseUnicodeEscape(true)
+		} else {
+			var w int
+			chr, w = utf8.DecodeRuneInString(p.src[p.pos:])
+			p.pos += w
+		}
+		if b.Len() == 0 && !isIDStart(chr) || !isIDPart(chr) {
+			p.fail(start, "Invalid capture group name")
+		}
+		b.WriteRune(chr)
+	}
+}
+
+func (p *parser) defineName(name string, offset int) {
+	if slices.Contains(p.active, name) {
+		p.fail(offset, "Duplicate capture group name")
+	}
+	p.active = append(p.active, name)
+	if !slices.Contains(p.names, name) {
+		p.names = append(p.names, name)
+	}
+}
+
+func (p *parser) parseAtomEscape() Term {
+	start := p.pos
+	p.pos++
+	switch chr := p.cur(); {
+	case isClassEscape(chr):
+		p.next()
+		return &CharacterClassEscape{Span: Span{start, p.pos}, Kind: chr}
+	case (chr == 'p' || chr == 'P') && p.unicode:
+		return p.parsePropertyEscape(start)
+	case chr == 'k' && p.named:
+		p.next()
+		if !p.eat('<') {
+			p.fail(start, "Invalid named reference")
+		}
+		ref := &Backreference{Name: p.parseGroupName()}
+		ref.Span = Span{start, p.pos}
+		p.refs = append(p.refs, ref)
+		return ref
+	case '1' <= chr && chr <= '9':
+		n, end, _ := digits(p.src, p.pos)
+		if n <= p.groups {
+			p.pos = end
+			return &Backreference{Span: Span{start, p.pos}, Index: n}
+		}
+		if p.unicode {
+			p.fail(start, "Invalid escape")
+		}
+		// Annex B reads escapes of missing groups as octal or identity
+		// escapes.
+	}
+	return p.parseCharacterEscape(start, false)
+}
+
+// parseCharacterEscape parses the rest of an escape that stands for a single
+// character, which started at start. With class set the escape is inside a
+// character class.
+func (p *parser) parseCharacterEscape(start int, class bool) *Character {
+	chr := p.next()
+	value := chr
+	switch chr {
+	case -1:
+		p.fail(start, `\ at end of pattern`)
+	case 'f':
+		value = '\f'
+	case 'n':
+		value = '\n'
+	case 'r':
+		value = '\r'
+	case 't':
+		value = '\t'
+	case 'v':
+		value = '\v'
+	case 'c':
+		if r := p.cur(); isASCIILetter(r) || !p.unicode && class && (isDigit(r) || r == '_') {
+			p.next()
+			value = r % 32
+		} else if p.unicode {
+			p.fail(start, "Invalid unicode escape")
+		} else {
+			// Annex B reads \ without a control letter as a backslash
+			// followed by c.
+			p.pos = start + 1
+			value = '\\'
+		}
+	case 'x':
+		if v, ok := hex(p.src[p.pos:], 2); ok {
+			p.pos += 2
+			value = v
+		} else if p.unicode {
+			p.fail(start, "Invalid escape")
+		}
+	case 'u':
+		if v := p.parseUnicodeEscape(p.unicode); v >= 0 {
+			value = v
+		} else if p.unicode {
+			p.fail(start, "Invalid Unicode escape")
+		}
+	case '0':
+		if !isDigit(p.cur()) {
+			value = 0
+		} else if p.unicode {
+			p.fail(start, "Invalid decimal escape")
+		} else {
+			value = p.parseLegacyOctal(chr)
+		}
+	case '1', '2', '3', '4', '5', '6', '7':
+		if p.unicode {
+			p.fail(start, "Invalid class escape")
+		}
+		value = p.parseLegacyOctal(chr)
+	default:
+		if p.unicode {
+			if !isSyntaxCharacter(chr) && chr != '/' && !(class && chr == '-') {
+				p.fail(start, "Invalid escape")
+			}
+		} else if chr == 'k' && p.named {
+			p.fail(start, "Invalid escape")
+		}
+	}
+	return &Character{Span: Span{start, p.pos}, Value: value}
+}
+
+// parseLegacyOctal parses the rest of an octal escape like \012 of Annex B,
+// whose first digit was already read.
+func (p *parser) parseLegacyOctal(first rune) rune {
+	value := first - '0'
+	more := 1
+	if first <= '3' {
+		more = 2
+	}
+	for ; more > 0 && '0' <= p.cur() && p.cur() <= '7'; more-- {
+		value = value*8 + p.next() - '0'
+	}
+	return value
+}
+
+// parseUnicodeEscape parses the rest of a \u escape, returning -1 if it is
+// not valid. With unicode set it also accepts \u{...} and joins escaped
+// surrogate pairs.
+func (p *parser) parseUnicodeEscape(unicode bool) rune {
+	if unicode && p.lookingAt("{") {
+		end := strings.IndexByte(p.src[p.pos:], '}')
+		if end < 0 {
+			return -1
+		}
+		v, ok := hex(p.src[p.pos+1:p.pos+end], end-1)
+		if !ok || end == 1 {
+			return -1
+		}
+		p.pos += end + 1
+		return v
+	}
+	v, ok := hex(p.src[p.pos:], 4)
+	if !ok {
+		return -1
+	}
+	p.pos += 4
+	if unicode && 0xD800 <= v && v <= 0xDBFF && p.lookingAt(`\u`) {
+		if lo, ok := hex(p.src[p.pos+2:], 4); ok && 0xDC00 <= lo && lo <= 0xDFFF {
+			p.pos += 6
+			return utf16.DecodeRune(v, lo)
+		}
+	}
+	return v
+}
+
+// hex parses the n hexadecimal digits at the start of s, failing for code
+// points beyond U+10FFFF.
+func hex(s string, n int) (rune, bool) {
+	if len(s) < n {
+		return 0, false
+	}
+	var v rune
+	for i := 0; i < n; i++ {
+		d := hexValue(s[i])
+		if d < 0 {
+			return 0, false
+		}
+		if v = v*16 + d; v > unicode.MaxRune {
+			return 0, false
+		}
+	}
+	return v, true
+}
+
+func hexValue(c byte) rune {
+	switch {
+	case '0' <= c && c <= '9':
+		return rune(c - '0')
+	case 'a' <= c && c <= 'f':
+		return rune(c - 'a' + 10)
+	case 'A' <= c && c <= 'F':
+		return rune(c - 'A' + 10)
+	}
+	return -1
+}
+
+func (p *parser) parsePropertyEscape(start int) *PropertyEscape {
+	negated := p.next() == 'P'
+	end := strings.IndexByte(p.src[p.pos:], '}')
+	if !p.eat('{') || end < 0 {
+		p.fail(start, "Invalid property name")
+	}
+	name, value, found := strings.Cut(p.src[p.pos:p.pos+end-1], "=")
+	if !found {
+		name, value = "", name
+	}
+	ok, ofStrings := validProperty(name, value, p.sets)
+	if !ok || ofStrings && negated || !isPropertyName(value) || found && !isPropertyName(name) {
+		p.fail(start, "Invalid property name")
+	}
+	p.pos += end
+	return &PropertyEscape{Span: Span{start, p.pos}, Negated: negated, Name: name, Value: value, Strings: ofStrings}
+}
+
+func isPropertyName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := rune(s[i]); !isASCIILetter(c) && !isDigit(c) && c != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *parser) parseClass() *CharacterClass {
+	start := p.pos
+	p.pos++
+	if p.sets {
+		return p.parseClassSet(start)
+	}
+	class := &CharacterClass{Negated: p.eat('^')}
+	for !p.eat(']') {
+		if p.eof() {
+			p.fail(start, "Unterminated character class")
+		}
+		atom := p.parseClassAtom()
+		if !p.lookingAt("-") || p.lookingAt("-]") {
+			class.Elements = append(class.Elements, atom)
+			continue
+		}
+		dash := p.pos
+		p.pos++
+		if p.eof() {
+			p.fail(start, "Unterminated character class")
+		}
+		last := p.parseClassAtom()
+		lo, ok1 := atom.(*Character)
+		hi, ok2 := last.(*Character)
+		if !ok1 || !ok2 {
+			if p.unicode {
+				p.fail(atom.Pos().Start, "Invalid character class")
+			}
+			// Annex B reads a range with a class escape as a union with a dash.
+			class.Elements = append(class.Elements, atom, &Character{Span: Span{dash, dash + 1}, Value: '-'}, last)
+			continue
+		}
+		if lo.Value > hi.Value {
+			p.fail(lo.Start, "Range out of order in character class")
+		}
+		class.Elements = append(class.Elements, &ClassRange{Span: Span{lo.Start, hi.End}, Min: lo, Max: hi})
+	}
+	class.Span = Span{start, p.pos}
+	return class
+}
+
+func (p *parser) parseClassAtom() ClassElement {
+	start := p.start()
+	if !p.lookingAt(`\`) {
+		chr := p.next()
+		return &Character{Span: Span{start, p.pos}, Value: chr}
+	}
+	p.pos++
+	switch chr := p.cur(); {
+	case isClassEscape(chr):
+		p.next()
+		return &CharacterClassEscape{Span: Span{start, p.pos}, Kind: chr}
+	case (chr == 'p' || chr == 'P') && p.unicode:
+		return p.parsePropertyEscape(start)
+	case chr == 'b':
+		p.next()
+		return &Character{Span: Span{start, p.pos}, Value: '\b'}
+	}
+	return p.parseCharacterEscape(start, true)
+}
+
+// parseClassSet parses a character class with the v flag after its opening
+// bracket at start.
+func (p *parser) parseClassSet(start int) *CharacterClass {
+	p.limits.Enter()
+	defer p.limits.Leave()
+	class := &CharacterClass{Negated: p.eat('^')}
+	if !p.eat(']') {
+		first := p.parseClassSetOperand(true)
+		class.Elements = append(class.Elements, first)
+		if p.lookingAt("&&") || p.lookingAt("--") {
+			op := p.src[p.pos : p.pos+2]
+			class.Kind = Intersection
+			if op == "--" {
+				class.Kind = Subtraction
+			}
+			if _, ok := first.(*ClassRange); ok {
+				p.fail(first.Pos().Start, "Invalid set operation in character class")
+			}
+			for p.lookingAt(op) {
+				p.pos += 2
+				if op == "&&" && p.lookingAt("&") {
+					p.fail(p.pos, "Invalid character in character class")
+				}
+				class.Elements = append(class.Elements, p.parseClassSetOperand(false))
+			}
+			if !p.eat(']') {
+				if p.eof() {
+					p.fail(start, "Unterminated character class")
+				}
+				p.fail(p.pos, "Invalid set operation in character class")
+			}
+		} else {
+			for !p.eat(']') {
+				if p.eof() {
+					p.fail(start, "Unterminated character class")
+				}
+				if p.lookingAt("&&") || p.lookingAt("--") {
+					p.fail(p.pos, "Invalid set operation in character class")
+				}
+				class.Elements = append(class.Elements, p.parseClassSetOperand(true))
+			}
+		}
+	}
+	class.Span = Span{start, p.pos}
+	if class.Negated && classMayContainStrings(class) {
+		p.fail(start, "Negated character class may contain strings")
+	}
+	return class
+}
+
+// parseClassSetOperand parses an operand of a class with the v flag, which
+// with allowRange set may also be a range.
+func (p *parser) parseClassSetOperand(allowRange bool) ClassElement {
+	start := p.pos
+	switch {
+	case p.lookingAt("["):
+		p.pos++
+		return p.parseClassSet(start)
+	case p.lookingAt(`\q{`):
+		return p.parseClassStrings()
+	case p.lookingAt(`\`) && p.pos+1 < len(p.src):
+		switch chr := rune(p.src[p.pos+1]); {
+		case isClassEscape(chr):
+			p.pos += 2
+			return &CharacterClassEscape{Span: Span{start, p.pos}, Kind: chr}
+		case chr == 'p' || chr == 'P':
+			p.pos++
+			return p.parsePropertyEscape(start)
+		}
+	}
+	lo := p.parseClassSetCharacter()
+	if !allowRange || !p.lookingAt("-") || p.lookingAt("--") {
+		return lo
+	}
+	p.pos++
+	hi := p.parseClassSetCharacter()
+	if lo.Value > hi.Value {
+		p.fail(lo.Start, "Range out of order in character class")
+	}
+	return &ClassRange{Span: Span{lo.Start, hi.End}, Min: lo, Max: hi}
+}
+
+const (
+	classSetSyntaxCharacters        = "()[]{}/-\\|"
+	classSetReservedPunctuators     = "&-!#%,:;<=>@`~"
+	classSetReservedDoublePunctuate = "&!#$%*+,.:;<=>?@^`~"
+)
+
+func (p *parser) parseClassSetCharacter() *Character {
+	start := p.pos
+	if p.eof() {
+		p.fail(start, "Unterminated character class")
+	}
+	if p.lookingAt(`\`) {
+		p.pos++
+		chr := p.cur()
+		switch {
+		case chr == 'b':
+			p.next()
+			return &Character{Span: Span{start, p.pos}, Value: '\b'}
+		case chr != -1 && strings.ContainsRune(classSetReservedPunctuators, chr):
+			p.next()
+			return &Character{Span: Span{start, p.pos}, Value: chr}
+		}
+		return p.parseCharacterEscape(start, true)
+	}
+	chr := p.cur()
+	if strings.ContainsRune(classSetSyntaxCharacters, chr) ||
+		strings.ContainsRune(classSetReservedDoublePunctuate, chr) && p.pos+1 < len(p.src) && rune(p.src[p.pos+1]) == chr {
+		p.fail(start, "Invalid character in character class")
+	}
+	p.next()
+	return &Character{Span: Span{start, p.pos}, Value: chr}
+}
+
+// parseClassStrings parses a \q{...} disjunction of strings.
+func (p *parser) parseClassStrings() *ClassStringDisjunction {
+	start := p.pos
+	p.pos += 3
+	d := &ClassStringDisjunction{}
+	for {
+		s := &ClassString{Span: Span{Start: p.pos}}
+		for !p.lookingAt("|") && !p.lookingAt("}") {
+			s.Characters = append(s.Characters, p.parseClassSetCharacter())
+		}
+		s.End = p.pos
+		d.Strings = append(d.Strings, s)
+		if !p.eat('|') {
+			break
+		}
+	}
+	p.pos++
+	d.Span = Span{start, p.pos}
+	return d
+}
+
+// mayContainStrings reports whether a class element can match a string that
+// is not a single character, which negated classes may not.
+func mayContainStrings(e ClassElement) bool {
+	switch e := e.(type) {
+	case *PropertyEscape:
+		return e.Strings
+	case *ClassStringDisjunction:
+		for _, s := range e.Strings {
+			if len(s.Characters) != 1 {
+				return true
+			}
+		}
+	case *CharacterClass:
+		return !e.Negated && classMayContainStrings(e)
+	}
+	return false
+}
+
+// classMayContainStrings is mayContainStrings for the elements of a class,
+// ignoring whether it is negated.
+func classMayContainStrings(c *CharacterClass) bool {
+	if len(c.Elements) == 0 {
+		return false
+	}
+	switch c.Kind {
+	case Intersection:
+		for _, elem := range c.Elements {
+			if !mayContainStrings(elem) {
+				return false
+			}
+		}
+		return true
+	case Subtraction:
+		return mayContainStrings(c.Elements[0])
+	}
+	return slices.ContainsFunc(c.Elements, mayContainStrings)
+}
+
+func isDigit(chr rune) bool {
+	return '0' <= chr && chr <= '9'
+}
+
+func isASCIILetter(chr rune) bool {
+	return 'a' <= chr && chr <= 'z' || 'A' <= chr && chr <= 'Z'
+}
+
+func isClassEscape(chr rune) bool {
+	switch chr {
+	case 'd', 'D', 's', 'S', 'w', 'W':
+		return true
+	}
+	return false
+}
+
+func isSyntaxCharacter(chr rune) bool {
+	return chr != -1 && strings.ContainsRune(`^$\.*+?()[]{}|`, chr)
+}
+
+func isIDStart(chr rune) bool {
+	if chr < utf8.RuneSelf {
+		return isASCIILetter(chr) || chr == '$' || chr == '_'
+	}
+	return unicodeid.IsIDStartUnicode(chr)
+}
+
+func isIDPart(chr rune) bool {
+	if chr < utf8.RuneSelf {
+		return isIDStart(chr) || isDigit(chr)
+	}
+	return chr == '\u200C' || chr == '\u200D' || unicodeid.IsIDContinueUnicode(chr)
+}
@@ -0,0 +1,265 @@
+package regexp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// String returns the source of the pattern. Characters are printed in their
+// shortest form that means the same, so that /\x61/ and /a/ print alike. The
+// empty pattern is printed as (?:) like the source property of a RegExp.
+func (p *Pattern) String() string {
+	pr := &printer{unicode: p.Flags.unicodeMode(), sets: p.Flags.UnicodeSets}
+	pr.disjunction(p.Body)
+	if pr.out.Len() == 0 {
+		return "(?:)"
+	}
+	return pr.out.String()
+}
+
+// Literal returns the pattern as a regular expression literal with its flags.
+func (p *Pattern) Literal() string {
+	return "/" + p.String() + "/" + p.Flags.String()
+}
+
+type printer struct {
+	out strings.Builder
+
+	unicode bool // u or v flag
+	sets    bool // v flag
+}
+
+const (
+	// syntaxCharacters are escaped outside of classes.
+	syntaxCharacters = `^$\.*+?()[]{}|/`
+	// classCharacters are escaped in classes without the v flag.
+	classCharacters = `\]^-[/`
+	// setCharacters are escaped in classes with the v flag.
+	setCharacters = syntaxCharacters + "-&!#%,:;<=>@`~"
+)
+
+func (p *printer) disjunction(d *Disjunction) {
+	for i, alt := range d.Alternatives {
+		if i > 0 {
+			p.out.WriteByte('|')
+		}
+		p.alternative(alt)
+	}
+}
+
+func (p *printer) alternative(alt *Alternative) {
+	for i := 0; i < len(alt.Terms); i++ {
+		if r, ok := pairAt(p, alt.Terms, i); ok {
+			p.char(r, syntaxCharacters)
+			i++
+			continue
+		}
+		if chr, ok := alt.Terms[i].(*Character); ok && i > 0 && isDigit(chr.Value) {
+			if ref, ok := alt.Terms[i-1].(*Backreference); ok && ref.Name == "" {
+				// A digit would extend the number of the backreference.
+				fmt.Fprintf(&p.out, `\x%02X`, chr.Value)
+				continue
+			}
+		}
+		p.term(alt.Terms[i])
+	}
+}
+
+// pairAt joins the surrogate pair of characters at index i of list, which
+// is how characters outside the Basic Multilingual Plane are read without
+// the u or v flag.
+func pairAt[T Node](p *printer, list []T, i int) (rune, bool) {
+	if p.unicode || i+1 >= len(list) {
+		return 0, false
+	}
+	hi, ok1 := any(list[i]).(*Character)
+	lo, ok2 := any(list[i+1]).(*Character)
+	if !ok1 || !ok2 || !utf16.IsSurrogate(hi.Value) || hi.Value > 0xDBFF || lo.Value < 0xDC00 || lo.Value > 0xDFFF {
+		return 0, false
+	}
+	return utf16.DecodeRune(hi.Value, lo.Value), true
+}
+
+func (p *printer) term(t Term) {
+	switch t := t.(type) {
+	case *Character:
+		p.char(t.Value, syntaxCharacters)
+	case *Dot:
+		p.out.WriteByte('.')
+	case *Assertion:
+		p.out.WriteString([...]string{"^", "$", `\b`, `\B`}[t.Kind])
+	case *LookAround:
+		p.out.WriteString([...]string{"(?=", "(?!", "(?<=", "(?<!"}[t.Kind])
+		p.disjunction(t.Body)
+		p.out.WriteByte(')')
+	case *Group:
+		switch {
+		case t.Name != "":
+			p.out.WriteString("(?<" + t.Name + ">")
+		case t.Capturing:
+			p.out.WriteByte('(')
+		case t.Enable != "" || t.Disable != "":
+			p.out.WriteString("(?" + t.Enable)
+			if t.Disable != "" {
+				p.out.WriteString("-" + t.Disable)
+			}
+			p.out.WriteByte(':')
+		default:
+			p.out.WriteString("(?:")
+		}
+		p.disjunction(t.Body)
+		p.out.WriteByte(')')
+	case *Backreference:
+		if t.Name != "" {
+			p.out.WriteString(`\k<` + t.Name + ">")
+		} else {
+			p.out.WriteString(`\` + strconv.Itoa(t.Index))
+		}
+	case *Quantifier:
+		p.quantifier(t)
+	case *CharacterClassEscape:
+		p.out.WriteString(`\` + string(t.Kind))
+	case *PropertyEscape:
+		p.property(t)
+	case *CharacterClass:
+		p.class(t)
+	}
+}
+
+func (p *printer) quantifier(q *Quantifier) {
+	chr, isChar := q.Body.(*Character)
+	_, isQuantifier := q.Body.(*Quantifier)
+	// Without the u or v flag a character outside the Basic Multilingual
+	// Plane is two code units, of which a quantifier repeats the last.
+	if isQuantifier || isChar && !p.unicode && chr.Value > 0xFFFF {
+		p.out.WriteString("(?:")
+		p.term(q.Body)
+		p.out.WriteByte(')')
+	} else {
+		p.term(q.Body)
+	}
+	switch {
+	case q.Min == 0 && q.Max == Unbounded:
+		p.out.WriteByte('*')
+	case q.Min == 1 && q.Max == Unbounded:
+		p.out.WriteByte('+')
+	case q.Min == 0 && q.Max == 1:
+		p.out.WriteByte('?')
+	case q.Min == q.Max:
+		fmt.Fprintf(&p.out, "{%d}", q.Min)
+	case q.Max == Unbounded:
+		fmt.Fprintf(&p.out, "{%d,}", q.Min)
+	default:
+		fmt.Fprintf(&p.out, "{%d,%d}", q.Min, q.Max)
+	}
+	if !q.Greedy {
+		p.out.WriteByte('?')
+	}
+}
+
+func (p *printer) property(e *PropertyEscape) {
+	if e.Negated {
+		p.out.WriteString(`\P{`)
+	} else {
+		p.out.WriteString(`\p{`)
+	}
+	if e.Name != "" {
+		p.out.WriteString(e.Name + "=")
+	}
+	p.out.WriteString(e.Value + "}")
+}
+
+func (p *printer) class(c *CharacterClass) {
+	p.out.WriteByte('[')
+	if c.Negated {
+		p.out.WriteByte('^')
+	}
+	for i := 0; i < len(c.Elements); i++ {
+		if i > 0 && c.Kind == Intersection {
+			p.out.WriteString("&&")
+		} else if i > 0 && c.Kind == Subtraction {
+			p.out.WriteString("--")
+		}
+		if r, ok := pairAt(p, c.Elements, i); ok && c.Kind == Union {
+			p.char(r, classCharacters)
+			i++
+			continue
+		}
+		p.classElement(c.Elements[i])
+	}
+	p.out.WriteByte(']')
+}
+
+func (p *printer) classElement(e ClassElement) {
+	escaped := classCharacters
+	if p.sets {
+		escaped = setCharacters
+	}
+	switch e := e.(type) {
+	case *Character:
+		p.char(e.Value, escaped)
+	case *ClassRange:
+		p.char(e.Min.Value, escaped)
+		p.out.WriteByte('-')
+		p.char(e.Max.Value, escaped)
+	case *CharacterClassEscape:
+		p.out.WriteString(`\` + string(e.Kind))
+	case *PropertyEscape:
+		p.property(e)
+	case *CharacterClass:
+		p.class(e)
+	case *ClassStringDisjunction:
+		p.out.WriteString(`\q{`)
+		for i, s := range e.Strings {
+			if i > 0 {
+				p.out.WriteByte('|')
+			}
+			for _, chr := range s.Characters {
+				p.char(chr.Value, escaped)
+			}
+		}
+		p.out.WriteByte('}')
+	}
+}
+
+// char writes a character, escaping it if it is in escaped or cannot be
+// written literally.
+func (p *printer) char(r rune, escaped string) {
+	switch {
+	case r == '\t':
+		p.out.WriteString(`\t`)
+	case r == '\n':
+		p.out.WriteString(`\n`)
+	case r == '\v':
+		p.out.WriteString(`\v`)
+	case r == '\f':
+		p.out.WriteString(`\f`)
+	case r == '\r':
+		p.out.WriteString(`\r`)
+	case r < 0x20 || r == 0x7F:
+		fmt.Fprintf(&p.out, `\x%02X`, r)
+	case r < 0x80:
+		if strings.ContainsRune(escaped, r) {
+			p.out.WriteByte('\\')
+		}
+		p.out.WriteRune(r)
+	case utf16.IsSurrogate(r) && p.unicode:
+		// \u escapes of surrogates would join into a pair.
+		fmt.Fprintf(&p.out, `\u{%X}`, r)
+	case utf16.IsSurrogate(r) || !unicode.IsPrint(r):
+		switch {
+		case r <= 0xFFFF:
+			fmt.Fprintf(&p.out, `\u%04X`, r)
+		case p.unicode:
+			fmt.Fprintf(&p.out, `\u{%X}`, r)
+		default:
+			hi, lo := utf16.EncodeRune(r)
+			fmt.Fprintf(&p.out, `\u%04X\u%04X`, hi, lo)
+		}
+	default:
+		p.out.WriteRune(r)
+	}
+}
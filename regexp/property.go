@@ -0,0 +1,143 @@
+package regexp
+
+// generalCategories are the values of General_Category and their aliases.
+var generalCategories = set(
+	"C", "Other",
+	"Cc", "Control", "cntrl",
+	"Cf", "Format",
+	"Cn", "Unassigned",
+	"Co", "Private_Use",
+	"Cs", "Surrogate",
+	"L", "Letter",
+	"LC", "Cased_Letter",
+	"Ll", "Lowercase_Letter",
+	"Lm", "Modifier_Letter",
+	"Lo", "Other_Letter",
+	"Lt", "Titlecase_Letter",
+	"Lu", "Uppercase_Letter",
+	"M", "Mark", "Combining_Mark",
+	"Mc", "Spacing_Mark",
+	"Me", "Enclosing_Mark",
+	"Mn", "Nonspacing_Mark",
+	"N", "Number",
+	"Nd", "Decimal_Number", "digit",
+	"Nl", "Letter_Number",
+	"No", "Other_Number",
+	"P", "Punctuation", "punct",
+	"Pc", "Connector_Punctuation",
+	"Pd", "Dash_Punctuation",
+	"Pe", "Close_Punctuation",
+	"Pf", "Final_Punctuation",
+	"Pi", "Initial_Punctuation",
+	"Po", "Other_Punctuation",
+	"Ps", "Open_Punctuation",
+	"S", "Symbol",
+	"Sc", "Currency_Symbol",
+	"Sk", "Modifier_Symbol",
+	"Sm", "Math_Symbol",
+	"So", "Other_Symbol",
+	"Z", "Separator",
+	"Zl", "Line_Separator",
+	"Zp", "Paragraph_Separator",
+	"Zs", "Space_Separator",
+)
+
+// binaryProperties are the binary properties and their aliases that can be
+// used without a value, as in \p{Alpha}.
+var binaryProperties = set(
+	"ASCII",
+	"ASCII_Hex_Digit", "AHex",
+	"Alphabetic", "Alpha",
+	"Any",
+	"Assigned",
+	"Bidi_Control", "Bidi_C",
+	"Bidi_Mirrored", "Bidi_M",
+	"Case_Ignorable", "CI",
+	"Cased",
+	"Changes_When_Casefolded", "CWCF",
+	"Changes_When_Casemapped", "CWCM",
+	"Changes_When_Lowercased", "CWL",
+	"Changes_When_NFKC_Casefolded", "CWKCF",
+	"Changes_When_Titlecased", "CWT",
+	"Changes_When_Uppercased", "CWU",
+	"Dash",
+	"Default_Ignorable_Code_Point", "DI",
+	"Deprecated", "Dep",
+	"Diacritic", "Dia",
+	"Emoji",
+	"Emoji_Component", "EComp",
+	"Emoji_Modifier", "EMod",
+	"Emoji_Modifier_Base", "EBase",
+	"Emoji_Presentation", "EPres",
+	"Extended_Pictographic", "ExtPict",
+	"Extender", "Ext",
+	"Grapheme_Base", "Gr_Base",
+	"Grapheme_Extend", "Gr_Ext",
+	"Hex_Digit", "Hex",
+	"IDS_Binary_Operator", "IDSB",
+	"IDS_Trinary_Operator", "IDST",
+	"IDS_Unary_Operator", "IDSU",
+	"ID_Compat_Math_Continue",
+	"ID_Compat_Math_Start",
+	"ID_Continue", "IDC",
+	"ID_Start", "IDS",
+	"Ideographic", "Ideo",
+	"Join_Control", "Join_C",
+	"Logical_Order_Exception", "LOE",
+	"Lowercase", "Lower",
+	"Math",
+	"Noncharacter_Code_Point", "NChar",
+	"Pattern_Syntax", "Pat_Syn",
+	"Pattern_White_Space", "Pat_WS",
+	"Quotation_Mark", "QMark",
+	"Radical",
+	"Regional_Indicator", "RI",
+	"Sentence_Terminal", "STerm",
+	"Soft_Dotted", "SD",
+	"Terminal_Punctuation", "Term",
+	"Unified_Ideograph", "UIdeo",
+	"Uppercase", "Upper",
+	"Variation_Selector", "VS",
+	"White_Space", "space",
+	"XID_Continue", "XIDC",
+	"XID_Start", "XIDS",
+)
+
+// stringProperties are the properties of strings, which are only available
+// with the v flag.
+var stringProperties = set(
+	"Basic_Emoji",
+	"Emoji_Keycap_Sequence",
+	"RGI_Emoji",
+	"RGI_Emoji_Flag_Sequence",
+	"RGI_Emoji_Modifier_Sequence",
+	"RGI_Emoji_Tag_Sequence",
+	"RGI_Emoji_ZWJ_Sequence",
+)
+
+func set(names ...string) map[string]bool {
+	m := make(map[string]bool, len(names))
+	for _, name := range names {
+		m[name] = true
+	}
+	return m
+}
+
+// validProperty reports whether \p{name=value}, or \p{value} with an empty
+// name, is a known property, and whether it is a property of strings. The
+// values of Script and Script_Extensions are not checked, as every version
+// of Unicode adds scripts.
+func validProperty(name, value string, sets bool) (ok, strings bool) {
+	switch name {
+	case "":
+		if sets && stringProperties[value] {
+			return true, true
+		}
+		return generalCategories[value] || binaryProperties[value], false
+	case "General_Category", "gc":
+		return generalCategories[value], false
+	case "Script", "sc", "Script_Extensions", "scx":
+		return true, false
+	}
+	return false, false
+}
@@ -0,0 +1,157 @@
+package regexp_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/t14raptor/go-fast/limit"
+	"github.com/t14raptor/go-fast/regexp"
+)
+
+func parse(t *testing.T, pattern, flags string) (*regexp.Pattern, error) {
+	t.Helper()
+	f, err := regexp.ParseFlags(flags)
+	if err != nil {
+		t.Fatalf("ParseFlags(%q) failed: %v", flags, err)
+	}
+	return regexp.Parse(pattern, f)
+}
+
+func TestPrint(t *testing.T) {
+	tests := []struct {
+		pattern, flags, want string
+	}{
+		{"", "", "(?:)"},
+		{"abc", "", "abc"},
+		{`a|b|`, "", "a|b|"},
+		{`\x61b\143`, "", "abc"},
+		{`\u{1F600}`, "u", "😀"},
+		{`😀`, "u", "😀"},
+		{`😀`, "", "😀"},
+		{`😀+`, "", `\uD83D\uDE00+`},
+		{`😀+`, "u", "😀+"},
+		{`\n\t\0\cJ\x7f`, "", `\n\t\x00\n\x7F`},
+		{`a*b+?c??d{2}e{2,}f{2,3}?`, "", "a*b+?c??d{2}e{2,}f{2,3}?"},
+		{`^\bx\B$`, "", `^\bx\B$`},
+		{`(a)(?:b)(?<c>d)\1\k<c>`, "", `(a)(?:b)(?<c>d)\1\k<c>`},
+		{`(a)\1\x30`, "", `(a)\1\x30`},
+		{`(?=a)(?!b)(?<=c)(?<!d)`, "", `(?=a)(?!b)(?<=c)(?<!d)`},
+		{`(?i:a)(?-m:b)(?s-i:c)`, "", `(?i:a)(?-m:b)(?s-i:c)`},
+		{`[a-z\d\-^][^\]]`, "", `[a-z\d\-\^][^\]]`},
+		{`[\w-a]`, "", `[\w\-a]`},
+		{`\p{L}\P{Script=Latin}`, "u", `\p{L}\P{Script=Latin}`},
+		{`[\p{L}&&\p{ASCII}][\w--_][[a-z]--\q{a|bc}][\p{RGI_Emoji}]`, "v", `[\p{L}&&\p{ASCII}][\w--_][[a-z]--\q{a|bc}][\p{RGI_Emoji}]`},
+		{`\/[/]`, "", `\/[\/]`},
+		{`{]}a{,2}`, "", `\{\]\}a\{,2\}`},
+		{`\c\d[\c_]`, "", `\\c\d[\x1F]`},
+		{`\8\k(?=a)*`, "", `8k(?=a)*`},
+		{`(?<π>a)|(?<π>b)`, "", `(?<π>a)|(?<π>b)`},
+		{`(?<\u{03C0}>a)`, "", `(?<π>a)`},
+		{"\u2028", "", `\u2028`},
+	}
+	for _, tt := range tests {
+		re, err := parse(t, tt.pattern, tt.flags)
+		if err != nil {
+			t.Errorf("Parse(/%s/%s) failed: %v", tt.pattern, tt.flags, err)
+			continue
+		}
+		if got := re.String(); got != tt.want {
+			t.Errorf("Parse(/%s/%s).String() = %s; want %s", tt.pattern, tt.flags, got, tt.want)
+		}
+		// The printed pattern has to parse to the same pattern.
+		again, err := parse(t, re.String(), tt.flags)
+		if err != nil {
+			t.Errorf("Parse(/%s/%s) failed: %v", re.String(), tt.flags, err)
+		} else if again.String() != re.String() {
+			t.Errorf("Parse(/%s/%s).String() = %s; want %s", re.String(), tt.flags, again.String(), re.String())
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		pattern, flags, message string
+		offset                  int
+	}{
+		{`a**`, "", "Nothing to repeat", 2},
+		{`+`, "", "Nothing to repeat", 0},
+		{`{1}`, "", "Nothing to repeat", 0},
+		{`^*`, "", "Nothing to repeat", 1},
+		{`(?<=a)+`, "", "Nothing to repeat", 6},
+		{`(?=a)+`, "u", "Nothing to repeat", 5},
+		{`a{2,1}`, "", "numbers out of order in {} quantifier", 1},
+		{`{`, "u", "Lone quantifier brackets", 0},
+		{`]`, "u", "Lone quantifier brackets", 0},
+		{`ab(c`, "", "Unterminated group", 2},
+		{`ab)`, "", "Unmatched ')'", 2},
+		{`(?x)`, "", "Invalid group", 0},
+		{`(?ii:a)`, "", "Repeated flag in group modifiers", 0},
+		{`(?-:a)`, "", "Invalid group", 0},
+		{`[a`, "", "Unterminated character class", 0},
+		{`[z-a]`, "", "Range out of order in character class", 1},
+		{`[\d-z]`, "u", "Invalid character class", 1},
+		{`a\`, "", `\ at end of pattern`, 1},
+		{`\a`, "u", "Invalid escape", 0},
+		{`\1`, "u", "Invalid escape", 0},
+		{`\c`, "u", "Invalid unicode escape", 0},
+		{`\u{110000}`, "u", "Invalid Unicode escape", 0},
+		{`\p{Foo}`, "u", "Invalid property name", 0},
+		{`\p{RGI_Emoji}`, "u", "Invalid property name", 0},
+		{`\P{RGI_Emoji}`, "v", "Invalid property name", 0},
+		{`(?<a>x)(?<a>y)`, "", "Duplicate capture group name", 7},
+		{`(?:(?<a>x)|y)(?<a>z)`, "", "Duplicate capture group name", 13},
+		{`(?<1a>x)`, "", "Invalid capture group name", 3},
+		{`\k<b>(?<a>x)`, "", "Invalid named capture referenced", 0},
+		{`\k(?<a>x)`, "", "Invalid named reference", 0},
+		{`[a-]`, "v", "Invalid character in character class", 3},
+		{`[a&&&b]`, "v", "Invalid character in character class", 4},
+		{`[a&&b--c]`, "v", "Invalid set operation in character class", 5},
+		{`[ab&&c]`, "v", "Invalid set operation in character class", 3},
+		{`[^\q{ab}]`, "v", "Negated character class may contain strings", 0},
+		{`[^\p{RGI_Emoji}]`, "v", "Negated character class may contain strings", 0},
+	}
+	for _, tt := range tests {
+		_, err := parse(t, tt.pattern, tt.flags)
+		e, ok := err.(*regexp.Error)
+		if !ok {
+			t.Errorf("Parse(/%s/%s) = %v; want error %q", tt.pattern, tt.flags, err, tt.message)
+			continue
+		}
+		if e.Message != tt.message || e.Offset != tt.offset {
+			t.Errorf("Parse(/%s/%s) = %q at %d; want %q at %d", tt.pattern, tt.flags, e.Message, e.Offset, tt.message, tt.offset)
+		}
+	}
+}
+
+func TestFlags(t *testing.T) {
+	f, err := regexp.ParseFlags("ygimsdv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := f.String(); got != "dgimsvy" {
+		t.Errorf("String() = %s; want dgimsvy", got)
+	}
+	for _, flags := range []string{"gg", "x", "uv"} {
+		if _, err := regexp.ParseFlags(flags); err == nil {
+			t.Errorf("ParseFlags(%q) succeeded; want an error", flags)
+		}
+	}
+}
+
+func TestParseTracked(t *testing.T) {
+	pattern := strings.Repeat("(", 10000) + "a" + strings.Repeat(")", 10000)
+	var tracker limit.Tracker
+	run := func(limits limit.Limits) (err error) {
+		defer limit.Catch(&err)
+		tracker.Start(limits)
+		_, err = regexp.ParseTracked(pattern, regexp.Flags{}, &tracker)
+		return err
+	}
+	if err := run(limit.Limits{MaxDepth: 100}); !errors.As(err, new(*limit.DepthError)) {
+		t.Errorf("Expected a depth error, got %v", err)
+	}
+	if err := run(limit.Limits{MaxDepth: 20000}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -80,6 +80,10 @@ func (h *hoister) VisitCatchStatement(n *ast.CatchStatement) {
 }
 
 func (h *hoister) VisitStatements(n *ast.Statements) {
+	names := lexicalNames(*n)
+	h.resolver.declareLexical(names, 1)
+	defer h.resolver.declareLexical(names, -1)
+
 	others := make(ast.Statements, 0, len(*n))
 	for i := range *n {
 		switch it := (*n)[i].Stmt.(type) {
@@ -122,6 +126,12 @@ func (h *hoister) VisitFunctionDeclaration(n *ast.FunctionDeclaration) {
 	}
 
 	if h.inBlock {
+		// Function declarations in blocks are only hoisted out of the block
+		// in sloppy mode code with web compatibility, and only if no lexical
+		// declaration on the way out has the same name.
+		if h.resolver.strict || !h.resolver.webCompat || h.resolver.lexical[n.Function.Name.Name] > 0 {
+			return
+		}
 		if kind, declared := h.resolver.current.isDeclared(n.Function.Name.Name); declared {
 			if kind != DeclKindVar && kind != DeclKindFunction {
 				return
@@ -132,13 +142,72 @@ func (h *hoister) VisitFunctionDeclaration(n *ast.FunctionDeclaration) {
 	h.resolver.modify(n.Function.Name, DeclKindFunction)
 }
 
+func (h *hoister) VisitImportDeclaration(n *ast.ImportDeclaration) {
+	for _, spec := range n.Specifiers {
+		switch s := spec.Specifier.(type) {
+		case *ast.ImportNamedSpecifier:
+			h.resolver.modify(s.Local, DeclKindVar)
+		case *ast.ImportDefaultSpecifier:
+			h.resolver.modify(s.Local, DeclKindVar)
+		case *ast.ImportNamespaceSpecifier:
+			h.resolver.modify(s.Local, DeclKindVar)
+		}
+	}
+}
+
 func (h *hoister) VisitSwitchStatement(n *ast.SwitchStatement) {
 	n.Discriminant.VisitWith(h)
 
+	names := switchLexicalNames(n)
+	h.resolver.declareLexical(names, 1)
 	old := h.inBlock
 	h.inBlock = true
 	n.Body.VisitWith(h)
 	h.inBlock = old
+	h.resolver.declareLexical(names, -1)
+}
+
+// VisitIfStatement treats a function declared as the body of an if
+// statement like one declared in a block, as Annex B specifies.
+func (h *hoister) VisitIfStatement(n *ast.IfStatement) {
+	old := h.inBlock
+	h.inBlock = true
+	n.VisitChildrenWith(h)
+	h.inBlock = old
+}
+
+// lexicalNames returns the names declared with let, const or class by the
+// statements of list itself.
+func lexicalNames(list ast.Statements) []string {
+	var names []string
+	for _, stmt := range list {
+		switch s := stmt.Stmt.(type) {
+		case *ast.VariableDeclaration:
+			if s.Token == token.Var {
+				continue
+			}
+			for _, decl := range s.List {
+				ast.BoundNames(decl.Target, func(id *ast.Identifier) {
+					names = append(names, id.Name)
+				})
+			}
+		case *ast.ClassDeclaration:
+			if s.Class.Name != nil {
+				names = append(names, s.Class.Name.Name)
+			}
+		}
+	}
+	return names
+}
+
+// switchLexicalNames is like lexicalNames for the clauses of n, which share
+// a single block.
+func switchLexicalNames(n *ast.SwitchStatement) []string {
+	var names []string
+	for _, clause := range n.Body {
+		names = append(names, lexicalNames(clause.Consequent)...)
+	}
+	return names
 }
 
 func (h *hoister) VisitArrowFunctionLiteral(*ast.ArrowFunctionLiteral) {}
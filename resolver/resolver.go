@@ -1,10 +1,6 @@
 package resolver
 
-import (
-	"fmt"
-
-	"github.com/t14raptor/go-fast/ast"
-)
+import "github.com/t14raptor/go-fast/ast"
 
 type IdentType int
 
@@ -25,6 +21,13 @@ type Resolver struct {
 
 	identType IdentType
 	declKind  DeclKind
+	strict    bool
+	webCompat bool
+	// lexical counts the names declared with let, const or class by the
+	// statement lists around the current one within its function. A
+	// function declared in a block is not hoisted past them, as a var of its
+	// name would clash with them.
+	lexical map[string]int
 
 	nextCtxt ast.ScopeContext
 }
@@ -32,6 +35,7 @@ type Resolver struct {
 func Resolve(p ast.VisitableNode) *Resolver {
 	r := &Resolver{
 		identType: IdentTypeRef,
+		webCompat: true,
 		nextCtxt:  TopLevelMark,
 	}
 	r.V = r
@@ -68,6 +72,18 @@ func (r *Resolver) modify(id *ast.Identifier, kind DeclKind) {
 	id.ScopeContext = r.current.ctx
 }
 
+func (r *Resolver) declareLexical(names []string, delta int) {
+	if len(names) == 0 {
+		return
+	}
+	if r.lexical == nil {
+		r.lexical = make(map[string]int)
+	}
+	for _, name := range names {
+		r.lexical[name] += delta
+	}
+}
+
 func (r *Resolver) lookupContext(sym string) (ast.ScopeContext, *Scope) {
 	for scope := r.current; scope != nil; scope = scope.parent {
 		if _, exists := scope.declaredSymbols[sym]; exists {
@@ -78,6 +94,9 @@ func (r *Resolver) lookupContext(sym string) (ast.ScopeContext, *Scope) {
 }
 
 func (r *Resolver) VisitArrowFunctionLiteral(n *ast.ArrowFunctionLiteral) {
+	oldStrict, oldLexical := r.strict, r.lexical
+	r.strict, r.lexical = n.Strict, nil
+
 	r.pushScope(ScopeKindFunction)
 
 	n.ScopeContext = r.current.ctx
@@ -98,6 +117,7 @@ func (r *Resolver) VisitArrowFunctionLiteral(n *ast.ArrowFunctionLiteral) {
 	r.identType = oldIdentType
 
 	r.popScope()
+	r.strict, r.lexical = oldStrict, oldLexical
 }
 
 func (r *Resolver) VisitBlockStatement(n *ast.BlockStatement) {
@@ -173,6 +193,9 @@ func (r *Resolver) VisitFunctionLiteral(n *ast.FunctionLiteral) {
 		r.modify(n.Name, DeclKindFunction)
 	}
 
+	oldStrict, oldLexical := r.strict, r.lexical
+	r.strict, r.lexical = n.Strict, nil
+
 	r.pushScope(ScopeKindFunction)
 
 	n.ScopeContext = r.current.ctx
@@ -181,12 +204,6 @@ func (r *Resolver) VisitFunctionLiteral(n *ast.FunctionLiteral) {
 	r.identType = IdentTypeBinding
 	n.ParameterList.VisitWith(r)
 
-	if rest, ok := n.ParameterList.Rest.(*ast.Identifier); ok {
-		rest.VisitWith(r)
-	} else if n.ParameterList.Rest != nil {
-		panic(fmt.Sprintf("Unexpected rest type: %T\n", n.ParameterList.Rest))
-	}
-
 	r.identType = IdentTypeRef
 	// Prevent creating new scope.
 	n.Body.ScopeContext = r.current.ctx
@@ -195,15 +212,30 @@ func (r *Resolver) VisitFunctionLiteral(n *ast.FunctionLiteral) {
 	r.identType = oldIdentType
 
 	r.popScope()
+	r.strict, r.lexical = oldStrict, oldLexical
 }
 
 func (r *Resolver) VisitProgram(n *ast.Program) {
+	r.strict = n.Strict
+	r.webCompat = n.WebCompat
 	r.pushScope(ScopeKindBlock)
 	n.VisitChildrenWith(r)
 	r.popScope()
 }
 
+func (r *Resolver) VisitClassLiteral(n *ast.ClassLiteral) {
+	// All parts of a class are strict mode code.
+	oldStrict := r.strict
+	r.strict = true
+	n.VisitChildrenWith(r)
+	r.strict = oldStrict
+}
+
 func (r *Resolver) VisitStatements(n *ast.Statements) {
+	names := lexicalNames(*n)
+	r.declareLexical(names, 1)
+	defer r.declareLexical(names, -1)
+
 	// Handle hoisting
 	h := newHoister(r)
 	h.V = h
@@ -213,6 +245,13 @@ func (r *Resolver) VisitStatements(n *ast.Statements) {
 	n.VisitChildrenWith(r)
 }
 
+func (r *Resolver) VisitSwitchStatement(n *ast.SwitchStatement) {
+	names := switchLexicalNames(n)
+	r.declareLexical(names, 1)
+	n.VisitChildrenWith(r)
+	r.declareLexical(names, -1)
+}
+
 func (r *Resolver) VisitVariableDeclaration(n *ast.VariableDeclaration) {
 	oldDeclKind := r.declKind
 	r.declKind = DeclKindVar
@@ -264,3 +303,25 @@ func (r *Resolver) VisitMemberProperty(n *ast.MemberProperty) {
 		computed.VisitWith(r)
 	}
 }
+
+func (r *Resolver) VisitExportNamedDeclaration(n *ast.ExportNamedDeclaration) {
+	if n.Declaration != nil {
+		n.Declaration.VisitWith(r)
+	}
+	if n.Source != nil {
+		return
+	}
+
+	// Local names of `export { a as b }` refer to bindings in the module scope.
+	oldIdentType := r.identType
+	r.identType = IdentTypeRef
+	for _, spec := range n.Specifiers {
+		if ident, ok := spec.Local.Name.(*ast.Identifier); ok {
+			ident.VisitWith(r)
+		}
+	}
+	r.identType = oldIdentType
+}
+
+// Imported and exported names are not bindings.
+func (r *Resolver) VisitModuleExportName(*ast.ModuleExportName) {}
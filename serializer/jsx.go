@@ -0,0 +1,166 @@
+package serializer
+
+import (
+	"strings"
+
+	"github.com/t14raptor/go-fast/ast"
+)
+
+func (s *Serializer) writeJSXChildren(children ast.JSXChildren) {
+	s.writeByte('[')
+	for i, child := range children {
+		if i > 0 {
+			s.writeStr(",")
+		}
+		s.serialize(child.Child)
+	}
+	s.writeByte(']')
+}
+
+func (s *Serializer) VisitJSXElement(n *ast.JSXElement) {
+	s.writeStr(`{"type":"JSXElement","openingElement":`)
+	s.serialize(n.Opening)
+	s.writeStr(`,"children":`)
+	s.writeJSXChildren(n.Children)
+	s.writeStr(`,"closingElement":`)
+	if n.Closing != nil {
+		s.serialize(n.Closing)
+	} else {
+		s.writeNull()
+	}
+	s.writeStr(",")
+	s.writePosition(n)
+	s.writeStr("}")
+}
+
+func (s *Serializer) VisitJSXOpeningElement(n *ast.JSXOpeningElement) {
+	s.writeStr(`{"type":"JSXOpeningElement","name":`)
+	s.serialize(n.Name)
+	s.writeStr(`,"attributes":[`)
+	for i, attr := range n.Attributes {
+		if i > 0 {
+			s.writeStr(",")
+		}
+		s.serialize(attr.Attribute)
+	}
+	s.writeStr(`],"selfClosing":`)
+	s.writeBool(n.SelfClosing)
+	s.writeStr(",")
+	s.writePosition(n)
+	s.writeStr("}")
+}
+
+func (s *Serializer) VisitJSXClosingElement(n *ast.JSXClosingElement) {
+	s.writeStr(`{"type":"JSXClosingElement","name":`)
+	s.serialize(n.Name)
+	s.writeStr(",")
+	s.writePosition(n)
+	s.writeStr("}")
+}
+
+func (s *Serializer) VisitJSXFragment(n *ast.JSXFragment) {
+	s.writeStr(`{"type":"JSXFragment","openingFragment":{"type":"JSXOpeningFragment",`)
+	s.writeRange(n.OpeningLessThan, n.OpeningGreaterThan+1)
+	s.writeStr(`},"children":`)
+	s.writeJSXChildren(n.Children)
+	s.writeStr(`,"closingFragment":{"type":"JSXClosingFragment",`)
+	s.writeRange(n.ClosingLessThan, n.ClosingGreaterThan+1)
+	s.writeStr("},")
+	s.writePosition(n)
+	s.writeStr("}")
+}
+
+// VisitJSXName writes a name as a JSXIdentifier, JSXNamespacedName or
+// JSXMemberExpression, depending on its form.
+func (s *Serializer) VisitJSXName(n *ast.JSXName) {
+	if i := strings.IndexByte(n.Name, ':'); i >= 0 {
+		s.writeStr(`{"type":"JSXNamespacedName","namespace":`)
+		s.writeJSXIdentifier(n.Name[:i], n.Idx)
+		s.writeStr(`,"name":`)
+		s.writeJSXIdentifier(n.Name[i+1:], n.Idx+ast.Idx(i+1))
+		s.writeStr(",")
+		s.writePosition(n)
+		s.writeStr("}")
+		return
+	}
+	s.writeJSXMemberName(n.Name, n.Idx)
+}
+
+func (s *Serializer) writeJSXMemberName(name string, idx ast.Idx) {
+	i := strings.LastIndexByte(name, '.')
+	if i < 0 {
+		s.writeJSXIdentifier(name, idx)
+		return
+	}
+	s.writeStr(`{"type":"JSXMemberExpression","object":`)
+	s.writeJSXMemberName(name[:i], idx)
+	s.writeStr(`,"property":`)
+	s.writeJSXIdentifier(name[i+1:], idx+ast.Idx(i+1))
+	s.writeStr(",")
+	s.writeRange(idx, idx+ast.Idx(len(name)))
+	s.writeStr("}")
+}
+
+func (s *Serializer) writeJSXIdentifier(name string, idx ast.Idx) {
+	s.writeStr(`{"type":"JSXIdentifier","name":`)
+	s.writeString(name)
+	s.writeStr(",")
+	s.writeRange(idx, idx+ast.Idx(len(name)))
+	s.writeStr("}")
+}
+
+func (s *Serializer) VisitJSXAttribute(n *ast.JSXAttribute) {
+	s.writeStr(`{"type":"JSXAttribute","name":`)
+	s.serialize(n.Name)
+	s.writeStr(`,"value":`)
+	if n.Value != nil {
+		s.serialize(n.Value.Expr)
+	} else {
+		s.writeNull()
+	}
+	s.writeStr(",")
+	s.writePosition(n)
+	s.writeStr("}")
+}
+
+func (s *Serializer) VisitJSXSpreadAttribute(n *ast.JSXSpreadAttribute) {
+	s.writeStr(`{"type":"JSXSpreadAttribute","argument":`)
+	s.serialize(n.Argument.Expr)
+	s.writeStr(",")
+	s.writePosition(n)
+	s.writeStr("}")
+}
+
+// VisitJSXExpressionContainer writes a container holding a spread element
+// as a JSXSpreadChild, and an empty one with a JSXEmptyExpression.
+func (s *Serializer) VisitJSXExpressionContainer(n *ast.JSXExpressionContainer) {
+	var spread *ast.SpreadElement
+	if n.Expression != nil {
+		spread, _ = n.Expression.Expr.(*ast.SpreadElement)
+	}
+	switch {
+	case n.Expression == nil:
+		s.writeStr(`{"type":"JSXExpressionContainer","expression":{"type":"JSXEmptyExpression",`)
+		s.writeRange(n.LeftBrace+1, n.RightBrace)
+		s.writeStr("}")
+	case spread != nil:
+		s.writeStr(`{"type":"JSXSpreadChild","expression":`)
+		s.serialize(spread.Expression.Expr)
+	default:
+		s.writeStr(`{"type":"JSXExpressionContainer","expression":`)
+		s.serialize(n.Expression.Expr)
+	}
+	s.writeStr(",")
+	s.writePosition(n)
+	s.writeStr("}")
+}
+
+func (s *Serializer) VisitJSXText(n *ast.JSXText) {
+	s.writeStr(`{"type":"JSXText","value":`)
+	s.writeString(n.Value)
+	s.writeStr(`,"raw":`)
+	s.writeString(n.Raw)
+	s.writeStr(",")
+	s.writePosition(n)
+	s.writeStr("}")
+}
@@ -2,10 +2,13 @@ package serializer
 
 import (
 	"strconv"
+	"strings"
 	"sync"
+	"unicode/utf8"
 	"unsafe"
 
 	"github.com/t14raptor/go-fast/ast"
+	"github.com/t14raptor/go-fast/limit"
 	"github.com/t14raptor/go-fast/token"
 )
 
@@ -20,19 +23,67 @@ var serializerPool = sync.Pool{
 
 // Serialize converts an AST node to ESTree-compatible JSON.
 func Serialize(node ast.VisitableNode) string {
+	result, _ := SerializeWithOptions(node, Options{})
+	return result
+}
+
+// Options configures the output of SerializeWithOptions.
+type Options struct {
+	// Locations adds an ESTree loc object to every node, holding the lines
+	// and columns of its start and end. Columns count UTF-16 code units from
+	// 0, like in acorn and babel.
+	Locations bool
+	// UTF16 counts the start and end offsets in UTF-16 code units instead
+	// of bytes, so that they index the source as a JavaScript string like
+	// the offsets of acorn and babel do.
+	UTF16 bool
+	// Source maps the positions of the nodes to lines, columns and UTF-16
+	// offsets. If it is nil, the Source of the serialized program is used.
+	// Without either, Locations and UTF16 have no effect.
+	Source *ast.SourceFile
+	// Limits stops the serialization with one of the errors of package limit
+	// when the nodes nest deeper than MaxDepth, more than MaxNodes nodes are
+	// serialized or Context is done.
+	Limits limit.Limits
+}
+
+// SerializeWithOptions is like Serialize but writes the output according
+// to opts. It only fails when a limit of opts.Limits is exceeded.
+func SerializeWithOptions(node ast.VisitableNode, opts Options) (result string, err error) {
 	s := serializerPool.Get().(*Serializer)
+	defer func() {
+		s.comments = nil
+		s.source = nil
+		serializerPool.Put(s)
+	}()
+	defer limit.Catch(&err)
 	s.out = s.out[:0] // Reset length, keep capacity
 	s.V = s
+	s.limits.Start(opts.Limits)
+	s.source = opts.Source
+	if program, ok := node.(*ast.Program); ok {
+		s.comments = program.CommentMap
+		if s.source == nil {
+			s.source = program.Source
+		}
+	}
+	s.locations = opts.Locations && s.source != nil
+	s.utf16 = opts.UTF16 && s.source != nil
 	node.VisitWith(s)
-	result := s.String()
-	serializerPool.Put(s)
-	return result
+	return s.String(), nil
 }
 
 // Serializer implements the ast.Visitor interface to serialize AST to JSON.
 type Serializer struct {
 	ast.NoopVisitor
 	out []byte
+
+	comments  map[ast.Node]*ast.NodeComments
+	source    *ast.SourceFile
+	locations bool // Write loc objects
+	utf16     bool // Count offsets in UTF-16 code units
+
+	limits limit.Tracker
 }
 
 // writeStr appends a string to the buffer
@@ -56,6 +107,9 @@ func (s *Serializer) serialize(node ast.VisitableNode) {
 		s.writeStr("null")
 		return
 	}
+	s.limits.Enter()
+	defer s.limits.Leave()
+	s.limits.Count()
 	node.VisitWith(s)
 }
 
@@ -198,37 +252,148 @@ func (s *Serializer) writeOperator(t token.Token) {
 
 // Convert 1-based Go position to 0-based ESTree position
 // Returns 0 if position is unset (was 0)
-func toESTreePos(pos ast.Idx) int {
+func (s *Serializer) offset(pos ast.Idx) int {
 	if pos == 0 {
 		return 0
 	}
+	if s.utf16 {
+		return s.source.OffsetIn(pos, ast.ColumnUTF16)
+	}
 	return int(pos) - 1
 }
 
 func (s *Serializer) writePosition(node ast.Node) {
+	if c, ok := s.comments[node]; ok {
+		if len(c.Leading) > 0 {
+			s.writeStr(`"leadingComments":`)
+			s.writeComments(c.Leading)
+			s.writeStr(",")
+		}
+		if len(c.Trailing) > 0 {
+			s.writeStr(`"trailingComments":`)
+			s.writeComments(c.Trailing)
+			s.writeStr(",")
+		}
+	}
+	s.writeRange(node.Idx0(), s.end(node))
+}
+
+// end returns the end of node. Identifiers and template elements do not
+// record theirs, so it is found in the source, where escape sequences and
+// line breaks can make their text longer than their value.
+func (s *Serializer) end(node ast.Node) ast.Idx {
+	switch n := node.(type) {
+	case *ast.Identifier:
+		if s.source != nil && n.Idx != 0 {
+			return s.identifierEnd(n)
+		}
+	case *ast.TemplateElement:
+		if s.source != nil && n.Idx != 0 {
+			return s.templateElementEnd(n)
+		}
+	}
+	return node.Idx1()
+}
+
+// identifierEnd walks the name of n through the source, where a character
+// may be spelled as a \u escape sequence.
+func (s *Serializer) identifierEnd(n *ast.Identifier) ast.Idx {
+	text := s.source.Text()
+	i := s.source.Offset(n.Idx)
+	if strings.HasPrefix(text[i:], n.Name) {
+		return n.Idx1()
+	}
+	for _, r := range n.Name {
+		switch {
+		case i >= len(text):
+			return ast.Idx(len(text) + 1)
+		case strings.HasPrefix(text[i:], `\u{`):
+			i += strings.IndexByte(text[i:], '}') + 1
+		case strings.HasPrefix(text[i:], `\u`):
+			i += len(`\u0000`)
+		default:
+			i += utf8.RuneLen(r)
+		}
+	}
+	return ast.Idx(min(i, len(text)) + 1)
+}
+
+// templateElementEnd returns where the text of n ends in the source, at the
+// next substitution or the closing backtick.
+func (s *Serializer) templateElementEnd(n *ast.TemplateElement) ast.Idx {
+	text := s.source.Text()
+	for i := s.source.Offset(n.Idx); i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '`':
+			return ast.Idx(i + 1)
+		case '$':
+			if strings.HasPrefix(text[i:], "${") {
+				return ast.Idx(i + 1)
+			}
+		}
+	}
+	return n.Idx1()
+}
+
+func (s *Serializer) writeRange(start, end ast.Idx) {
 	s.writeStr(`"start":`)
-	s.writeInt(toESTreePos(node.Idx0()))
+	s.writeInt(s.offset(start))
 	s.writeStr(`,"end":`)
-	s.writeInt(toESTreePos(node.Idx1()))
+	s.writeInt(s.offset(end))
+	if s.locations {
+		s.writeStr(`,"loc":{"start":`)
+		s.writeLocation(start)
+		s.writeStr(`,"end":`)
+		s.writeLocation(end)
+		s.writeByte('}')
+	}
+}
+
+func (s *Serializer) writeLocation(idx ast.Idx) {
+	pos := s.source.Position(idx, ast.ColumnUTF16)
+	s.writeStr(`{"line":`)
+	s.writeInt(pos.Line)
+	s.writeStr(`,"column":`)
+	s.writeInt(pos.Column)
+	s.writeByte('}')
 }
 
 func (s *Serializer) writePositionStartOnly(start ast.Idx) {
 	s.writeStr(`"start":`)
-	s.writeInt(toESTreePos(start))
+	s.writeInt(s.offset(start))
 }
 
-// Program
-func (s *Serializer) VisitProgram(n *ast.Program) {
-	s.writeStr(`{"type":"Program","body":[`)
-	for i, stmt := range n.Body {
+func (s *Serializer) writeComments(comments []*ast.Comment) {
+	s.writeByte('[')
+	for i, c := range comments {
 		if i > 0 {
 			s.writeStr(",")
 		}
-		s.serialize(stmt.Stmt)
+		s.writeStr(`{"type":`)
+		s.writeString(c.Kind.String())
+		s.writeStr(`,"value":`)
+		s.writeString(c.Text)
+		s.writeStr(",")
+		s.writePosition(c)
+		s.writeStr("}")
+	}
+	s.writeByte(']')
+}
+
+// Program
+func (s *Serializer) VisitProgram(n *ast.Program) {
+	s.writeStr(`{"type":"Program","body":`)
+	s.writeBody(n.Directives, n.Body)
+	s.writeStr(`,"sourceType":`)
+	s.writeString(n.SourceType.String())
+	if len(n.Comments) > 0 {
+		s.writeStr(`,"comments":`)
+		s.writeComments(n.Comments)
 	}
-	s.writeStr("]")
 	// Program.Idx0()/Idx1() panic on empty body, so only write position if non-empty
-	if len(n.Body) > 0 {
+	if len(n.Directives) > 0 || len(n.Body) > 0 {
 		s.writeStr(",")
 		s.writePosition(n)
 	}
@@ -265,6 +430,20 @@ func (s *Serializer) VisitBooleanLiteral(n *ast.BooleanLiteral) {
 	s.writeStr("}")
 }
 
+func (s *Serializer) VisitBigIntLiteral(n *ast.BigIntLiteral) {
+	s.writeStr(`{"type":"Literal","value":null,`)
+	if n.Raw != nil {
+		s.writeStr(`"raw":`)
+		s.writeString(*n.Raw)
+		s.writeStr(",")
+	}
+	s.writeStr(`"bigint":`)
+	s.writeString(n.Value.String())
+	s.writeStr(",")
+	s.writePosition(n)
+	s.writeStr("}")
+}
+
 func (s *Serializer) VisitNullLiteral(n *ast.NullLiteral) {
 	s.writeStr(`{"type":"Literal","value":null,`)
 	s.writePosition(n)
@@ -306,6 +485,12 @@ func (s *Serializer) VisitRegExpLiteral(n *ast.RegExpLiteral) {
 }
 
 // Expressions
+func (s *Serializer) VisitInvalidExpression(n *ast.InvalidExpression) {
+	s.writeStr(`{"type":"InvalidExpression",`)
+	s.writePosition(n)
+	s.writeStr("}")
+}
+
 func (s *Serializer) VisitBinaryExpression(n *ast.BinaryExpression) {
 	s.writeStr(`{"type":"BinaryExpression","operator":`)
 	s.writeOperator(n.Operator)
@@ -582,6 +767,20 @@ func (s *Serializer) VisitMetaProperty(n *ast.MetaProperty) {
 	s.writeStr("}")
 }
 
+func (s *Serializer) VisitImportExpression(n *ast.ImportExpression) {
+	s.writeStr(`{"type":"ImportExpression","source":`)
+	s.serialize(n.Source.Expr)
+	s.writeStr(`,"options":`)
+	if n.Options != nil {
+		s.serialize(n.Options.Expr)
+	} else {
+		s.writeNull()
+	}
+	s.writeStr(",")
+	s.writePosition(n)
+	s.writeStr("}")
+}
+
 // Functions
 func (s *Serializer) VisitFunctionLiteral(n *ast.FunctionLiteral) {
 	s.writeStr(`{"type":"FunctionExpression","id":`)
@@ -673,18 +872,46 @@ func (s *Serializer) VisitConciseBody(n *ast.ConciseBody) {
 
 // Statements
 func (s *Serializer) VisitBlockStatement(n *ast.BlockStatement) {
-	s.writeStr(`{"type":"BlockStatement","body":[`)
-	for i, stmt := range n.List {
+	s.writeStr(`{"type":"BlockStatement","body":`)
+	s.writeBody(n.Directives, n.List)
+	if n.ScopeContext != 0 {
+		s.writeStr(`,"scopeContext":`)
+		s.writeInt(int(n.ScopeContext))
+	}
+	s.writeStr(",")
+	s.writePosition(n)
+	s.writeStr("}")
+}
+
+// writeBody writes a statement list preceded by its directive prologue.
+func (s *Serializer) writeBody(directives ast.Directives, list ast.Statements) {
+	s.writeByte('[')
+	for i := range directives {
 		if i > 0 {
 			s.writeStr(",")
 		}
-		s.serialize(stmt.Stmt)
+		s.serialize(&directives[i])
 	}
-	s.writeStr("]")
-	if n.ScopeContext != 0 {
-		s.writeStr(`,"scopeContext":`)
-		s.writeInt(int(n.ScopeContext))
+	for i, stmt := range list {
+		if i > 0 || len(directives) > 0 {
+			s.writeStr(",")
+		}
+		s.serialize(stmt.Stmt)
 	}
+	s.writeByte(']')
+}
+
+// VisitDirective writes a directive the ESTree way, as an expression
+// statement of a string literal with the directive text alongside.
+func (s *Serializer) VisitDirective(n *ast.Directive) {
+	s.writeStr(`{"type":"ExpressionStatement","expression":{"type":"Literal","value":`)
+	s.writeString(n.Cooked)
+	s.writeStr(`,"raw":`)
+	s.writeString(n.Raw)
+	s.writeStr(",")
+	s.writeRange(n.Idx0(), n.Idx1())
+	s.writeStr(`},"directive":`)
+	s.writeString(n.Value)
 	s.writeStr(",")
 	s.writePosition(n)
 	s.writeStr("}")
@@ -698,6 +925,12 @@ func (s *Serializer) VisitExpressionStatement(n *ast.ExpressionStatement) {
 	s.writeStr("}")
 }
 
+func (s *Serializer) VisitBadStatement(n *ast.BadStatement) {
+	s.writeStr(`{"type":"BadStatement",`)
+	s.writePosition(n)
+	s.writeStr("}")
+}
+
 func (s *Serializer) VisitEmptyStatement(n *ast.EmptyStatement) {
 	s.writeStr(`{"type":"EmptyStatement",`)
 	s.writePosition(n)
@@ -774,7 +1007,9 @@ func (s *Serializer) VisitForOfStatement(n *ast.ForOfStatement) {
 	s.serialize(n.Source.Expr)
 	s.writeStr(`,"body":`)
 	s.serialize(n.Body.Stmt)
-	s.writeStr(`,"await":false,`)
+	s.writeStr(`,"await":`)
+	s.writeBool(n.Await)
+	s.writeStr(",")
 	s.writePosition(n)
 	s.writeStr("}")
 }
@@ -1090,7 +1325,9 @@ func (s *Serializer) VisitClassLiteral(n *ast.ClassLiteral) {
 		}
 		s.serialize(elem.Element)
 	}
-	s.writeStr("]},")
+	s.writeStr(`]},"decorators":`)
+	s.writeDecorators(n.Decorators)
+	s.writeStr(",")
 	s.writePosition(n)
 	s.writeStr("}")
 }
@@ -1115,7 +1352,9 @@ func (s *Serializer) VisitClassDeclaration(n *ast.ClassDeclaration) {
 		}
 		s.serialize(elem.Element)
 	}
-	s.writeStr("]},")
+	s.writeStr(`]},"decorators":`)
+	s.writeDecorators(n.Class.Decorators)
+	s.writeStr(",")
 	s.writePosition(n)
 	s.writeStr("}")
 }
@@ -1137,24 +1376,61 @@ func (s *Serializer) VisitMethodDefinition(n *ast.MethodDefinition) {
 	s.writeBool(n.Computed)
 	s.writeStr(`,"static":`)
 	s.writeBool(n.Static)
+	s.writeStr(`,"decorators":`)
+	s.writeDecorators(n.Decorators)
 	s.writeStr(",")
 	s.writePosition(n)
 	s.writeStr("}")
 }
 
 func (s *Serializer) VisitFieldDefinition(n *ast.FieldDefinition) {
-	s.writeStr(`{"type":"PropertyDefinition","key":`)
-	s.serialize(n.Key.Expr)
+	s.writeStr(`{"type":"PropertyDefinition",`)
+	s.writeField(n.Key, n.Initializer, n.Computed, n.Static, n.Decorators)
+	s.writePosition(n)
+	s.writeStr("}")
+}
+
+func (s *Serializer) VisitClassAccessorProperty(n *ast.ClassAccessorProperty) {
+	s.writeStr(`{"type":"AccessorProperty",`)
+	s.writeField(n.Key, n.Initializer, n.Computed, n.Static, n.Decorators)
+	s.writePosition(n)
+	s.writeStr("}")
+}
+
+// writeField writes the properties shared by PropertyDefinition and
+// AccessorProperty, followed by a comma.
+func (s *Serializer) writeField(key, value *ast.Expression, computed, static bool, decorators ast.Decorators) {
+	s.writeStr(`"key":`)
+	s.serialize(key.Expr)
 	s.writeStr(`,"value":`)
-	if n.Initializer != nil {
-		s.serialize(n.Initializer.Expr)
+	if value != nil {
+		s.serialize(value.Expr)
 	} else {
 		s.writeNull()
 	}
 	s.writeStr(`,"computed":`)
-	s.writeBool(n.Computed)
+	s.writeBool(computed)
 	s.writeStr(`,"static":`)
-	s.writeBool(n.Static)
+	s.writeBool(static)
+	s.writeStr(`,"decorators":`)
+	s.writeDecorators(decorators)
+	s.writeStr(",")
+}
+
+func (s *Serializer) writeDecorators(decorators ast.Decorators) {
+	s.writeByte('[')
+	for i := range decorators {
+		if i > 0 {
+			s.writeStr(",")
+		}
+		s.serialize(&decorators[i])
+	}
+	s.writeByte(']')
+}
+
+func (s *Serializer) VisitDecorator(n *ast.Decorator) {
+	s.writeStr(`{"type":"Decorator","expression":`)
+	s.serialize(n.Expression.Expr)
 	s.writeStr(",")
 	s.writePosition(n)
 	s.writeStr("}")
@@ -1195,3 +1471,122 @@ func (s *Serializer) VisitPrivateDotExpression(n *ast.PrivateDotExpression) {
 	s.writePosition(n)
 	s.writeStr("}")
 }
+
+// Modules
+func (s *Serializer) VisitImportDeclaration(n *ast.ImportDeclaration) {
+	s.writeStr(`{"type":"ImportDeclaration","specifiers":[`)
+	for i, spec := range n.Specifiers {
+		if i > 0 {
+			s.writeStr(",")
+		}
+		s.serialize(spec.Specifier)
+	}
+	s.writeStr(`],"source":`)
+	s.serialize(n.Source)
+	s.writeStr(",")
+	s.writePosition(n)
+	s.writeStr("}")
+}
+
+func (s *Serializer) VisitImportNamedSpecifier(n *ast.ImportNamedSpecifier) {
+	s.writeStr(`{"type":"ImportSpecifier","imported":`)
+	s.serialize(n.Imported)
+	s.writeStr(`,"local":`)
+	s.serialize(n.Local)
+	s.writeStr(",")
+	s.writePosition(n)
+	s.writeStr("}")
+}
+
+func (s *Serializer) VisitImportDefaultSpecifier(n *ast.ImportDefaultSpecifier) {
+	s.writeStr(`{"type":"ImportDefaultSpecifier","local":`)
+	s.serialize(n.Local)
+	s.writeStr(",")
+	s.writePosition(n)
+	s.writeStr("}")
+}
+
+func (s *Serializer) VisitImportNamespaceSpecifier(n *ast.ImportNamespaceSpecifier) {
+	s.writeStr(`{"type":"ImportNamespaceSpecifier","local":`)
+	s.serialize(n.Local)
+	s.writeStr(",")
+	s.writePosition(n)
+	s.writeStr("}")
+}
+
+func (s *Serializer) VisitExportNamedDeclaration(n *ast.ExportNamedDeclaration) {
+	s.writeStr(`{"type":"ExportNamedDeclaration","declaration":`)
+	if n.Declaration != nil {
+		s.serialize(n.Declaration.Stmt)
+	} else {
+		s.writeNull()
+	}
+	s.writeStr(`,"specifiers":[`)
+	for i, spec := range n.Specifiers {
+		if i > 0 {
+			s.writeStr(",")
+		}
+		s.serialize(&spec)
+	}
+	s.writeStr(`],"source":`)
+	if n.Source != nil {
+		s.serialize(n.Source)
+	} else {
+		s.writeNull()
+	}
+	s.writeStr(",")
+	s.writePosition(n)
+	s.writeStr("}")
+}
+
+func (s *Serializer) VisitExportSpecifier(n *ast.ExportSpecifier) {
+	s.writeStr(`{"type":"ExportSpecifier","local":`)
+	s.serialize(n.Local)
+	s.writeStr(`,"exported":`)
+	s.serialize(n.Exported)
+	s.writeStr(",")
+	s.writePosition(n)
+	s.writeStr("}")
+}
+
+func (s *Serializer) VisitExportDefaultDeclaration(n *ast.ExportDefaultDeclaration) {
+	s.writeStr(`{"type":"ExportDefaultDeclaration","declaration":`)
+	if n.Declaration != nil {
+		s.serialize(n.Declaration.Stmt)
+	} else {
+		// Anonymous functions and classes are declarations in ESTree.
+		switch e := n.Expression.Expr.(type) {
+		case *ast.FunctionLiteral:
+			s.VisitFunctionDeclaration(&ast.FunctionDeclaration{Function: e})
+		case *ast.ClassLiteral:
+			class := *e
+			if class.Name != nil && class.Name.Name == "" {
+				class.Name = nil
+			}
+			s.VisitClassDeclaration(&ast.ClassDeclaration{Class: &class})
+		default:
+			s.serialize(e)
+		}
+	}
+	s.writeStr(",")
+	s.writePosition(n)
+	s.writeStr("}")
+}
+
+func (s *Serializer) VisitExportAllDeclaration(n *ast.ExportAllDeclaration) {
+	s.writeStr(`{"type":"ExportAllDeclaration","exported":`)
+	if n.Exported != nil {
+		s.serialize(n.Exported)
+	} else {
+		s.writeNull()
+	}
+	s.writeStr(`,"source":`)
+	s.serialize(n.Source)
+	s.writeStr(",")
+	s.writePosition(n)
+	s.writeStr("}")
+}
+
+func (s *Serializer) VisitModuleExportName(n *ast.ModuleExportName) {
+	s.serialize(n.Name)
+}
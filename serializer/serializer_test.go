@@ -2,8 +2,12 @@ package serializer
 
 import (
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
+	"unicode/utf16"
 
+	"github.com/t14raptor/go-fast/limit"
 	"github.com/t14raptor/go-fast/parser"
 )
 
@@ -58,6 +62,7 @@ const arrowImplicit = x => x * 2;
 
 async function asyncFn() {
     await promise;
+    for await (const chunk of stream) {}
     return 1;
 }
 
@@ -283,3 +288,189 @@ func BenchmarkSerializerJSONSimple(b *testing.B) {
 		_, _ = json.Marshal(program)
 	}
 }
+
+func TestTolerantJSON(t *testing.T) {
+	program, _ := parser.ParseFileWithOptions("let a = ;\n} b();\nswitch (x) {}\n({a});", parser.Options{Tolerant: true})
+	result := Serialize(program)
+	if !json.Valid([]byte(result)) {
+		t.Fatalf("Invalid JSON output: %s", result)
+	}
+	for _, typ := range []string{`"InvalidExpression"`, `"SwitchStatement"`} {
+		if !strings.Contains(result, typ) {
+			t.Errorf("Expected %s in output: %s", typ, result)
+		}
+	}
+}
+
+func TestDirectives(t *testing.T) {
+	program, err := parser.ParseFile(`"\x41";`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out struct {
+		Body []struct {
+			Directive  string
+			Expression struct {
+				Value string
+				Raw   string
+			}
+		}
+	}
+	if err := json.Unmarshal([]byte(Serialize(program)), &out); err != nil {
+		t.Fatal(err)
+	}
+	// Only the directive keeps the escape, the value of the literal is cooked.
+	if d := out.Body[0]; d.Directive != `\x41` || d.Expression.Value != "A" || d.Expression.Raw != `"\x41"` {
+		t.Errorf("Unexpected directive %+v", d)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	program, err := parser.ParseFile(strings.Repeat("[", 1000) + strings.Repeat("]", 1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SerializeWithOptions(program, Options{Limits: limit.Limits{MaxDepth: 100}}); !errors.As(err, new(*limit.DepthError)) {
+		t.Errorf("Expected a depth error, got %v", err)
+	}
+	if _, err := SerializeWithOptions(program, Options{Limits: limit.Limits{MaxNodes: 100}}); !errors.As(err, new(*limit.NodeError)) {
+		t.Errorf("Expected a node error, got %v", err)
+	}
+	result, err := SerializeWithOptions(program, Options{Limits: limit.Limits{MaxDepth: 10000}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid([]byte(result)) {
+		t.Fatalf("Invalid JSON output: %s", result)
+	}
+}
+
+func TestLocations(t *testing.T) {
+	program, err := parser.ParseFile("'😀';\nfoo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := SerializeWithOptions(program, Options{Locations: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid([]byte(result)) {
+		t.Fatalf("Invalid JSON output: %s", result)
+	}
+	for _, loc := range []string{
+		`"loc":{"start":{"line":1,"column":0},"end":{"line":1,"column":4}}`,
+		`"loc":{"start":{"line":2,"column":0},"end":{"line":2,"column":3}}`,
+	} {
+		if !strings.Contains(result, loc) {
+			t.Errorf("Expected %s in output: %s", loc, result)
+		}
+	}
+	if result := Serialize(program); strings.Contains(result, `"loc"`) {
+		t.Errorf("Unexpected loc in output: %s", result)
+	}
+}
+
+func TestUTF16Offsets(t *testing.T) {
+	code := "x = 'é😀';\ny"
+	program, err := parser.ParseFile(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := SerializeWithOptions(program, Options{UTF16: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out struct {
+		Body []struct {
+			Start, End int
+			Expression struct {
+				Start, End int
+			}
+		}
+	}
+	if err := json.Unmarshal([]byte(result), &out); err != nil {
+		t.Fatal(err)
+	}
+	// The offsets index code as a JavaScript string.
+	if got := out.Body[0].Expression; got.Start != 0 || got.End != 9 {
+		t.Errorf("Expected the assignment at 0-9, got %d-%d", got.Start, got.End)
+	}
+	if got := out.Body[1]; got.Start != 11 || got.End != 12 {
+		t.Errorf("Expected the second statement at 11-12, got %d-%d", got.Start, got.End)
+	}
+}
+
+func TestUTF16Slices(t *testing.T) {
+	code := "let ñ = `é${ñ}😀\r\nü`, s = 'x😀';\nñ.ñ;"
+	program, err := parser.ParseFile(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := SerializeWithOptions(program, Options{UTF16: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var root any
+	if err := json.Unmarshal([]byte(result), &root); err != nil {
+		t.Fatal(err)
+	}
+	// Every node slices its own text out of code as a JavaScript string.
+	src := utf16.Encode([]rune(code))
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case []any:
+			for _, elem := range v {
+				walk(elem)
+			}
+		case map[string]any:
+			for _, elem := range v {
+				walk(elem)
+			}
+			start, ok1 := v["start"].(float64)
+			end, ok2 := v["end"].(float64)
+			if !ok1 || !ok2 {
+				return
+			}
+			if start < 0 || start > end || int(end) > len(src) {
+				t.Errorf("Invalid range %v-%v of %v", start, end, v["type"])
+				return
+			}
+			text := string(utf16.Decode(src[int(start):int(end)]))
+			switch v["type"] {
+			case "Identifier":
+				if text != v["name"] {
+					t.Errorf("Expected the identifier %q, got %q", v["name"], text)
+				}
+			case "TemplateElement":
+				if raw := v["value"].(map[string]any)["raw"]; strings.ReplaceAll(text, "\r\n", "\n") != raw {
+					t.Errorf("Expected the template element %q, got %q", raw, text)
+				}
+			case "Literal":
+				if text != "'x😀'" {
+					t.Errorf("Expected the string literal, got %q", text)
+				}
+			}
+		}
+	}
+	walk(root)
+}
+
+func TestJSX(t *testing.T) {
+	program, err := parser.ParseFileWithOptions(`<A.B x:y="1" {...p}>a{}{...c}<></></A.B>`, parser.Options{JSX: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := Serialize(program)
+	if !json.Valid([]byte(result)) {
+		t.Fatalf("Invalid JSON output: %s", result)
+	}
+	for _, typ := range []string{
+		`"JSXElement"`, `"JSXMemberExpression"`, `"JSXNamespacedName"`, `"JSXSpreadAttribute"`,
+		`"JSXText"`, `"JSXEmptyExpression"`, `"JSXSpreadChild"`, `"JSXFragment"`, `"JSXClosingElement"`,
+	} {
+		if !strings.Contains(result, typ) {
+			t.Errorf("Expected %s in output: %s", typ, result)
+		}
+	}
+}
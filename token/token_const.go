@@ -6,9 +6,13 @@ const (
 	Illegal
 	Eof
 	Comment
+	Whitespace
 
 	String
 	Number
+	BigInt
+	RegExp
+	Template
 
 	Plus      // +
 	Minus     // -
@@ -37,6 +41,9 @@ const (
 	ShiftLeftAssign          // <<=
 	ShiftRightAssign         // >>=
 	UnsignedShiftRightAssign // >>>=
+	LogicalAndAssign         // &&=
+	LogicalOrAssign          // ||=
+	CoalesceAssign           // ??=
 
 	LogicalAnd // &&
 	LogicalOr  // ||
@@ -74,6 +81,7 @@ const (
 	Arrow            // =>
 	Ellipsis         // ...
 	Backtick         // `
+	At               // @
 
 	PrivateIdentifier
 
@@ -110,6 +118,8 @@ const (
 	Typeof
 	Delete
 	Switch
+	Import
+	Export
 
 	Default
 	Finally
@@ -128,17 +138,26 @@ const (
 	Async
 	Await
 	Yield
+
+	// Using and AwaitUsing are the kinds of using declarations. The lexer
+	// never produces them, as using is not a reserved word.
+	Using
+	AwaitUsing
 )
 
 var token2string = [...]string{
 	Illegal:                  "Illegal",
 	Eof:                      "Eof",
 	Comment:                  "Comment",
+	Whitespace:               "Whitespace",
 	Keyword:                  "Keyword",
 	String:                   "String",
 	Boolean:                  "Boolean",
 	Null:                     "Null",
 	Number:                   "Number",
+	BigInt:                   "BigInt",
+	RegExp:                   "RegExp",
+	Template:                 "Template",
 	Identifier:               "Identifier",
 	PrivateIdentifier:        "PrivateIdentifier",
 	Plus:                     "+",
@@ -165,6 +184,9 @@ var token2string = [...]string{
 	ShiftLeftAssign:          "<<=",
 	ShiftRightAssign:         ">>=",
 	UnsignedShiftRightAssign: ">>>=",
+	LogicalAndAssign:         "&&=",
+	LogicalOrAssign:          "||=",
+	CoalesceAssign:           "??=",
 	LogicalAnd:               "&&",
 	LogicalOr:                "||",
 	Coalesce:                 "??",
@@ -196,6 +218,7 @@ var token2string = [...]string{
 	Arrow:                    "=>",
 	Ellipsis:                 "...",
 	Backtick:                 "`",
+	At:                       "@",
 	If:                       "if",
 	In:                       "in",
 	Of:                       "of",
@@ -224,6 +247,8 @@ var token2string = [...]string{
 	Typeof:                   "typeof",
 	Delete:                   "delete",
 	Switch:                   "switch",
+	Import:                   "import",
+	Export:                   "export",
 	Static:                   "static",
 	Default:                  "default",
 	Finally:                  "finally",
@@ -232,6 +257,8 @@ var token2string = [...]string{
 	Continue:                 "continue",
 	Debugger:                 "debugger",
 	InstanceOf:               "instanceof",
+	Using:                    "using",
+	AwaitUsing:               "await using",
 }
 
 var keywordTable = map[string]keyword{
@@ -327,15 +354,13 @@ var keywordTable = map[string]keyword{
 		futureKeyword: true,
 	},
 	"export": {
-		token:         Keyword,
-		futureKeyword: true,
+		token: Export,
 	},
 	"extends": {
 		token: Extends,
 	},
 	"import": {
-		token:         Keyword,
-		futureKeyword: true,
+		token: Import,
 	},
 	"super": {
 		token: Super,
@@ -18,6 +18,7 @@ type scope struct {
 
 	bindingsAffectedByEval map[ast.Id]struct{}
 	foundDirectEval        bool
+	directEval             *ast.CallExpression // The first direct eval found
 
 	bindingsAffectedByArguments map[ast.Id]struct{}
 	foundArguments              bool
@@ -73,12 +74,19 @@ func (a *analyzer) Withscope(kind scopeKind, op func(*analyzer)) {
 		for id := range child.bindingsAffectedByEval {
 			if name, ok := a.data.usedNames[id]; !ok {
 				a.data.usedNames[id] = varInfo{Usage: 1}
+				a.data.keptByEval[id] = child.directEval
 			} else {
+				if name.Usage == 0 && name.Assign == 0 {
+					a.data.keptByEval[id] = child.directEval
+				}
 				name.Usage++
 				a.data.usedNames[id] = name
 			}
 		}
 		a.scope.foundDirectEval = true
+		if a.scope.directEval == nil {
+			a.scope.directEval = child.directEval
+		}
 	}
 
 	// If we found arguments, mark all declarations in scope and upper as used
@@ -147,6 +155,9 @@ func (a *analyzer) VisitCallExpression(n *ast.CallExpression) {
 	if ident, ok := n.Callee.Expr.(*ast.Identifier); ok {
 		if ident.Name == "eval" {
 			a.scope.foundDirectEval = true
+			if a.scope.directEval == nil {
+				a.scope.directEval = n
+			}
 		}
 	}
 }
@@ -240,3 +251,42 @@ func (a *analyzer) VisitVariableDeclaration(n *ast.VariableDeclaration) {
 	}
 	a.inVarDecl = old
 }
+
+// Exported bindings are observable from other modules, so they are treated as used.
+func (a *analyzer) VisitExportNamedDeclaration(n *ast.ExportNamedDeclaration) {
+	n.VisitChildrenWith(a)
+
+	if n.Declaration != nil {
+		a.addExported(n.Declaration.Stmt)
+	}
+	if n.Source == nil {
+		for _, spec := range n.Specifiers {
+			if ident, ok := spec.Local.Name.(*ast.Identifier); ok {
+				a.Add(ident.ToId(), false)
+			}
+		}
+	}
+}
+
+func (a *analyzer) VisitExportDefaultDeclaration(n *ast.ExportDefaultDeclaration) {
+	n.VisitChildrenWith(a)
+
+	if n.Declaration != nil {
+		a.addExported(n.Declaration.Stmt)
+	}
+}
+
+func (a *analyzer) addExported(decl ast.Stmt) {
+	switch decl := decl.(type) {
+	case *ast.FunctionDeclaration:
+		a.Add(decl.Function.Name.ToId(), false)
+	case *ast.ClassDeclaration:
+		a.Add(decl.Class.Name.ToId(), false)
+	case *ast.VariableDeclaration:
+		for _, v := range decl.List {
+			for id := range collectIdentifiers(v.Target) {
+				a.Add(id, false)
+			}
+		}
+	}
+}
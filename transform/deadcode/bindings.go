@@ -6,29 +6,34 @@ type bindingCollector struct {
 	ast.NoopVisitor
 	only     *ast.ScopeContext
 	bindings map[ast.Id]struct{}
+	idents   map[ast.Id]*ast.Identifier // The declaring identifiers, if not nil
 }
 
-func (v *bindingCollector) add(id ast.Id) {
+func (v *bindingCollector) add(ident *ast.Identifier) {
+	id := ident.ToId()
 	if v.only != nil && *v.only != id.ScopeContext {
 		return
 	}
 	v.bindings[id] = struct{}{}
+	if v.idents != nil {
+		v.idents[id] = ident
+	}
 }
 
 func (v *bindingCollector) VisitClassLiteral(n *ast.ClassLiteral) {
 	n.VisitChildrenWith(v)
-	v.add(n.Name.ToId())
+	v.add(n.Name)
 }
 
 func (v *bindingCollector) VisitFunctionDeclaration(n *ast.FunctionDeclaration) {
 	n.VisitChildrenWith(v)
-	v.add(n.Function.Name.ToId())
+	v.add(n.Function.Name)
 }
 
 func (v *bindingCollector) VisitBindingTarget(n *ast.BindingTarget) {
 	n.VisitChildrenWith(v)
 	if ident, ok := n.Target.(*ast.Identifier); ok {
-		v.add(ident.ToId())
+		v.add(ident)
 	}
 }
 
@@ -42,6 +47,17 @@ func collectDeclarations(n ast.VisitableNode) map[ast.Id]struct{} {
 	return visitor.bindings
 }
 
+// collectDeclaringIdents collects the identifiers that declare bindings.
+func collectDeclaringIdents(n ast.VisitableNode) map[ast.Id]*ast.Identifier {
+	visitor := &bindingCollector{
+		bindings: make(map[ast.Id]struct{}),
+		idents:   make(map[ast.Id]*ast.Identifier),
+	}
+	visitor.V = visitor
+	n.VisitWith(visitor)
+	return visitor.idents
+}
+
 // collectDeclarationsInScope collects binding if they are in the given scope.
 func collectDeclarationsInScope(n ast.VisitableNode, scope ast.ScopeContext) map[ast.Id]struct{} {
 	visitor := &bindingCollector{
@@ -19,6 +19,9 @@ type data struct {
 	usedNames map[ast.Id]varInfo
 	graph     cfg.DirectedGraph[ast.Id, varInfo]
 	entries   map[ast.Id]struct{}
+	// keptByEval holds the bindings that are only used by the direct eval
+	// in their scope, which may reference any of them.
+	keptByEval map[ast.Id]*ast.CallExpression
 }
 
 func (d *data) AddDependencyEdge(from, to ast.Id, assign bool) {
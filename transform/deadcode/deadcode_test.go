@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/t14raptor/go-fast/diag"
 	"github.com/t14raptor/go-fast/generator"
 	"github.com/t14raptor/go-fast/parser"
 	"github.com/t14raptor/go-fast/transform/deadcode"
@@ -89,3 +90,40 @@ func TestEmptyBlockStmt(t *testing.T) {
 func TestObject(t *testing.T) {
 	test(`function a() { } var b = { a: a }; console.log(b.a);`, `function a() { } var b = { a: a }; console.log(b.a);`, t)
 }
+
+func TestEvalWarnings(t *testing.T) {
+	src := `function f(a) { var b = 1, c = 2; c; return eval(a); } function g() { var d; } f(); g();`
+	p, err := parser.ParseFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list diag.List
+	deadcode.EliminateWithOptions(p, deadcode.Options{Resolve: true, Reporter: &list})
+	if len(list) != 1 {
+		t.Fatalf("Expected 1 warning, got %+v", list)
+	}
+	w := list[0]
+	if w.Severity != diag.SeverityWarning || w.Code != "EvalKeepsUnused" || src[w.Idx-1:int(w.Idx)-1+w.Length] != "eval(a)" {
+		t.Errorf("Unexpected warning %+v", w)
+	}
+	if len(w.Related) != 1 || w.Related[0].Message != "'b' is never used otherwise" || src[w.Related[0].Idx-1] != 'b' {
+		t.Errorf("Unexpected related spans %+v", w.Related)
+	}
+}
+
+func TestFileHeader(t *testing.T) {
+	for in, want := range map[string]string{
+		"/*! MIT License */\nfunction unused() {}\nconsole.log(1);":          "/*! MIT License */\nconsole.log(1);\n",
+		"// Copyright\n\n// unused\nfunction unused() {}\nconsole.log(1);":   "// Copyright\nconsole.log(1);\n",
+		"/** @license MIT */ /* a */\nfunction unused() {}\nconsole.log(1);": "/** @license MIT */\nconsole.log(1);\n",
+	} {
+		p, err := parser.ParseFileWithOptions(in, parser.Options{Comments: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		deadcode.Eliminate(p, true)
+		if got := generator.Generate(p); got != want {
+			t.Errorf("dce(%q) = %q; want %q", in, got, want)
+		}
+	}
+}
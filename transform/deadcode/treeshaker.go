@@ -1,9 +1,13 @@
 package deadcode
 
 import (
+	"cmp"
+	"fmt"
+	"maps"
 	"slices"
 	"sync/atomic"
 
+	"github.com/t14raptor/go-fast/diag"
 	"github.com/t14raptor/go-fast/resolver"
 
 	"github.com/t14raptor/go-fast/ast"
@@ -12,10 +16,25 @@ import (
 	"github.com/t14raptor/go-fast/transform/internal/cfg"
 )
 
+// Options configures EliminateWithOptions.
+type Options struct {
+	// Resolve resolves the AST first.
+	Resolve bool
+	// Reporter receives warnings about code that could not be removed, such
+	// as unused bindings that a direct eval might reference. Nil discards
+	// them.
+	Reporter diag.Reporter
+}
+
 // Eliminate removes dead code from the AST.
 // If resolve is true, it will resolve the AST first.
 func Eliminate(p ast.VisitableNode, resolve bool) {
-	if resolve {
+	EliminateWithOptions(p, Options{Resolve: resolve})
+}
+
+// EliminateWithOptions is like Eliminate but configured by opts.
+func EliminateWithOptions(p ast.VisitableNode, opts Options) {
+	if opts.Resolve {
 		resolver.Resolve(p)
 	}
 
@@ -25,6 +44,52 @@ func Eliminate(p ast.VisitableNode, resolve bool) {
 		visitor.changed = false
 		p.VisitWith(visitor)
 	}
+
+	if opts.Reporter != nil {
+		visitor.reportKeptByEval(p, opts.Reporter)
+	}
+}
+
+// reportKeptByEval warns about every direct eval that kept unused bindings
+// in the last pass, which saw the final AST.
+func (ts *treeShaker) reportKeptByEval(p ast.VisitableNode, r diag.Reporter) {
+	if len(ts.data.keptByEval) == 0 {
+		return
+	}
+	idents := collectDeclaringIdents(p)
+	kept := map[*ast.CallExpression][]*ast.Identifier{}
+	for id, call := range ts.data.keptByEval {
+		if ident := idents[id]; ident != nil && call != nil {
+			kept[call] = append(kept[call], ident)
+		}
+	}
+	calls := slices.SortedFunc(maps.Keys(kept), func(a, b *ast.CallExpression) int {
+		return cmp.Compare(a.Idx0(), b.Idx0())
+	})
+	for _, call := range calls {
+		bindings := kept[call]
+		slices.SortFunc(bindings, func(a, b *ast.Identifier) int { return cmp.Compare(a.Idx, b.Idx) })
+		noun := "bindings"
+		if len(bindings) == 1 {
+			noun = "binding"
+		}
+		d := diag.Diagnostic{
+			Severity: diag.SeverityWarning,
+			Code:     "EvalKeepsUnused",
+			Message:  fmt.Sprintf("Direct eval keeps %d unused %s from being removed", len(bindings), noun),
+			Idx:      call.Idx0(),
+			Length:   int(call.Idx1() - call.Idx0()),
+			Hint:     "eval may reference any binding in its scope; an indirect call like (0, eval)(code) only sees globals",
+		}
+		for _, ident := range bindings {
+			d.Related = append(d.Related, diag.Span{
+				Idx:     ident.Idx,
+				Length:  len(ident.Name),
+				Message: fmt.Sprintf("'%s' is never used otherwise", ident.Name),
+			})
+		}
+		r.Report(d)
+	}
 }
 
 type treeShaker struct {
@@ -111,12 +176,19 @@ func (ts *treeShaker) VisitClassDeclaration(n *ast.ClassDeclaration) {
 			return
 		}
 
+		// Decorators are called when the class is defined.
+		if len(n.Class.Decorators) > 0 {
+			return
+		}
+
 		if slices.ContainsFunc(n.Class.Body, func(elem ast.ClassElement) bool {
 			switch elem := elem.Element.(type) {
 			case *ast.MethodDefinition:
-				return elem.Computed
+				return elem.Computed || len(elem.Decorators) > 0
 			case *ast.FieldDefinition:
-				return elem.Computed || (elem.Initializer != nil && ext.MayHaveSideEffects(elem.Initializer))
+				return elem.Computed || len(elem.Decorators) > 0 || (elem.Initializer != nil && ext.MayHaveSideEffects(elem.Initializer))
+			case *ast.ClassAccessorProperty:
+				return elem.Computed || len(elem.Decorators) > 0 || (elem.Initializer != nil && ext.MayHaveSideEffects(elem.Initializer))
 			case *ast.ClassStaticBlock:
 				return true
 			default:
@@ -154,7 +226,7 @@ func (ts *treeShaker) VisitExpression(n *ast.Expression) {
 func (ts *treeShaker) VisitStatement(n *ast.Statement) {
 	n.VisitChildrenWith(ts)
 
-	if varDecl, ok := n.Stmt.(*ast.VariableDeclaration); ok {
+	if varDecl, ok := n.Stmt.(*ast.VariableDeclaration); ok && !isUsing(varDecl) {
 		if len(varDecl.List) == 0 {
 			ts.remove.Store(true)
 		} else {
@@ -196,6 +268,10 @@ func (ts *treeShaker) VisitUnaryExpression(n *ast.UnaryExpression) {
 }
 
 func (ts *treeShaker) VisitVariableDeclaration(n *ast.VariableDeclaration) {
+	if isUsing(n) {
+		n.VisitChildrenWith(ts)
+		return
+	}
 	for i := len(n.List) - 1; i >= 0; i-- {
 		n.List[i].VisitWith(ts)
 
@@ -212,6 +288,12 @@ func (ts *treeShaker) VisitVariableDeclaration(n *ast.VariableDeclaration) {
 	}
 }
 
+// isUsing reports whether n is a using declaration, whose values are disposed
+// of at the end of the scope even if they are never referenced.
+func isUsing(n *ast.VariableDeclaration) bool {
+	return n.Token == token.Using || n.Token == token.AwaitUsing
+}
+
 func (ts *treeShaker) VisitProgram(n *ast.Program) {
 	if len(ts.bindings) == 0 {
 		ts.bindings = collectDeclarations(n)
@@ -221,6 +303,8 @@ func (ts *treeShaker) VisitProgram(n *ast.Program) {
 		usedNames: make(map[ast.Id]varInfo),
 		graph:     cfg.NewDirectedGraph[ast.Id, varInfo](),
 		entries:   make(map[ast.Id]struct{}),
+
+		keptByEval: make(map[ast.Id]*ast.CallExpression),
 	}
 
 	analyzer := &analyzer{
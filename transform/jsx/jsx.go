@@ -0,0 +1,331 @@
+// Package jsx lowers JSX elements and fragments to plain function calls, so
+// that the other transforms and the generator see regular JavaScript.
+package jsx
+
+import (
+	"strings"
+
+	"github.com/t14raptor/go-fast/ast"
+)
+
+// Runtime selects the calls JSX is lowered to.
+type Runtime int
+
+const (
+	// Classic lowers <a href={x}>b</a> to React.createElement("a", {href: x}, "b").
+	Classic Runtime = iota
+	// Automatic lowers <a href={x}>b</a> to _jsx("a", {href: x, children: "b"}),
+	// calling jsxs for elements with several children. Like Babel, it falls
+	// back to createElement for elements with a key after a spread prop.
+	Automatic
+)
+
+// Options configures Transform.
+type Options struct {
+	Runtime Runtime
+	// Pragma is the function called for elements with the classic runtime.
+	// The default is React.createElement.
+	Pragma string
+	// PragmaFrag is the component used for fragments with the classic
+	// runtime. The default is React.Fragment.
+	PragmaFrag string
+	// ImportSource is the module whose jsx-runtime the automatic runtime
+	// imports its helpers from, and which it imports createElement from. The
+	// default is react. The helpers are only imported into module programs;
+	// elsewhere _jsx, _jsxs, _Fragment and _createElement are expected to be
+	// in scope.
+	ImportSource string
+}
+
+// Transform replaces all JSX elements and fragments in p by function calls.
+func Transform(p ast.VisitableNode, opts Options) {
+	if opts.Pragma == "" {
+		opts.Pragma = "React.createElement"
+	}
+	if opts.PragmaFrag == "" {
+		opts.PragmaFrag = "React.Fragment"
+	}
+	if opts.ImportSource == "" {
+		opts.ImportSource = "react"
+	}
+
+	visitor := &transformer{opts: opts, used: map[string]bool{}}
+	visitor.V = visitor
+	p.VisitWith(visitor)
+
+	if program, ok := p.(*ast.Program); ok && program.SourceType == ast.SourceTypeModule {
+		visitor.addImport(program)
+	}
+}
+
+type transformer struct {
+	ast.NoopVisitor
+
+	opts Options
+	// Helpers of the automatic runtime that need to be imported.
+	used map[string]bool
+}
+
+func (t *transformer) VisitExpression(n *ast.Expression) {
+	switch expr := n.Expr.(type) {
+	case *ast.JSXElement:
+		n.Expr = t.element(expr)
+	case *ast.JSXFragment:
+		n.Expr = t.fragment(expr)
+	default:
+		n.VisitChildrenWith(t)
+	}
+}
+
+func (t *transformer) element(n *ast.JSXElement) ast.Expr {
+	// A key after a spread prop overrides the key of the spread, so it must
+	// stay in the props, which only createElement takes keys from.
+	createElement := t.opts.Runtime == Automatic && keyAfterSpread(n.Opening.Attributes)
+
+	var props ast.Properties
+	var key *ast.Expression
+	for _, attr := range n.Opening.Attributes {
+		switch attr := attr.Attribute.(type) {
+		case *ast.JSXSpreadAttribute:
+			attr.Argument.VisitWith(t)
+			props = append(props, ast.Property{Prop: &ast.SpreadElement{Expression: attr.Argument}})
+		case *ast.JSXAttribute:
+			value := t.attributeValue(attr)
+			if attr.Name.Name == "key" && t.opts.Runtime == Automatic && !createElement {
+				key = value
+				continue
+			}
+			props = append(props, ast.Property{Prop: &ast.PropertyKeyed{
+				Key:   propertyKey(attr.Name.Name),
+				Kind:  ast.PropertyKindValue,
+				Value: value,
+			}})
+		}
+	}
+	if createElement {
+		return createElementCall(t.helper("createElement"), t.elementType(n.Opening.Name), props, t.children(n.Children))
+	}
+	return t.call(t.elementType(n.Opening.Name), props, t.children(n.Children), key)
+}
+
+func keyAfterSpread(attrs ast.JSXAttributes) bool {
+	spread := false
+	for _, attr := range attrs {
+		switch attr := attr.Attribute.(type) {
+		case *ast.JSXSpreadAttribute:
+			spread = true
+		case *ast.JSXAttribute:
+			if spread && attr.Name.Name == "key" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (t *transformer) fragment(n *ast.JSXFragment) ast.Expr {
+	var typ ast.Expr
+	if t.opts.Runtime == Automatic {
+		typ = t.helper("Fragment")
+	} else {
+		typ = memberChain(t.opts.PragmaFrag)
+	}
+	return t.call(typ, nil, t.children(n.Children), nil)
+}
+
+// call creates the call for an element of type typ.
+func (t *transformer) call(typ ast.Expr, props ast.Properties, children ast.Expressions, key *ast.Expression) ast.Expr {
+	if t.opts.Runtime == Classic {
+		return createElementCall(memberChain(t.opts.Pragma), typ, props, children)
+	}
+
+	args := ast.Expressions{{Expr: typ}}
+	fn := "jsx"
+	switch {
+	case len(children) == 0:
+	case len(children) == 1 && !isSpread(children[0].Expr):
+		props = append(props, childrenProperty(children[0].Expr))
+	default:
+		fn = "jsxs"
+		props = append(props, childrenProperty(&ast.ArrayLiteral{Value: children}))
+	}
+	args = append(args, ast.Expression{Expr: &ast.ObjectLiteral{Value: props}})
+	if key != nil {
+		args = append(args, *key)
+	}
+	return &ast.CallExpression{
+		Callee:       &ast.Expression{Expr: t.helper(fn)},
+		ArgumentList: args,
+	}
+}
+
+// createElementCall creates a call of callee like React.createElement, with
+// the props, or null without any, followed by the children.
+func createElementCall(callee, typ ast.Expr, props ast.Properties, children ast.Expressions) ast.Expr {
+	args := ast.Expressions{{Expr: typ}}
+	if len(props) == 0 {
+		args = append(args, ast.Expression{Expr: &ast.NullLiteral{}})
+	} else {
+		args = append(args, ast.Expression{Expr: &ast.ObjectLiteral{Value: props}})
+	}
+	return &ast.CallExpression{
+		Callee:       &ast.Expression{Expr: callee},
+		ArgumentList: append(args, children...),
+	}
+}
+
+func isSpread(expr ast.Expr) bool {
+	_, ok := expr.(*ast.SpreadElement)
+	return ok
+}
+
+func childrenProperty(value ast.Expr) ast.Property {
+	return ast.Property{Prop: &ast.PropertyKeyed{
+		Key:   propertyKey("children"),
+		Kind:  ast.PropertyKindValue,
+		Value: &ast.Expression{Expr: value},
+	}}
+}
+
+// helper returns the local name of a helper of the automatic runtime.
+func (t *transformer) helper(name string) ast.Expr {
+	t.used[name] = true
+	return &ast.Identifier{Name: "_" + name}
+}
+
+// addImport imports the helpers of the automatic runtime that were used.
+func (t *transformer) addImport(program *ast.Program) {
+	var imports ast.Statements
+	for _, source := range []struct {
+		path  string
+		names []string
+	}{
+		{t.opts.ImportSource + "/jsx-runtime", []string{"jsx", "jsxs", "Fragment"}},
+		{t.opts.ImportSource, []string{"createElement"}},
+	} {
+		var specifiers ast.ImportSpecifiers
+		for _, name := range source.names {
+			if !t.used[name] {
+				continue
+			}
+			specifiers = append(specifiers, ast.ImportSpecifier{Specifier: &ast.ImportNamedSpecifier{
+				Imported: &ast.ModuleExportName{Name: &ast.Identifier{Name: name}},
+				Local:    &ast.Identifier{Name: "_" + name},
+			}})
+		}
+		if len(specifiers) == 0 {
+			continue
+		}
+		imports = append(imports, ast.Statement{Stmt: &ast.ImportDeclaration{
+			Specifiers: specifiers,
+			Source:     &ast.StringLiteral{Value: source.path},
+		}})
+	}
+	program.Body = append(imports, program.Body...)
+}
+
+// attributeValue returns the value of an attribute as an expression, which
+// is true for an attribute without a value.
+func (t *transformer) attributeValue(attr *ast.JSXAttribute) *ast.Expression {
+	if attr.Value == nil {
+		return &ast.Expression{Expr: &ast.BooleanLiteral{Value: true}}
+	}
+	switch value := attr.Value.Expr.(type) {
+	case *ast.StringLiteral:
+		// The raw text of an attribute string has no escapes in JSX.
+		return &ast.Expression{Expr: &ast.StringLiteral{Value: value.Value}}
+	case *ast.JSXExpressionContainer:
+		value.Expression.VisitWith(t)
+		return value.Expression
+	}
+	attr.Value.VisitWith(t)
+	return attr.Value
+}
+
+// children returns the arguments for the children of an element, dropping
+// empty expression containers and text that is only formatting.
+func (t *transformer) children(children ast.JSXChildren) ast.Expressions {
+	var args ast.Expressions
+	for _, child := range children {
+		switch child := child.Child.(type) {
+		case *ast.JSXText:
+			if text := cleanText(child.Value); text != "" {
+				args = append(args, ast.Expression{Expr: &ast.StringLiteral{Value: text}})
+			}
+		case *ast.JSXExpressionContainer:
+			if child.Expression != nil {
+				child.Expression.VisitWith(t)
+				args = append(args, *child.Expression)
+			}
+		case *ast.JSXElement:
+			args = append(args, ast.Expression{Expr: t.element(child)})
+		case *ast.JSXFragment:
+			args = append(args, ast.Expression{Expr: t.fragment(child)})
+		}
+	}
+	return args
+}
+
+// cleanText trims the whitespace around the lines of a text child and drops
+// empty lines, joining the rest with spaces. Whitespace without a line break
+// is kept, as in <b>a</b> <i>b</i>.
+func cleanText(text string) string {
+	lines := strings.Split(newlines.Replace(text), "\n")
+	var b strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			line = strings.TrimLeft(line, " \t")
+		}
+		if i < len(lines)-1 {
+			line = strings.TrimRight(line, " \t")
+		}
+		if line == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+var newlines = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// elementType returns the first argument for an element: a string for
+// intrinsic elements like div or svg:rect, and a reference otherwise.
+func (t *transformer) elementType(name *ast.JSXName) ast.Expr {
+	if strings.Contains(name.Name, ".") {
+		return memberChain(name.Name)
+	}
+	if name.Name != "" && 'a' <= name.Name[0] && name.Name[0] <= 'z' || strings.ContainsAny(name.Name, ":-") {
+		return &ast.StringLiteral{Value: name.Name}
+	}
+	return &ast.Identifier{Name: name.Name}
+}
+
+// memberChain turns a dotted name like React.createElement into an
+// identifier or member expression.
+func memberChain(name string) ast.Expr {
+	parts := strings.Split(name, ".")
+	var expr ast.Expr = &ast.Identifier{Name: parts[0]}
+	if parts[0] == "this" {
+		expr = &ast.ThisExpression{}
+	}
+	for _, part := range parts[1:] {
+		expr = &ast.MemberExpression{
+			Object:   &ast.Expression{Expr: expr},
+			Property: &ast.MemberProperty{Prop: &ast.Identifier{Name: part}},
+		}
+	}
+	return expr
+}
+
+// propertyKey returns the key for a prop, which is a string unless the
+// attribute name is a valid identifier.
+func propertyKey(name string) *ast.Expression {
+	if strings.ContainsAny(name, ":-") {
+		return &ast.Expression{Expr: &ast.StringLiteral{Value: name}}
+	}
+	return &ast.Expression{Expr: &ast.Identifier{Name: name}}
+}
@@ -0,0 +1,80 @@
+package jsx_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/t14raptor/go-fast/ast"
+	"github.com/t14raptor/go-fast/generator"
+	"github.com/t14raptor/go-fast/parser"
+	"github.com/t14raptor/go-fast/transform/jsx"
+)
+
+func lower(in string, sourceType ast.SourceType, opts jsx.Options) (string, error) {
+	p, err := parser.ParseFileWithOptions(in, parser.Options{JSX: true, SourceType: sourceType})
+	if err != nil {
+		return "", err
+	}
+	jsx.Transform(p, opts)
+	return generator.Generate(p), nil
+}
+
+func test(in, want string, opts jsx.Options, t *testing.T) {
+	got, err := lower(in, ast.SourceTypeScript, opts)
+	if err != nil {
+		t.Errorf("lower('%s') failed: %v", in, err)
+		return
+	}
+	got = strings.TrimSuffix(strings.TrimSpace(got), ";")
+	got = regexp.MustCompile(`\s+`).ReplaceAllString(got, " ")
+	if got != want {
+		t.Errorf("lower('%s') = '%s'; want '%s'", in, got, want)
+	}
+}
+
+func TestClassic(t *testing.T) {
+	opts := jsx.Options{}
+	test(`<div />`, `React.createElement("div", null)`, opts, t)
+	test(`<Foo a="1" b={2} c />`, `React.createElement(Foo, { a: "1", b: 2, c: true })`, opts, t)
+	test(`<a.b data-x="y" {...p} />`, `React.createElement(a.b, { "data-x": "y", ...p })`, opts, t)
+	test(`<svg:rect />`, `React.createElement("svg:rect", null)`, opts, t)
+	test(`<this.Foo />`, `React.createElement(this.Foo, null)`, opts, t)
+	test(`<p>Hello, {name}!</p>`, `React.createElement("p", null, "Hello, ", name, "!")`, opts, t)
+	test(`<>a{}<b /></>`, `React.createElement(React.Fragment, null, "a", React.createElement("b", null))`, opts, t)
+	test(`<p>{<i />}</p>`, `React.createElement("p", null, React.createElement("i", null))`, opts, t)
+	test("<p>\n  multi\n  line &amp;\n  text\n</p>", `React.createElement("p", null, "multi line & text")`, opts, t)
+	test("<p>\n  <b />\n  <i />\n</p>", `React.createElement("p", null, React.createElement("b", null), React.createElement("i", null))`, opts, t)
+	test(`<p><b /> <i /></p>`, `React.createElement("p", null, React.createElement("b", null), " ", React.createElement("i", null))`, opts, t)
+
+	opts = jsx.Options{Pragma: "h", PragmaFrag: "Fragment"}
+	test(`<><a /></>`, `h(Fragment, null, h("a", null))`, opts, t)
+}
+
+func TestAutomatic(t *testing.T) {
+	opts := jsx.Options{Runtime: jsx.Automatic}
+	test(`<div />`, `_jsx("div", {})`, opts, t)
+	test(`<div id="a">b</div>`, `_jsx("div", { id: "a", children: "b" })`, opts, t)
+	test(`<div key={k}>b{c}</div>`, `_jsxs("div", { children: ["b", c] }, k)`, opts, t)
+	test(`<>{...c}</>`, `_jsxs(_Fragment, { children: [...c] })`, opts, t)
+	test(`<a key="k" {...p} />`, `_jsx("a", { ...p }, "k")`, opts, t)
+	// A key after a spread overrides the key of the spread, as Babel keeps it.
+	test(`<a {...p} key="k" />`, `_createElement("a", { ...p, key: "k" })`, opts, t)
+	test(`<a {...p} key="k">b{c}</a>`, `_createElement("a", { ...p, key: "k" }, "b", c)`, opts, t)
+
+	got, err := lower(`export default () => <><a /></>`, ast.SourceTypeModule, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `import {jsx as _jsx, Fragment as _Fragment} from "react/jsx-runtime";`; !strings.HasPrefix(got, want) {
+		t.Errorf("Expected import %s, got %s", want, got)
+	}
+
+	got, err = lower(`export default <><a {...p} key="k" /></>`, ast.SourceTypeModule, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `import {jsx as _jsx, Fragment as _Fragment} from "react/jsx-runtime"; import {createElement as _createElement} from "react";`; !strings.HasPrefix(strings.Join(strings.Fields(got), " "), want) {
+		t.Errorf("Expected imports %s, got %s", want, got)
+	}
+}
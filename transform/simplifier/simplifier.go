@@ -3,6 +3,7 @@ package simplifier
 import (
 	"fmt"
 	"math"
+	"math/big"
 	"slices"
 	"strconv"
 	"strings"
@@ -14,6 +15,7 @@ import (
 	"github.com/nukilabs/unicodeid"
 	"github.com/t14raptor/go-fast/ast"
 	"github.com/t14raptor/go-fast/ast/ext"
+	"github.com/t14raptor/go-fast/regexp"
 	"github.com/t14raptor/go-fast/token"
 )
 
@@ -66,6 +68,13 @@ type simplifier struct {
 	isArgOfUpdate bool
 	isModifying   bool
 	inCallee      bool
+
+	// inEvalScope is set in sloppy mode functions that call eval directly, and
+	// in the functions nested in them. The eval may declare variables there
+	// which shadow undefined, NaN or Infinity, so globalRefs counts the
+	// references to them, and expressions containing one are not folded.
+	inEvalScope bool
+	globalRefs  int
 }
 
 func (s *simplifier) optimizeMemberExpression(expr *ast.Expression) {
@@ -168,7 +177,7 @@ func (s *simplifier) optimizeMemberExpression(expr *ast.Expression) {
 		// 'foo'['']
 		case IndexStr:
 			if !ext.IsStringSymbol(string(op)) {
-				expr.Expr = &ast.Identifier{Idx: memExpr.Idx0(), Name: "undefined"}
+				expr.Expr = s.undefinedExpr(memExpr.Idx0())
 			}
 		}
 
@@ -272,7 +281,7 @@ func (s *simplifier) optimizeMemberExpression(expr *ast.Expression) {
 
 		case IndexStr:
 			if len(obj.Value) == 0 && !ext.IsArraySymbol(string(op)) {
-				expr.Expr = &ast.Identifier{Idx: memExpr.Idx0(), Name: "undefined"}
+				expr.Expr = s.undefinedExpr(memExpr.Idx0())
 			}
 		}
 
@@ -317,13 +326,18 @@ func (s *simplifier) optimizeBinaryExpression(expr *ast.Expression) {
 		s.changed = true
 		expr.Expr = makeBoolExpr(v, []ast.Expression{{Expr: left.Expr}, {Expr: right.Expr}}).Expr
 	}
+	tryReplaceBigInt := func(v *big.Int, left, right *ast.Expression) {
+		s.changed = true
+		value := &ast.BigIntLiteral{Idx: binExpr.Idx0(), Value: v}
+		expr.Expr = ext.PreserveEffects(ast.Expression{Expr: value}, []ast.Expression{{Expr: left.Expr}, {Expr: right.Expr}}).Expr
+	}
 	tryReplaceNum := func(v float64, left, right *ast.Expression) {
 		s.changed = true
 		var value ast.Expr
 		if !math.IsNaN(v) {
 			value = &ast.NumberLiteral{Idx: binExpr.Idx0(), Value: v}
 		} else {
-			value = &ast.Identifier{Idx: binExpr.Idx0(), Name: "NaN"}
+			value = s.nanExpr(binExpr.Idx0())
 		}
 		expr.Expr = ext.PreserveEffects(ast.Expression{Expr: value}, []ast.Expression{{Expr: left.Expr}, {Expr: right.Expr}}).Expr
 	}
@@ -355,6 +369,11 @@ func (s *simplifier) optimizeBinaryExpression(expr *ast.Expression) {
 					expr.Expr = &ast.StringLiteral{Idx: binExpr.Idx0(), Value: l.Val() + r.Val()}
 				}
 			}
+		// BigInt calculation
+		case ext.BigIntType:
+			if v := s.performBigIntArithmeticOp(token.Plus, binExpr.Left, binExpr.Right); v.Known() {
+				tryReplaceBigInt(v.Val(), binExpr.Left, binExpr.Right)
+			}
 		// Numerical calculation
 		case ext.BoolType, ext.NullType, ext.NumberType, ext.UndefinedType:
 			if v := s.performArithmeticOp(token.Plus, binExpr.Left, binExpr.Right); v.Known() {
@@ -426,10 +445,23 @@ func (s *simplifier) optimizeBinaryExpression(expr *ast.Expression) {
 			expr.Expr = makeBoolExpr(true, []ast.Expression{{Expr: binExpr.Left.Expr}}).Expr
 		}
 	case token.Minus, token.Slash, token.Remainder, token.Exponent:
+		// BigInt operands must never reach the float64 folding below.
+		if ext.IsBigInt(binExpr.Left) || ext.IsBigInt(binExpr.Right) {
+			if v := s.performBigIntArithmeticOp(binExpr.Operator, binExpr.Left, binExpr.Right); v.Known() {
+				tryReplaceBigInt(v.Val(), binExpr.Left, binExpr.Right)
+			}
+			return
+		}
 		if v := s.performArithmeticOp(binExpr.Operator, binExpr.Left, binExpr.Right); v.Known() {
 			tryReplaceNum(v.Val(), binExpr.Left, binExpr.Right)
 		}
 	case token.ShiftLeft, token.ShiftRight, token.UnsignedShiftRight:
+		if ext.IsBigInt(binExpr.Left) || ext.IsBigInt(binExpr.Right) {
+			if v := s.performBigIntArithmeticOp(binExpr.Operator, binExpr.Left, binExpr.Right); v.Known() {
+				tryReplaceBigInt(v.Val(), binExpr.Left, binExpr.Right)
+			}
+			return
+		}
 		tryFoldShift := func(op token.Token, left, right *ast.Expression) (float64, bool) {
 			if _, ok := left.Expr.(*ast.NumberLiteral); !ok {
 				return 0, false
@@ -463,6 +495,12 @@ func (s *simplifier) optimizeBinaryExpression(expr *ast.Expression) {
 	//
 	// (a * 1) * 2 --> a * (1 * 2) --> a * 2
 	case token.Multiply, token.And, token.Or, token.ExclusiveOr:
+		if ext.IsBigInt(binExpr.Left) || ext.IsBigInt(binExpr.Right) {
+			if v := s.performBigIntArithmeticOp(binExpr.Operator, binExpr.Left, binExpr.Right); v.Known() {
+				tryReplaceBigInt(v.Val(), binExpr.Left, binExpr.Right)
+			}
+			return
+		}
 		if v := s.performArithmeticOp(binExpr.Operator, binExpr.Left, binExpr.Right); v.Known() {
 			tryReplaceNum(v.Val(), binExpr.Left, binExpr.Right)
 		}
@@ -474,7 +512,7 @@ func (s *simplifier) optimizeBinaryExpression(expr *ast.Expression) {
 				if !math.IsNaN(v.Val()) {
 					valExpr = &ast.NumberLiteral{Idx: binExpr.Idx0(), Value: v.Val()}
 				} else {
-					valExpr = &ast.Identifier{Idx: binExpr.Idx0(), Name: "NaN"}
+					valExpr = s.nanExpr(binExpr.Idx0())
 				}
 				s.changed = true
 				binExpr.Left.Expr = binExpr2.Left.Expr
@@ -528,6 +566,8 @@ func (s *simplifier) tryFoldTypeOf(expr *ast.Expression) {
 			val = "string"
 		case *ast.NumberLiteral:
 			val = "number"
+		case *ast.BigIntLiteral:
+			val = "bigint"
 		case *ast.BooleanLiteral:
 			val = "boolean"
 		case *ast.NullLiteral, *ast.ObjectLiteral, *ast.ArrayLiteral:
@@ -552,6 +592,80 @@ func (s *simplifier) tryFoldTypeOf(expr *ast.Expression) {
 	}
 }
 
+// tryFoldRegExpTest folds calls like /abc/.test("xabc") whose pattern only
+// matches a fixed string, optionally anchored with ^ and $.
+func (s *simplifier) tryFoldRegExpTest(expr *ast.Expression) {
+	call := expr.Expr.(*ast.CallExpression)
+	member, ok := call.Callee.Expr.(*ast.MemberExpression)
+	if !ok || len(call.ArgumentList) != 1 {
+		return
+	}
+	lit, ok := member.Object.Expr.(*ast.RegExpLiteral)
+	if !ok {
+		return
+	}
+	if prop, ok := member.Property.Prop.(*ast.Identifier); !ok || prop.Name != "test" {
+		return
+	}
+	str, ok := call.ArgumentList[0].Expr.(*ast.StringLiteral)
+	if !ok || !utf8.ValidString(str.Value) {
+		return
+	}
+	flags, err := regexp.ParseFlags(lit.Flags)
+	if err != nil || flags.IgnoreCase {
+		return
+	}
+	pattern, err := regexp.Parse(lit.Pattern, flags)
+	if err != nil || len(pattern.Body.Alternatives) != 1 {
+		return
+	}
+
+	terms := pattern.Body.Alternatives[0].Terms
+	// Sticky patterns only match at the start, as lastIndex is 0 for a
+	// literal.
+	start, end := flags.Sticky, false
+	if len(terms) > 0 {
+		if a, ok := terms[0].(*regexp.Assertion); ok && a.Kind == regexp.Start {
+			start, terms = true, terms[1:]
+		}
+	}
+	if len(terms) > 0 {
+		if a, ok := terms[len(terms)-1].(*regexp.Assertion); ok && a.Kind == regexp.End {
+			end, terms = true, terms[:len(terms)-1]
+		}
+	}
+	if flags.Multiline && (start || end) {
+		return
+	}
+	var units []uint16
+	for _, term := range terms {
+		chr, ok := term.(*regexp.Character)
+		if !ok {
+			return
+		}
+		units = utf16.AppendRune(units, chr.Value)
+	}
+	want := string(utf16.Decode(units))
+	if strings.ContainsRune(want, utf8.RuneError) {
+		// The pattern has lone surrogates.
+		return
+	}
+
+	var match bool
+	switch {
+	case start && end:
+		match = str.Value == want
+	case start:
+		match = strings.HasPrefix(str.Value, want)
+	case end:
+		match = strings.HasSuffix(str.Value, want)
+	default:
+		match = strings.Contains(str.Value, want)
+	}
+	s.changed = true
+	expr.Expr = &ast.BooleanLiteral{Value: match}
+}
+
 func (s *simplifier) optimizeUnaryExpression(expr *ast.Expression) {
 	unaryExpr, ok := expr.Expr.(*ast.UnaryExpression)
 	if !ok {
@@ -583,7 +697,7 @@ func (s *simplifier) optimizeUnaryExpression(expr *ast.Expression) {
 		if val := ext.AsPureNumber(unaryExpr.Operand); val.Known() {
 			s.changed = true
 			if math.IsNaN(val.Val()) {
-				expr.Expr = ext.PreserveEffects(ast.Expression{Expr: &ast.Identifier{Idx: unaryExpr.Idx, Name: "NaN"}}, []ast.Expression{{Expr: unaryExpr.Operand.Expr}}).Expr
+				expr.Expr = ext.PreserveEffects(ast.Expression{Expr: s.nanExpr(unaryExpr.Idx)}, []ast.Expression{{Expr: unaryExpr.Operand.Expr}}).Expr
 				return
 			}
 			expr.Expr = ext.PreserveEffects(ast.Expression{Expr: &ast.NumberLiteral{Idx: unaryExpr.Idx, Value: val.Val()}}, []ast.Expression{{Expr: unaryExpr.Operand.Expr}}).Expr
@@ -600,6 +714,9 @@ func (s *simplifier) optimizeUnaryExpression(expr *ast.Expression) {
 		case *ast.NumberLiteral:
 			s.changed = true
 			expr.Expr = &ast.NumberLiteral{Idx: operand.Idx, Value: -operand.Value}
+		case *ast.BigIntLiteral:
+			s.changed = true
+			expr.Expr = &ast.BigIntLiteral{Idx: operand.Idx, Value: new(big.Int).Neg(operand.Value)}
 		}
 		// TODO: Report that user is something bad (negating
 		// non-number value)
@@ -613,6 +730,11 @@ func (s *simplifier) optimizeUnaryExpression(expr *ast.Expression) {
 			unaryExpr.Operand.Expr = &ast.NumberLiteral{Idx: unaryExpr.Operand.Expr.Idx0(), Value: 0.0}
 		}
 	case token.BitwiseNot:
+		if val := ext.AsPureBigInt(unaryExpr.Operand); val.Known() {
+			s.changed = true
+			expr.Expr = &ast.BigIntLiteral{Idx: unaryExpr.Idx, Value: new(big.Int).Not(val.Val())}
+			return
+		}
 		if val := ext.AsPureNumber(unaryExpr.Operand); val.Known() {
 			if _, frac := math.Modf(val.Val()); frac == 0.0 {
 				s.changed = true
@@ -732,6 +854,91 @@ func (s *simplifier) performArithmeticOp(op token.Token, left, right *ast.Expres
 	return ext.Unknown[float64]()
 }
 
+// maxBigIntFoldBits bounds the size of folded BigInt values, so that
+// expressions like 2n ** 100000000n are left for the runtime.
+const maxBigIntFoldBits = 1 << 16
+
+func (s *simplifier) performBigIntArithmeticOp(op token.Token, left, right *ast.Expression) ext.Value[*big.Int] {
+	// Replace only if it becomes shorter
+	tryReplace := func(v *big.Int) ext.Value[*big.Int] {
+		newLen := len(v.String()) + 1
+		if right.Expr.Idx1() > left.Expr.Idx0() {
+			origLen := right.Expr.Idx1() - right.Expr.Idx0() + left.Expr.Idx1() - left.Expr.Idx0()
+			if newLen <= int(origLen)+1 {
+				return ext.Known(v)
+			} else {
+				return ext.Unknown[*big.Int]()
+			}
+		} else {
+			return ext.Known(v)
+		}
+	}
+
+	// Mixing BigInt and other types throws a TypeError at runtime.
+	lv := ext.AsPureBigInt(left)
+	rv := ext.AsPureBigInt(right)
+	if lv.Unknown() || rv.Unknown() {
+		return ext.Unknown[*big.Int]()
+	}
+	l, r := lv.Val(), rv.Val()
+
+	switch op {
+	case token.Plus:
+		return tryReplace(new(big.Int).Add(l, r))
+	case token.Minus:
+		return tryReplace(new(big.Int).Sub(l, r))
+	case token.Multiply:
+		return tryReplace(new(big.Int).Mul(l, r))
+	case token.Slash:
+		// Division by zero throws a RangeError.
+		if r.Sign() == 0 {
+			return ext.Unknown[*big.Int]()
+		}
+		return tryReplace(new(big.Int).Quo(l, r))
+	case token.Remainder:
+		if r.Sign() == 0 {
+			return ext.Unknown[*big.Int]()
+		}
+		return tryReplace(new(big.Int).Rem(l, r))
+	case token.Exponent:
+		// Negative exponents throw a RangeError.
+		if r.Sign() < 0 || !r.IsInt64() || int64(l.BitLen())*r.Int64() > maxBigIntFoldBits {
+			return ext.Unknown[*big.Int]()
+		}
+		return tryReplace(new(big.Int).Exp(l, r, nil))
+	case token.And:
+		return tryReplace(new(big.Int).And(l, r))
+	case token.Or:
+		return tryReplace(new(big.Int).Or(l, r))
+	case token.ExclusiveOr:
+		return tryReplace(new(big.Int).Xor(l, r))
+	case token.ShiftLeft, token.ShiftRight:
+		if !r.IsInt64() {
+			return ext.Unknown[*big.Int]()
+		}
+		n := r.Int64()
+		if op == token.ShiftRight {
+			n = -n
+		}
+		if n >= 0 {
+			if int64(l.BitLen())+n > maxBigIntFoldBits {
+				return ext.Unknown[*big.Int]()
+			}
+			return tryReplace(new(big.Int).Lsh(l, uint(n)))
+		}
+		if -n > maxBigIntFoldBits {
+			// Everything has been shifted out.
+			if l.Sign() < 0 {
+				return tryReplace(big.NewInt(-1))
+			}
+			return tryReplace(new(big.Int))
+		}
+		return tryReplace(new(big.Int).Rsh(l, uint(-n)))
+	}
+	// Notably `>>>` which is a TypeError for BigInt.
+	return ext.Unknown[*big.Int]()
+}
+
 func (s *simplifier) performAbstractRelCmp(left, right *ast.Expression, willNegate bool) ext.BoolValue {
 	// Special case: `x < x` is always false.
 	if l, ok := left.Expr.(*ast.Identifier); ok {
@@ -771,6 +978,15 @@ func (s *simplifier) performAbstractRelCmp(left, right *ast.Expression, willNega
 		}
 	}
 
+	if lt.Value == ext.Known[ext.Type](ext.BigIntType{}) && rt.Value == ext.Known[ext.Type](ext.BigIntType{}) {
+		lv := ext.AsPureBigInt(left)
+		rv := ext.AsPureBigInt(right)
+		if lv.Known() && rv.Known() {
+			return ext.BoolValue{Value: ext.Known(lv.Val().Cmp(rv.Val()) < 0)}
+		}
+		return ext.BoolValue{Value: ext.Unknown[bool]()}
+	}
+
 	// Then, try to evaluate based on the value of the node. Try comparing as
 	// numbers.
 	lv := ext.AsPureNumber(left)
@@ -799,6 +1015,10 @@ func (s *simplifier) performAbstractEqCmp(left, right *ast.Expression) ext.BoolV
 	if (lt.Val() == ext.NullType{} && rt.Val() == ext.UndefinedType{}) || (lt.Val() == ext.UndefinedType{} && rt.Val() == ext.NullType{}) {
 		return ext.BoolValue{Value: ext.Known(true)}
 	}
+	// BigInt compares mathematically with numbers and numeric strings.
+	if (lt.Val() == ext.BigIntType{}) || (rt.Val() == ext.BigIntType{}) {
+		return ext.BoolValue{Value: ext.Unknown[bool]()}
+	}
 	if (lt.Val() == ext.NumberType{} && rt.Val() == ext.StringType{}) || (rt.Val() == ext.BoolType{}) {
 		rv := ext.AsPureNumber(right)
 		if rv.Unknown() {
@@ -857,6 +1077,13 @@ func (s *simplifier) performStrictEqCmp(left, right *ast.Expression) ext.BoolVal
 			return ext.BoolValue{Value: ext.Unknown[bool]()}
 		}
 		return ext.BoolValue{Value: ext.Known(lv.Val() == rv.Val())}
+	case ext.BigIntType:
+		lv := ext.AsPureBigInt(left)
+		rv := ext.AsPureBigInt(right)
+		if lv.Unknown() || rv.Unknown() {
+			return ext.BoolValue{Value: ext.Unknown[bool]()}
+		}
+		return ext.BoolValue{Value: ext.Known(lv.Val().Cmp(rv.Val()) == 0)}
 	case ext.StringType:
 		lv := ext.AsPureString(left)
 		rv := ext.AsPureString(right)
@@ -940,17 +1167,21 @@ func (s *simplifier) VisitExpression(n *ast.Expression) {
 		return
 	}
 	// fold children before doing something more.
+	refs := s.globalRefs
 	n.VisitChildrenWith(s)
+	if s.inEvalScope && s.globalRefs != refs {
+		return
+	}
 
 	switch expr := n.Expr.(type) {
 	// Do nothing.
-	case *ast.StringLiteral, *ast.BooleanLiteral, *ast.NullLiteral, *ast.NumberLiteral, *ast.RegExpLiteral, *ast.ThisExpression:
+	case *ast.StringLiteral, *ast.BooleanLiteral, *ast.NullLiteral, *ast.NumberLiteral, *ast.BigIntLiteral, *ast.RegExpLiteral, *ast.ThisExpression:
 		return
 	case *ast.SequenceExpression:
 		if len(expr.Sequence) == 0 {
 			return
 		}
-	case *ast.UnaryExpression, *ast.BinaryExpression, *ast.MemberExpression, *ast.ConditionalExpression, *ast.ArrayLiteral, *ast.ObjectLiteral, *ast.NewExpression:
+	case *ast.UnaryExpression, *ast.BinaryExpression, *ast.MemberExpression, *ast.ConditionalExpression, *ast.ArrayLiteral, *ast.ObjectLiteral, *ast.NewExpression, *ast.CallExpression:
 	default:
 		return
 	}
@@ -962,6 +1193,8 @@ func (s *simplifier) VisitExpression(n *ast.Expression) {
 		s.optimizeBinaryExpression(n)
 	case *ast.MemberExpression:
 		s.optimizeMemberExpression(n)
+	case *ast.CallExpression:
+		s.tryFoldRegExpTest(n)
 	case *ast.ConditionalExpression:
 		if v, pure := ext.CastToBool(expr.Test); v.Known() {
 			s.changed = true
@@ -1098,7 +1331,7 @@ func (s *simplifier) VisitSequenceExpression(n *ast.SequenceExpression) {
 		}
 		if s.inCallee && !ext.MayHaveSideEffects(&expr) {
 			switch expr.Expr.(type) {
-			case *ast.StringLiteral, *ast.BooleanLiteral, *ast.NullLiteral, *ast.NumberLiteral, *ast.RegExpLiteral, *ast.Identifier:
+			case *ast.StringLiteral, *ast.BooleanLiteral, *ast.NullLiteral, *ast.NumberLiteral, *ast.BigIntLiteral, *ast.RegExpLiteral, *ast.Identifier:
 				if len(exprs) == 0 {
 					s.changed = true
 					exprs = append(exprs, ast.Expression{Expr: &ast.NumberLiteral{Value: 0.0}})
@@ -1108,7 +1341,7 @@ func (s *simplifier) VisitSequenceExpression(n *ast.SequenceExpression) {
 		}
 		// Drop side effect free nodes.
 		switch expr.Expr.(type) {
-		case *ast.StringLiteral, *ast.BooleanLiteral, *ast.NullLiteral, *ast.NumberLiteral, *ast.RegExpLiteral, *ast.Identifier:
+		case *ast.StringLiteral, *ast.BooleanLiteral, *ast.NullLiteral, *ast.NumberLiteral, *ast.BigIntLiteral, *ast.RegExpLiteral, *ast.Identifier:
 			continue
 		}
 		// Flatten array
@@ -1183,6 +1416,47 @@ func (s *simplifier) VisitWithStatement(n *ast.WithStatement) {
 	n.Object.VisitWith(s)
 }
 
+func (s *simplifier) VisitIdentifier(n *ast.Identifier) {
+	if s.inEvalScope && (n.Name == "undefined" || n.Name == "NaN" || n.Name == "Infinity") {
+		s.globalRefs++
+	}
+}
+
+func (s *simplifier) VisitFunctionLiteral(n *ast.FunctionLiteral) {
+	old := s.inEvalScope
+	s.inEvalScope = old || !n.Strict && containsDirectEval(n)
+	n.VisitChildrenWith(s)
+	s.inEvalScope = old
+}
+
+func (s *simplifier) VisitArrowFunctionLiteral(n *ast.ArrowFunctionLiteral) {
+	old := s.inEvalScope
+	s.inEvalScope = old || !n.Strict && containsDirectEval(n)
+	n.VisitChildrenWith(s)
+	s.inEvalScope = old
+}
+
+// undefinedExpr returns undefined, written as void 0 where a direct eval may
+// shadow it.
+func (s *simplifier) undefinedExpr(idx ast.Idx) ast.Expr {
+	if s.inEvalScope {
+		return &ast.UnaryExpression{Idx: idx, Operator: token.Void, Operand: &ast.Expression{Expr: &ast.NumberLiteral{Idx: idx, Value: 0}}}
+	}
+	return &ast.Identifier{Idx: idx, Name: "undefined"}
+}
+
+// nanExpr returns NaN, written as 0 / 0 where a direct eval may shadow it.
+func (s *simplifier) nanExpr(idx ast.Idx) ast.Expr {
+	if s.inEvalScope {
+		return &ast.BinaryExpression{
+			Operator: token.Slash,
+			Left:     &ast.Expression{Expr: &ast.NumberLiteral{Idx: idx, Value: 0}},
+			Right:    &ast.Expression{Expr: &ast.NumberLiteral{Idx: idx, Value: 0}},
+		}
+	}
+	return &ast.Identifier{Idx: idx, Name: "NaN"}
+}
+
 // Simplify simplifies the AST by optimizing expressions.
 // By default, it is expected that the AST is already resolved.
 func Simplify(p ast.VisitableNode, resolve bool) {
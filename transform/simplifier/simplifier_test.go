@@ -893,6 +893,22 @@ func TestFoldStringLength(t *testing.T) {
 	fold("x = '123\\u01dc'.length", "x = 4", t)
 }
 
+func TestFoldRegExpTest(t *testing.T) {
+	fold(`x = /abc/.test("xabcx")`, "x = true", t)
+	fold(`x = /abc/.test("ab")`, "x = false", t)
+	fold(`x = /^ab/.test("abc")`, "x = true", t)
+	fold(`x = /bc$/.test("abc")`, "x = true", t)
+	fold(`x = /^b$/.test("abc")`, "x = false", t)
+	fold(`x = /\x61\u{1F600}/u.test("a😀")`, "x = true", t)
+	fold(`x = /b/y.test("abc")`, "x = false", t)
+
+	// Cannot fold
+	fold(`x = /a/i.test("A")`, `x = /a/i.test("A")`, t)
+	fold(`x = /^a/m.test("b\na")`, `x = /^a/m.test("b\na")`, t)
+	fold(`x = /a+/.test("a")`, `x = /a+/.test("a")`, t)
+	fold(`x = /a/.test(y)`, `x = /a/.test(y)`, t)
+}
+
 func TestFoldTypeof(t *testing.T) {
 	fold("x = typeof 1", "x = \"number\"", t)
 	fold("x = typeof 'foo'", "x = \"string\"", t)
@@ -1105,3 +1121,70 @@ func TestCompPropToIdent(t *testing.T) {
 	fold("a['@@lol'](1)", "a['@@lol'](1)", t)
 	fold("a['']()", "a['']()", t)
 }
+
+func TestFoldBigInt(t *testing.T) {
+	fold("x = 1n + 2n", "x = 3n", t)
+	fold("x = 10n - 3n", "x = 7n", t)
+	fold("x = 6n * 7n", "x = 42n", t)
+	fold("x = 7n / 2n", "x = 3n", t)
+	fold("x = -7n / 2n", "x = -3n", t)
+	fold("x = -7n % 2n", "x = -1n", t)
+	fold("x = 2n ** 10n", "x = 1024n", t)
+	fold("x = 5n & 3n", "x = 1n", t)
+	fold("x = 5n | 3n", "x = 7n", t)
+	fold("x = 5n ^ 3n", "x = 6n", t)
+	fold("x = -1n & 255n", "x = 255n", t)
+	fold("x = 1n << 4n", "x = 16n", t)
+	fold("x = -9n >> 1n", "x = -5n", t)
+	fold("x = 0x10n + 0n", "x = 16n", t)
+	fold("x = 9007199254740993n * 1n", "x = 9007199254740993n", t)
+	fold("x = -(5n)", "x = -5n", t)
+	fold("x = ~5n", "x = -6n", t)
+
+	// Never fold what throws at runtime.
+	fold("x = 1n / 0n", "x = 1n / 0n", t)
+	fold("x = 1n % 0n", "x = 1n % 0n", t)
+	fold("x = 2n ** -1n", "x = 2n ** -1n", t)
+	fold("x = 1n >>> 0n", "x = 1n >>> 0n", t)
+	fold("x = 1n + 1", "x = 1n + 1", t)
+	fold("x = 1n * 1", "x = 1n * 1", t)
+	fold("x = 1n ** 0", "x = 1n ** 0", t)
+	fold("x = +1n", "x = +1n", t)
+
+	fold("x = 1n < 2n", "x = true", t)
+	fold("x = 2n <= 1n", "x = false", t)
+	fold("x = 1n === 1n", "x = true", t)
+	fold("x = 1n === 1", "x = false", t)
+	fold("x = 1n == 1n", "x = true", t)
+	fold("x = 1n == 1", "x = 1n == 1", t)
+	fold("x = !0n", "x = true", t)
+	fold("x = typeof 1n", "x = \"bigint\"", t)
+	fold("x = 1n + \"\"", "x = \"1\"", t)
+}
+
+func TestFoldLogicalAssign(t *testing.T) {
+	fold("x ||= 1", "x ||= 1", t)
+	fold("x &&= y", "x &&= y", t)
+	fold("x ??= 1", "x ??= 1", t)
+	fold("y = !(x ||= 1)", "y = (x ||= 1, false)", t)
+	fold("y = !(x &&= 0)", "y = (x &&= 0, true)", t)
+	fold("y = !(x ||= 0)", "y = !(x ||= 0)", t)
+	fold("y = !(x &&= 1)", "y = !(x &&= 1)", t)
+	fold("y = !(x ??= 1)", "y = !(x ??= 1)", t)
+	fold("y = !(x += 1)", "y = !(x += 1)", t)
+}
+
+func TestDirectEval(t *testing.T) {
+	fold("function f() { eval(s); return typeof undefined; }", "function f() { eval(s); return typeof undefined; }", t)
+	fold("function f() { (0, eval)(s); return typeof undefined; }", "function f() { (0, eval)(s); return \"undefined\"; }", t)
+	fold("function f() { 'use strict'; eval(s); return typeof undefined; }", "function f() { 'use strict'; eval(s); return \"undefined\"; }", t)
+	fold("function f() { g(() => eval(s)); return typeof undefined; }", "function f() { g(() => eval(s)); return \"undefined\"; }", t)
+
+	// Constants still fold next to the eval, as long as they don't refer to
+	// names it may shadow.
+	fold("function f() { eval(s); return 1 + 2; }", "function f() { eval(s); return 3; }", t)
+	fold("function f() { eval(s); return [undefined, 1 + 2]; }", "function f() { eval(s); return [undefined, 3]; }", t)
+	fold("function f() { eval(s); return () => 'a' + 'b' + NaN; }", "function f() { eval(s); return () => \"ab\" + NaN; }", t)
+	fold("function f() { eval(s); return [].foo; }", "function f() { eval(s); return void 0; }", t)
+	fold("function f() { eval(s); return 1 - 'a'; }", "function f() { eval(s); return 0 / 0; }", t)
+}
@@ -12,7 +12,7 @@ import (
 
 func isNonObj(n *ast.Expression) bool {
 	switch n := n.Expr.(type) {
-	case *ast.StringLiteral, *ast.NumberLiteral, *ast.NullLiteral, *ast.BooleanLiteral:
+	case *ast.StringLiteral, *ast.NumberLiteral, *ast.BigIntLiteral, *ast.NullLiteral, *ast.BooleanLiteral:
 		return true
 	case *ast.Identifier:
 		if n.Name == "undefined" || n.Name == "Infinity" || n.Name == "NaN" {
@@ -45,6 +45,32 @@ func directnessMaters(n *ast.Expression) bool {
 	return false
 }
 
+type directEvalFinder struct {
+	ast.NoopVisitor
+
+	found bool
+}
+
+// containsDirectEval reports whether the function fn calls eval directly,
+// not counting calls in nested functions.
+func containsDirectEval(fn ast.VisitableNode) bool {
+	v := &directEvalFinder{}
+	v.V = v
+	fn.VisitChildrenWith(v)
+	return v.found
+}
+
+func (v *directEvalFinder) VisitCallExpression(n *ast.CallExpression) {
+	if id, ok := n.Callee.Expr.(*ast.Identifier); ok && id.Name == "eval" {
+		v.found = true
+		return
+	}
+	n.VisitChildrenWith(v)
+}
+
+func (v *directEvalFinder) VisitFunctionLiteral(*ast.FunctionLiteral)           {}
+func (v *directEvalFinder) VisitArrowFunctionLiteral(*ast.ArrowFunctionLiteral) {}
+
 func makeBoolExpr(value bool, orig ast.Expressions) ast.Expression {
 	return ext.PreserveEffects(ast.Expression{Expr: &ast.BooleanLiteral{Value: value}}, orig)
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"syscall/js"
 
+	"github.com/t14raptor/go-fast/ast"
 	"github.com/t14raptor/go-fast/parser"
 	"github.com/t14raptor/go-fast/resolver"
 	"github.com/t14raptor/go-fast/serializer"
@@ -11,7 +12,11 @@ import (
 
 // errorJSON returns a JSON string for error responses
 func errorJSON(msg string) string {
-	// Simple JSON encoding - escape quotes and backslashes in the message
+	return `{"error":"` + escapeJSON(msg) + `"}`
+}
+
+// escapeJSON escapes quotes, backslashes and line breaks in msg
+func escapeJSON(msg string) string {
 	escaped := ""
 	for _, c := range msg {
 		switch c {
@@ -29,7 +34,39 @@ func errorJSON(msg string) string {
 			escaped += string(c)
 		}
 	}
-	return `{"error":"` + escaped + `"}`
+	return escaped
+}
+
+// withErrors adds the syntax errors of a tolerant parse to the serialized program.
+// Columns, offsets and lengths are counted in UTF-16 code units if utf16 is set.
+func withErrors(programJSON string, errors parser.ErrorList, source *ast.SourceFile, utf16 bool) string {
+	out := programJSON[:len(programJSON)-1] + `,"errors":[`
+	for i, e := range errors {
+		if i > 0 {
+			out += ","
+		}
+		out += fmt.Sprintf(`{"message":"%s","code":"%s",%s`, escapeJSON(e.Message), e.Code, positionJSON(e.Line, e.Column, e.Offset, e.Length, source, utf16))
+		if e.Hint != "" {
+			out += `,"hint":"` + escapeJSON(e.Hint) + `"`
+		}
+		if r := e.Related; r != nil {
+			out += fmt.Sprintf(`,"related":{"message":"%s",%s}`, escapeJSON(r.Message), positionJSON(r.Line, r.Column, r.Offset, r.Length, source, utf16))
+		}
+		out += "}"
+	}
+	return out + "]}"
+}
+
+// positionJSON returns the JSON fields of the position of an error or of its
+// related span.
+func positionJSON(line, column, offset, length int, source *ast.SourceFile, utf16 bool) string {
+	if utf16 {
+		idx := ast.Idx(offset + 1)
+		column = source.Position(idx, ast.ColumnUTF16).Column + 1
+		offset = source.OffsetIn(idx, ast.ColumnUTF16)
+		length = source.OffsetIn(idx+ast.Idx(length), ast.ColumnUTF16) - offset
+	}
+	return fmt.Sprintf(`"line":%d,"column":%d,"offset":%d,"length":%d`, line, column, offset, length)
 }
 
 func parseJS(this js.Value, args []js.Value) (result any) {
@@ -48,15 +85,59 @@ func parseJS(this js.Value, args []js.Value) (result any) {
 
 	// Check for options object as second argument
 	shouldResolve := false
+	var opts parser.Options
+	var serializeOpts serializer.Options
 	if len(args) >= 2 && args[1].Type() == js.TypeObject {
 		resolveVal := args[1].Get("resolve")
 		if resolveVal.Type() == js.TypeBoolean {
 			shouldResolve = resolveVal.Bool()
 		}
+		sourceTypeVal := args[1].Get("sourceType")
+		if sourceTypeVal.Type() == js.TypeString && sourceTypeVal.String() == "module" {
+			opts.SourceType = ast.SourceTypeModule
+		}
+		commentsVal := args[1].Get("comments")
+		if commentsVal.Type() == js.TypeBoolean {
+			opts.Comments = commentsVal.Bool()
+		}
+		tolerantVal := args[1].Get("tolerant")
+		if tolerantVal.Type() == js.TypeBoolean {
+			opts.Tolerant = tolerantVal.Bool()
+		}
+		jsxVal := args[1].Get("jsx")
+		if jsxVal.Type() == js.TypeBoolean {
+			opts.JSX = jsxVal.Bool()
+		}
+		typescriptVal := args[1].Get("typescript")
+		if typescriptVal.Type() == js.TypeBoolean {
+			opts.TypeScript = typescriptVal.Bool()
+		}
+		webCompatVal := args[1].Get("webCompat")
+		if webCompatVal.Type() == js.TypeBoolean {
+			opts.NoWebCompat = !webCompatVal.Bool()
+		}
+		maxDepthVal := args[1].Get("maxDepth")
+		if maxDepthVal.Type() == js.TypeNumber {
+			opts.Limits.MaxDepth = maxDepthVal.Int()
+		}
+		maxNodesVal := args[1].Get("maxNodes")
+		if maxNodesVal.Type() == js.TypeNumber {
+			opts.Limits.MaxNodes = maxNodesVal.Int()
+		}
+		locationsVal := args[1].Get("locations")
+		if locationsVal.Type() == js.TypeBoolean {
+			serializeOpts.Locations = locationsVal.Bool()
+		}
+		utf16Val := args[1].Get("utf16")
+		if utf16Val.Type() == js.TypeBoolean {
+			serializeOpts.UTF16 = utf16Val.Bool()
+		}
 	}
 
-	program, err := parser.ParseFile(source)
-	if err != nil {
+	program, err := parser.ParseFileWithOptions(source, opts)
+	list, isList := err.(*parser.ErrorList)
+	// Exceeded limits are reported even in tolerant mode.
+	if err != nil && (!opts.Tolerant || !isList) {
 		return errorJSON(err.Error())
 	}
 
@@ -64,7 +145,15 @@ func parseJS(this js.Value, args []js.Value) (result any) {
 		resolver.Resolve(program)
 	}
 
-	return serializer.Serialize(program)
+	serializeOpts.Limits = opts.Limits
+	json, err := serializer.SerializeWithOptions(program, serializeOpts)
+	if err != nil {
+		return errorJSON(err.Error())
+	}
+	if isList {
+		return withErrors(json, *list, program.Source, serializeOpts.UTF16)
+	}
+	return json
 }
 
 func main() {